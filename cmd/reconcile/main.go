@@ -0,0 +1,186 @@
+// Command reconcile reconciles bank statements files against a system transactions file without the HTTP server
+//
+//	reconcile --bank bank_statements.csv --system system_transactions.csv --from 2024-01-01 --to 2024-01-31 --format table
+//
+// It exits with exitReconciled when every transaction is matched without difference, exitDiscrepancies when some
+// are unmatched or matched with a difference and exitFailed when the reconciliation can't be run
+package main
+
+import (
+	"amartha-test/entities/transactions"
+	libError "amartha-test/errors"
+	"amartha-test/parsers"
+	usecase "amartha-test/usecases"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// exit codes of reconcile
+const (
+	exitReconciled    = 0
+	exitDiscrepancies = 1
+	exitFailed        = 2
+)
+
+// fileList is a flag that can be repeated, every value is a file
+type fileList []string
+
+func (l *fileList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *fileList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	// the reconciliation stops on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run reconciles the files of the arguments, writes the result to stdout and returns the exit code
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var bankFiles fileList
+	flags.Var(&bankFiles, "bank", "bank statements csv, xlsx, camt xml or MT940 file, repeat it for every file")
+	systemFile := flags.String("system", "", "system transactions csv or xlsx file")
+	from := flags.String("from", "", "first day reconciled, "+transactions.DateRangeFormat)
+	to := flags.String("to", "", "last day reconciled, "+transactions.DateRangeFormat)
+	format := flags.String("format", formatTable, "format of the result, json, csv or table")
+	dateToleranceDays := flags.Int("date-tolerance-days", 0, "maximum difference in days between a matched bank statement and system transaction")
+	bankFormat := flags.String("bank-format", "", "layout of the bank statements files, the csv layout of the records when empty")
+	err := flags.Parse(args)
+	if err != nil {
+		return exitFailed
+	}
+
+	param, err := reconciliationRequest(bankFiles, *systemFile, *from, *to)
+	if err != nil {
+		fmt.Fprintf(stderr, "reconcile: %s\n", err)
+		return exitFailed
+	}
+	defer closeFiles(param)
+	param.DateToleranceDays = *dateToleranceDays
+	param.BankStatementsOptions.Format = strings.ToLower(*bankFormat)
+
+	write, ok := writers[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "reconcile: format must be %s, %s or %s\n", formatJSON, formatCSV, formatTable)
+		return exitFailed
+	}
+
+	transactionsUsecase := usecase.NewTransactionUsecase(usecase.TransactionUsecase{
+		BankStatementsParsers: parsers.BankStatementsParsers(),
+	})
+	result, err := transactionsUsecase.DoReconciliation(ctx, param)
+	if err != nil {
+		writeError(stderr, err)
+		return exitFailed
+	}
+
+	err = write(stdout, result)
+	if err != nil {
+		fmt.Fprintf(stderr, "reconcile: %s\n", err)
+		return exitFailed
+	}
+
+	if result.UnmatchedTransaction > 0 || len(result.MatchedWithDifference) > 0 {
+		return exitDiscrepancies
+	}
+	return exitReconciled
+}
+
+// reconciliationRequest opens the files and reads the date range of the reconciliation
+func reconciliationRequest(bankFiles []string, systemFile, from, to string) (param transactions.DoReconciliationRequest, err error) {
+	if len(bankFiles) == 0 || systemFile == "" {
+		return param, errors.New("--bank and --system are required")
+	}
+
+	param.StartDate, err = parseDate("from", from)
+	if err != nil {
+		return param, err
+	}
+	param.EndDate, err = parseDate("to", to)
+	if err != nil {
+		return param, err
+	}
+
+	defer func() {
+		if err != nil {
+			closeFiles(param)
+		}
+	}()
+	param.SystemTransactions, err = openFile(systemFile)
+	if err != nil {
+		return param, err
+	}
+	for _, bankFile := range bankFiles {
+		file, err := openFile(bankFile)
+		if err != nil {
+			return param, err
+		}
+		param.BankStatements = append(param.BankStatements, transactions.BankStatementsUpload{
+			File: file,
+			Name: filepath.Base(bankFile),
+		})
+	}
+	return param, nil
+}
+
+// parseDate reads an optional date in transactions.DateRangeFormat, an empty value is read as zero time
+func parseDate(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.ParseInLocation(transactions.DateRangeFormat, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--%s must be a date with format %s", name, transactions.DateRangeFormat)
+	}
+	return date, nil
+}
+
+func openFile(name string) (multipart.File, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func closeFiles(param transactions.DoReconciliationRequest) {
+	if param.SystemTransactions != nil {
+		param.SystemTransactions.Close()
+	}
+	for _, upload := range param.BankStatements {
+		upload.File.Close()
+	}
+}
+
+// writeError writes the error of the reconciliation with every invalid value of the files
+func writeError(stderr io.Writer, err error) {
+	fmt.Fprintf(stderr, "reconcile: %s\n", err)
+
+	var validationError *libError.ValidationError
+	if !errors.As(err, &validationError) {
+		return
+	}
+	rowErrors, _ := validationError.Errors.([]transactions.RowError)
+	for _, rowError := range rowErrors {
+		fmt.Fprintf(stderr, "  %s line %d, %s %q: %s\n", rowError.File, rowError.Line, rowError.Column, rowError.Value, rowError.Reason)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_run(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		err := os.WriteFile(path, []byte(content), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	bankFile := writeFile("bank.csv", "unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024\nBRI_23463,\"Rp7,000,000\",02/01/2024")
+	systemFile := writeFile("system.csv", "trxID,amount,type,transactionTime\n1,\"Rp1,500,000\",2,01/01/2024 8:45:00\n2,\"Rp7,000,000\",2,02/01/2024 8:46:00")
+	invalidFile := writeFile("invalid.csv", "trxID,amount,type,transactionTime\n1,abc,2,01/01/2024 8:45:00")
+
+	tests := []struct {
+		name       string
+		args       []string
+		want       int
+		wantStdout string
+	}{
+		{
+			name:       "Succesful without discrepancies",
+			args:       []string{"--bank", bankFile, "--system", systemFile, "--format", "csv"},
+			want:       exitReconciled,
+			wantStdout: "status,trxID,unique_identifier,bank_source,amount,bank_amount,difference,date\nmatched,1,BCA_12345,BCA,1500000.00,1500000.00,0.00,01/01/2024\nmatched,2,BRI_23463,BRI,7000000.00,7000000.00,0.00,02/01/2024\n",
+		},
+		{
+			name:       "Succesful in the date range",
+			args:       []string{"--bank", bankFile, "--system", systemFile, "--from", "2024-01-02", "--to", "2024-01-02", "--format", "csv"},
+			want:       exitReconciled,
+			wantStdout: "status,trxID,unique_identifier,bank_source,amount,bank_amount,difference,date\nmatched,2,BRI_23463,BRI,7000000.00,7000000.00,0.00,02/01/2024\n",
+		},
+		{
+			name: "Succesful with discrepancies",
+			args: []string{"--bank", bankFile, "--bank", bankFile, "--system", systemFile, "--format", "table"}, // the bank statements of the second file are missing
+			want: exitDiscrepancies,
+		},
+		{
+			name: "Failed without system transactions",
+			args: []string{"--bank", bankFile},
+			want: exitFailed,
+		},
+		{
+			name: "Failed with invalid date",
+			args: []string{"--bank", bankFile, "--system", systemFile, "--from", "01/01/2024"},
+			want: exitFailed,
+		},
+		{
+			name: "Failed with unknown format",
+			args: []string{"--bank", bankFile, "--system", systemFile, "--format", "xml"},
+			want: exitFailed,
+		},
+		{
+			name: "Failed with invalid data",
+			args: []string{"--bank", bankFile, "--system", invalidFile},
+			want: exitFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := run(context.Background(), tt.args, &stdout, &stderr); got != tt.want {
+				t.Errorf("run() = %v, want %v, stderr %s", got, tt.want, stderr.String())
+			}
+			if tt.wantStdout != "" && stdout.String() != tt.wantStdout {
+				t.Errorf("run() stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"amartha-test/entities/transactions"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// formats of the result
+const (
+	formatJSON  = "json"
+	formatCSV   = "csv"
+	formatTable = "table"
+)
+
+// writers write the result in the format of their name
+var writers = map[string]func(w io.Writer, result transactions.DoReconciliationResponse) error{
+	formatJSON:  writeJSON,
+	formatCSV:   writeCSV,
+	formatTable: writeTable,
+}
+
+// statuses of the records of the result
+const (
+	statusMatched                  = "matched"
+	statusMatchedWithDifference    = "matched_with_difference"
+	statusMissingBankStatement     = "missing_bank_statement"
+	statusMissingSystemTransaction = "missing_system_transaction"
+)
+
+var recordsHeader = []string{"status", "trxID", "unique_identifier", "bank_source", "amount", "bank_amount", "difference", "date"}
+
+// records returns a row of recordsHeader for every record of the result, the matched records first
+func records(result transactions.DoReconciliationResponse) [][]string {
+	var rows [][]string
+	addMatched := func(status string, matched []transactions.MatchedTransaction) {
+		for _, transaction := range matched {
+			rows = append(rows, []string{
+				status,
+				transaction.TransactionID,
+				transaction.UniqueIdentifier,
+				transaction.BankSource,
+				transaction.Amount.String(),
+				transaction.BankAmount.String(),
+				transaction.Difference.String(),
+				transaction.Date,
+			})
+		}
+	}
+	addMatched(statusMatched, result.MatchedTransactions)
+	addMatched(statusMatchedWithDifference, result.MatchedWithDifference)
+
+	for _, transaction := range result.MissingSystemTransactions {
+		rows = append(rows, []string{
+			statusMissingSystemTransaction,
+			transaction.TransactionID,
+			"",
+			"",
+			transaction.AbsoluteAmount().String(),
+			"",
+			"",
+			transaction.TransactionTime,
+		})
+	}
+
+	// banks are sorted so the rows are always written in the same order
+	banks := make([]string, 0, len(result.MissingBankStatements))
+	for bank := range result.MissingBankStatements {
+		banks = append(banks, bank)
+	}
+	sort.Strings(banks)
+	for _, bank := range banks {
+		for _, statement := range result.MissingBankStatements[bank] {
+			rows = append(rows, []string{
+				statusMissingBankStatement,
+				"",
+				statement.ID,
+				statement.BankSource,
+				"",
+				statement.AbsoluteAmount().String(),
+				"",
+				statement.Date,
+			})
+		}
+	}
+	return rows
+}
+
+func writeJSON(w io.Writer, result transactions.DoReconciliationResponse) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// writeCSV writes every record of the result, the totals are left out
+func writeCSV(w io.Writer, result transactions.DoReconciliationResponse) error {
+	writer := csv.NewWriter(w)
+	writer.Write(recordsHeader)
+	writer.WriteAll(records(result))
+	return writer.Error()
+}
+
+// writeTable writes the totals of the result followed by every record that is not matched exactly
+func writeTable(w io.Writer, result transactions.DoReconciliationResponse) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "transaction proceed\t%d\n", result.TransactionsProceed)
+	fmt.Fprintf(writer, "matched transaction\t%d\n", result.MatchedTransaction)
+	fmt.Fprintf(writer, "unmatched transaction\t%d\n", result.UnmatchedTransaction)
+	fmt.Fprintf(writer, "total discrepancies\t%s %s\n", result.TotalDiscrepancies, result.ReportingCurrency)
+	for _, rowError := range result.RejectedRows {
+		fmt.Fprintf(writer, "rejected row\t%s line %d, %s %q: %s\n", rowError.File, rowError.Line, rowError.Column, rowError.Value, rowError.Reason)
+	}
+
+	var discrepancies [][]string
+	for _, row := range records(result) {
+		if row[0] != statusMatched {
+			discrepancies = append(discrepancies, row)
+		}
+	}
+	if len(discrepancies) > 0 {
+		fmt.Fprintln(writer)
+		for _, row := range append([][]string{recordsHeader}, discrepancies...) {
+			for index, value := range row {
+				if value == "" {
+					value = "-"
+				}
+				if index > 0 {
+					fmt.Fprint(writer, "\t")
+				}
+				fmt.Fprint(writer, value)
+			}
+			fmt.Fprintln(writer)
+		}
+	}
+	return writer.Flush()
+}
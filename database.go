@@ -0,0 +1,39 @@
+package main
+
+import (
+	"amartha-test/repositories"
+	"context"
+	"os"
+)
+
+const (
+	// databaseDriverEnv is the environment variable with the database the runs are stored in, sqlite or postgres
+	databaseDriverEnv = "DATABASE_DRIVER"
+	// databaseURLEnv is the environment variable with the file of the sqlite database or the connection string of
+	// the postgres database
+	databaseURLEnv = "DATABASE_URL"
+
+	defaultDatabaseURL = "reconciliation.db"
+)
+
+// loadReconciliationRepository opens the database of the runs and creates its tables, runs are stored with sqlite
+// in defaultDatabaseURL when the variables are not set
+func loadReconciliationRepository(ctx context.Context) (repositories.ReconciliationRepository, error) {
+	driver, url := os.Getenv(databaseDriverEnv), os.Getenv(databaseURLEnv)
+	if driver == "" {
+		driver = repositories.DriverSQLite
+	}
+	if url == "" && driver == repositories.DriverSQLite {
+		url = defaultDatabaseURL
+	}
+
+	db, err := repositories.Open(driver, url)
+	if err != nil {
+		return repositories.ReconciliationRepository{}, err
+	}
+	repository := repositories.NewReconciliationRepository(repositories.ReconciliationRepository{
+		DB:     db,
+		Driver: driver,
+	})
+	return repository, repository.Migrate(ctx)
+}
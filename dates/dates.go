@@ -0,0 +1,196 @@
+package dates
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is the layout of the dates of a column, either a Go reference time layout like 02/01/2006 15:04:05
+// or one of the named formats below
+type Format string
+
+const (
+	// FormatISO8601 reads ISO 8601 dates like 2024-01-13, 2024-01-13T08:45:00 or 2024-01-13T08:45:00+07:00
+	FormatISO8601 Format = "iso8601"
+	// FormatUnix reads the number of seconds since 1970-01-01 UTC
+	FormatUnix Format = "unix"
+	// FormatUnixMilli reads the number of milliseconds since 1970-01-01 UTC
+	FormatUnixMilli Format = "unix_ms"
+	// FormatAuto detects the layout from the values of the column, columns that can be read with different
+	// days and months are rejected
+	FormatAuto Format = "auto"
+)
+
+// iso8601Layouts are the ISO 8601 layouts read by FormatISO8601, layouts without offset are read in the location
+var iso8601Layouts = []string{
+	"2006-01-02",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+}
+
+// detectedLayouts are tried by FormatAuto after ISO 8601 and Unix time, a day or month without leading zero is
+// also read by these layouts
+var detectedLayouts = func() (layouts []Format) {
+	for _, date := range []string{"2/1/2006", "1/2/2006", "2-1-2006", "1-2-2006", "2006/1/2"} {
+		for _, clock := range []string{"", " 15:04:05", " 15:04"} {
+			layouts = append(layouts, Format(date+clock))
+		}
+	}
+	return layouts
+}()
+
+// IsValid checks whether the format is a named format or a layout that has a year, month and day
+func (f Format) IsValid() bool {
+	switch f {
+	case FormatISO8601, FormatUnix, FormatUnixMilli, FormatAuto:
+		return true
+	}
+
+	reference := time.Date(2024, time.January, 13, 8, 45, 0, 0, time.UTC)
+	parsed, err := time.Parse(string(f), reference.Format(string(f)))
+	if err != nil {
+		return false
+	}
+	return parsed.Year() == reference.Year() && parsed.Month() == reference.Month() && parsed.Day() == reference.Day()
+}
+
+// Parse reads a date written with the format, dates without offset are read in the location. FormatAuto must be
+// detected with Detect first
+func Parse(value string, format Format, location *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	switch format {
+	case FormatAuto:
+		return time.Time{}, errors.New("date format must be detected before the dates are read")
+
+	case FormatISO8601:
+		for _, layout := range iso8601Layouts {
+			date, err := time.ParseInLocation(layout, value, location)
+			if err == nil {
+				return date, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("date %q is not an ISO 8601 date", value)
+
+	case FormatUnix, FormatUnixMilli:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("date %q is not a Unix time", value)
+		}
+		if format == FormatUnixMilli {
+			return time.UnixMilli(number).In(location), nil
+		}
+		return time.Unix(number, 0).In(location), nil
+	}
+
+	return time.ParseInLocation(string(format), value, location)
+}
+
+// Detect returns the format that reads the most values of a column, empty values are left out. It fails when no
+// format reads any value, or when the values can be read by formats that give different dates, like 03/01/2024
+// that is the 3rd of January written day first and the 1st of March written month first
+func Detect(values []string) (Format, error) {
+	detector := NewDetector()
+	for _, value := range values {
+		detector.Add(value)
+	}
+	return detector.Format()
+}
+
+// Detector detects the format of a column one value at a time, so the column doesn't have to be kept in memory
+type Detector struct {
+	candidates []Format
+	counts     []int
+	conflicts  map[[2]int]string // a value read differently by two candidates, by the index of the candidates
+}
+
+func NewDetector() *Detector {
+	candidates := append([]Format{FormatISO8601, FormatUnix, FormatUnixMilli}, detectedLayouts...)
+	return &Detector{
+		candidates: candidates,
+		counts:     make([]int, len(candidates)),
+		conflicts:  map[[2]int]string{},
+	}
+}
+
+// Add reads a value of the column with every candidate format
+func (d *Detector) Add(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+
+	parsed := make([]*time.Time, len(d.candidates))
+	for index, candidate := range d.candidates {
+		if !isDetected(value, candidate) {
+			continue
+		}
+		date, _ := Parse(value, candidate, time.UTC)
+		parsed[index] = &date
+		d.counts[index]++
+
+		for other := 0; other < index; other++ {
+			if parsed[other] == nil || parsed[other].Equal(date) {
+				continue
+			}
+			if _, ok := d.conflicts[[2]int{other, index}]; !ok {
+				d.conflicts[[2]int{other, index}] = value
+			}
+		}
+	}
+}
+
+// Format returns the format that reads the most values added, formats that read as many values must agree on
+// every date
+func (d *Detector) Format() (Format, error) {
+	var best []int
+	bestCount := 0
+	for index, count := range d.counts {
+		switch {
+		case count == 0 || count < bestCount:
+		case count > bestCount:
+			best, bestCount = []int{index}, count
+		default:
+			best = append(best, index)
+		}
+	}
+	if len(best) == 0 {
+		return "", errors.New("no known date format reads the values")
+	}
+
+	for _, other := range best[1:] {
+		if value, ok := d.conflicts[[2]int{best[0], other}]; ok {
+			return "", fmt.Errorf("dates like %s are ambiguous, they can be read as %s or %s", value, d.candidates[best[0]], d.candidates[other])
+		}
+	}
+	return d.candidates[best[0]], nil
+}
+
+// isDetected reports whether the format reads a value of a column, Unix times in seconds have 9 to 11 digits and
+// Unix times in milliseconds have 12 or 13 digits so other numbers and numbers read both ways are not detected
+func isDetected(value string, format Format) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return false
+	}
+
+	switch format {
+	case FormatUnix:
+		if len(value) < 9 || len(value) > 11 {
+			return false
+		}
+	case FormatUnixMilli:
+		if len(value) < 12 || len(value) > 13 {
+			return false
+		}
+	}
+
+	_, err := Parse(value, format, time.UTC)
+	return err == nil
+}
@@ -0,0 +1,168 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormat_IsValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		want   bool
+	}{
+		{name: "Succesful layout", format: "02/01/2006 15:04:05", want: true},
+		{name: "Succesful named format", format: FormatISO8601, want: true},
+		{name: "Succesful auto", format: FormatAuto, want: true},
+		{name: "Layout without day", format: "01/2006", want: false},
+		{name: "Unknown format", format: "dd/mm/yyyy", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format.IsValid(); got != tt.want {
+				t.Errorf("Format.IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+
+	type args struct {
+		value  string
+		format Format
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:    "Succesful layout with single digit hour",
+			args:    args{value: "01/01/2024 8:45:00", format: "02/01/2006 15:04:05"},
+			want:    time.Date(2024, time.January, 1, 8, 45, 0, 0, jakarta),
+			wantErr: false,
+		},
+		{
+			name:    "Succesful ISO 8601 date",
+			args:    args{value: "2024-01-13", format: FormatISO8601},
+			want:    time.Date(2024, time.January, 13, 0, 0, 0, 0, jakarta),
+			wantErr: false,
+		},
+		{
+			name:    "Succesful ISO 8601 with offset",
+			args:    args{value: "2024-01-13T01:45:00Z", format: FormatISO8601},
+			want:    time.Date(2024, time.January, 13, 8, 45, 0, 0, jakarta),
+			wantErr: false,
+		},
+		{
+			name:    "Succesful Unix time",
+			args:    args{value: "1705110300", format: FormatUnix},
+			want:    time.Date(2024, time.January, 13, 8, 45, 0, 0, jakarta),
+			wantErr: false,
+		},
+		{
+			name:    "Succesful Unix time in milliseconds",
+			args:    args{value: "1705110300000", format: FormatUnixMilli},
+			want:    time.Date(2024, time.January, 13, 8, 45, 0, 0, jakarta),
+			wantErr: false,
+		},
+		{
+			name:    "ISO 8601 date is invalid",
+			args:    args{value: "13/01/2024", format: FormatISO8601},
+			wantErr: true,
+		},
+		{
+			name:    "Unix time is invalid",
+			args:    args{value: "2024-01-13", format: FormatUnix},
+			wantErr: true,
+		},
+		{
+			name:    "Auto is not detected",
+			args:    args{value: "2024-01-13", format: FormatAuto},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.args.value, tt.args.format, jakarta)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    Format
+		wantErr bool
+	}{
+		{
+			name:    "Succesful day first",
+			values:  []string{"01/01/2024 8:45:00", "13/01/2024 10:00:00", ""},
+			want:    "2/1/2006 15:04:05",
+			wantErr: false,
+		},
+		{
+			name:    "Succesful month first",
+			values:  []string{"1/2/2024", "1/13/2024"},
+			want:    "1/2/2006",
+			wantErr: false,
+		},
+		{
+			name:    "Succesful same day and month",
+			values:  []string{"01/01/2024", "02/02/2024"},
+			want:    "2/1/2006",
+			wantErr: false,
+		},
+		{
+			name:    "Succesful ISO 8601",
+			values:  []string{"2024-01-13", "2024-01-13T08:45:00+07:00"},
+			want:    FormatISO8601,
+			wantErr: false,
+		},
+		{
+			name:    "Succesful Unix time",
+			values:  []string{"1705110300", "1705196700"},
+			want:    FormatUnix,
+			wantErr: false,
+		},
+		{
+			name:    "Succesful with invalid value",
+			values:  []string{"13/01/2024", "14/01/2024", "yesterday"},
+			want:    "2/1/2006",
+			wantErr: false,
+		},
+		{
+			name:    "Ambiguous day and month",
+			values:  []string{"03/01/2024", "04/02/2024"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown format",
+			values:  []string{"yesterday", ""},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Detect(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Detect() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
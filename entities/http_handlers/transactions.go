@@ -4,4 +4,7 @@ import "net/http"
 
 type TransactionHandler interface {
 	HandleReconciliation(w http.ResponseWriter, r *http.Request) 
+	HandleGetReconciliationRuns(w http.ResponseWriter, r *http.Request)
+	HandleGetReconciliationRun(w http.ResponseWriter, r *http.Request)
+	HandleGetJob(w http.ResponseWriter, r *http.Request)
 }
\ No newline at end of file
@@ -0,0 +1,27 @@
+package parsers
+
+import (
+	"amartha-test/entities/transactions"
+	"amartha-test/money"
+	"io"
+)
+
+// formats of bank statements files that are not read by a BankStatementsParser
+const (
+	FormatCSV  = "csv"  // unique_identifier,amount,date layout of transactions.BankStatements
+	FormatAuto = "auto" // detected from the content of the file
+)
+
+// BankStatementsParser reads the statement export of one bank into bank statements
+type BankStatementsParser interface {
+	// Detect reports whether the content of the file is written in the format of the parser
+	Detect(content []byte) bool
+
+	// Parse reads every bank statement of the file with its line number. Amounts are written as signed values,
+	// negative for DEBIT, and dates with layout 02/01/2006, values that can't be read are kept as written so
+	// they are reported by the validation
+	Parse(file io.Reader) ([]*transactions.BankStatements, error)
+
+	// NumberFormat is the separators used in the amounts returned by Parse
+	NumberFormat() money.NumberFormat
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"amartha-test/entities/transactions"
+	"context"
+	"errors"
+)
+
+// ErrRunNotFound is returned when no run is stored with the id
+var ErrRunNotFound = errors.New("reconciliation run is not found")
+
+//go:generate mockgen -destination mock/mock_reconciliation.go -source=reconciliation.go ReconciliationRepository
+
+// ReconciliationRepository stores the reconciliation runs
+type ReconciliationRepository interface {
+	// SaveRun stores the run with its matched pairs and unmatched items
+	SaveRun(ctx context.Context, run transactions.ReconciliationRun) error
+
+	// GetRuns returns the page of runs of the filter, the most recent first, with the number of runs of every page
+	GetRuns(ctx context.Context, filter transactions.GetReconciliationRunsRequest) (runs []transactions.ReconciliationRunSummary, total int, err error)
+
+	// GetRun returns the run with its matched pairs and unmatched items, ErrRunNotFound when there is none
+	GetRun(ctx context.Context, id string) (transactions.ReconciliationRun, error)
+}
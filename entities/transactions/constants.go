@@ -3,4 +3,30 @@ package transactions
 const (
 	DEBIT = iota + 1
 	CREDIT
-)
\ No newline at end of file
+)
+
+// BankSourceGroup is the name of the group that captures the bank source in a bank source pattern
+const BankSourceGroup = "bank"
+
+// DateRangeFormat is the layout of the start and end date of a reconciliation
+const DateRangeFormat = "2006-01-02"
+
+// ValidationMode decides what happens to the invalid rows of the uploaded files
+type ValidationMode string
+
+const (
+	ValidationStrict  ValidationMode = "strict"  // the reconciliation fails and every invalid row is reported
+	ValidationLenient ValidationMode = "lenient" // invalid rows are skipped and listed in the result
+)
+
+// names of the uploaded files used in the validation report
+const (
+	BankStatementsFile     = "bank_statements"
+	SystemTransactionsFile = "system_transactions"
+)
+
+// default headers of the columns of the uploaded csv files, a column layout maps other headers to them
+var (
+	BankStatementsColumns     = []string{"unique_identifier", "amount", "date", "bank"}
+	SystemTransactionsColumns = []string{"trxID", "amount", "type", "transactionTime"}
+)
@@ -0,0 +1,137 @@
+package transactions
+
+import (
+	"amartha-test/money"
+	"sync/atomic"
+	"time"
+)
+
+// JobStatus is the state of an asynchronous reconciliation
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// ReconciliationJob is a reconciliation run in the background, its result is stored with the run of RunID
+type ReconciliationJob struct {
+	ID         string                 `json:"id"`
+	Status     JobStatus              `json:"status"`
+	Error      string                 `json:"error,omitempty"`
+	Errors     interface{}            `json:"errors,omitempty"` // invalid values when the uploaded data is invalid
+	RunID      string                 `json:"run_id,omitempty"`
+	Progress   ReconciliationProgress `json:"progress"`
+	Summary    *ReconciliationSummary `json:"summary,omitempty"` // totals of the result when the job succeeded
+	Callback   *JobCallback           `json:"callback,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	StartedAt  *time.Time             `json:"started_at,omitempty"`
+	FinishedAt *time.Time             `json:"finished_at,omitempty"`
+}
+
+// CallbackStatus is the state of the delivery of the callback of a job
+type CallbackStatus string
+
+const (
+	CallbackPending   CallbackStatus = "pending"
+	CallbackDelivered CallbackStatus = "delivered"
+	CallbackFailed    CallbackStatus = "failed"
+)
+
+// JobCallback is the url notified when a job finishes and how its delivery went
+type JobCallback struct {
+	URL      string         `json:"url"`
+	Status   CallbackStatus `json:"status"`
+	Attempts int            `json:"attempts"`
+	Error    string         `json:"error,omitempty"` // error of the last attempt
+}
+
+// JobCallbackPayload is the body sent to the callback url of a job when it succeeds or fails
+type JobCallbackPayload struct {
+	JobID  string    `json:"job_id"`
+	Status JobStatus `json:"status"`
+	Error  string    `json:"error,omitempty"`
+	RunID  string    `json:"run_id,omitempty"`
+	ReconciliationSummary
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// NewJobCallbackPayload returns the payload of the finished job, the totals are zero when the job failed
+func NewJobCallbackPayload(job ReconciliationJob) JobCallbackPayload {
+	payload := JobCallbackPayload{
+		JobID:  job.ID,
+		Status: job.Status,
+		Error:  job.Error,
+		RunID:  job.RunID,
+	}
+	if job.Summary != nil {
+		payload.ReconciliationSummary = *job.Summary
+	}
+	if job.FinishedAt != nil {
+		payload.FinishedAt = *job.FinishedAt
+	}
+	return payload
+}
+
+// ReconciliationProgress counts the records a reconciliation has gone through
+type ReconciliationProgress struct {
+	BankStatementsRead     int64 `json:"bank_statements_read"`
+	SystemTransactionsRead int64 `json:"system_transactions_read"`
+	TransactionsProceed    int64 `json:"transaction_proceed"` // system transactions matched or reported as missing
+}
+
+// ReconciliationSummary is the totals of a reconciliation
+type ReconciliationSummary struct {
+	MatchedTransaction   int         `json:"matched_transaction"`
+	UnmatchedTransaction int         `json:"unmatched_transaction"`
+	TotalDiscrepancies   money.Money `json:"total_discripencies"`
+}
+
+// NewReconciliationSummary returns the totals of the result
+func NewReconciliationSummary(result DoReconciliationResponse) ReconciliationSummary {
+	return ReconciliationSummary{
+		MatchedTransaction:   result.MatchedTransaction,
+		UnmatchedTransaction: result.UnmatchedTransaction,
+		TotalDiscrepancies:   result.TotalDiscrepancies,
+	}
+}
+
+// Progress is updated by a reconciliation while it runs and can be read at the same time, a nil Progress counts
+// nothing
+type Progress struct {
+	bankStatementsRead     atomic.Int64
+	systemTransactionsRead atomic.Int64
+	transactionsProceed    atomic.Int64
+}
+
+func (p *Progress) AddBankStatementsRead(count int) {
+	if p != nil {
+		p.bankStatementsRead.Add(int64(count))
+	}
+}
+
+func (p *Progress) AddSystemTransactionsRead(count int) {
+	if p != nil {
+		p.systemTransactionsRead.Add(int64(count))
+	}
+}
+
+func (p *Progress) AddTransactionsProceed(count int) {
+	if p != nil {
+		p.transactionsProceed.Add(int64(count))
+	}
+}
+
+// Counts returns the counts of the progress so far
+func (p *Progress) Counts() ReconciliationProgress {
+	if p == nil {
+		return ReconciliationProgress{}
+	}
+	return ReconciliationProgress{
+		BankStatementsRead:     p.bankStatementsRead.Load(),
+		SystemTransactionsRead: p.systemTransactionsRead.Load(),
+		TransactionsProceed:    p.transactionsProceed.Load(),
+	}
+}
@@ -1,9 +1,163 @@
 package transactions
 
-import "mime/multipart"
+import (
+	"amartha-test/dates"
+	"amartha-test/money"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // the time zones are read from the binary so they don't depend on the server
+	"unicode/utf8"
+)
 
 type DoReconciliationRequest struct {
 	SystemTransactions multipart.File
-	BankStatements     multipart.File
+	BankStatements     []BankStatementsUpload // every bank statements file is reconciled against the same system transactions
+	DateToleranceDays  int                    // maximum difference in days between a bank statement and a system transaction to be matched
+
+	// maximum difference in amount between a bank statement and a system transaction to be matched,
+	// either absolute in the reporting currency or as percentage of the system transaction amount,
+	// the bigger one is used
+	AmountTolerance        money.Money
+	AmountTolerancePercent float64
+
+	// only records dated inside the range are reconciled, a zero date leaves that side of the range open
+	StartDate time.Time
+	EndDate   time.Time
+
+	// the time zone of the bank statements options is the zone the banks book their statements in, system
+	// transactions are matched with the bank statements of the day they fall on in that zone
+	BankStatementsOptions     FileOptions
+	SystemTransactionsOptions FileOptions
+
+	// amounts are matched and summed in the reporting currency, records in other currencies are exchanged
+	// with the exchange rates file which is optional when every record already uses the reporting currency
+	ReportingCurrency string
+	ExchangeRates     multipart.File
+
+	ValidationMode ValidationMode // ValidationStrict when empty
+
+	Progress *Progress // counts the records while the reconciliation runs, nil when nobody follows it
 }
 
+// BankStatementsUpload is one uploaded bank statements file
+type BankStatementsUpload struct {
+	File multipart.File
+	Name string // name of the uploaded file, every row of the file is tagged with it
+	Bank string // bank source of the rows that have no bank column value, empty to read it from unique_identifier
+}
+
+// FileOptions describes how the values of an uploaded file are written
+type FileOptions struct {
+	NumberFormat money.NumberFormat // separators used in the amount column
+	Currency     string             // currency of amounts written without currency symbol or code
+	Format       string             // layout of the file, the csv layout of the records when empty
+	Sheet        string             // sheet of an xlsx workbook, the first sheet when empty
+	HeaderRow    int                // row of the header in the sheet of an xlsx workbook, the first row when 0
+	Layout       ColumnLayout       // headers and separators of a csv file that is not written like the default csv
+	DateFormat   dates.Format       // layout of the date column, the default layout of the file when empty
+	Location     *time.Location     // time zone of the dates written without offset, DefaultLocation when nil
+
+	// reads the bank source of a bank statement from its unique_identifier with the named group BankSourceGroup,
+	// it is not used for rows that have a value in the bank column
+	BankSourcePattern *regexp.Regexp
+}
+
+// DefaultLocation is the time zone of the dates of the uploaded files when none is set, the bank statements are
+// booked in Western Indonesia Time
+var DefaultLocation = mustLoadLocation("Asia/Jakarta")
+
+func mustLoadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return location
+}
+
+// ColumnLayout describes a csv file that has other headers than the default headers, no header at all, or other
+// separators. It can be sent with every request or saved as a named profile
+type ColumnLayout struct {
+	Columns    map[string]string `json:"columns"`    // header of the column in the file by its default header, or its 1-based position when Headerless
+	Headerless bool              `json:"headerless"` // the file has no header row, only the columns in Columns are read
+	Delimiter  string            `json:"delimiter"`  // separator of the columns, "," when empty
+	Quote      string            `json:"quote"`      // character that quotes values, `"` when empty
+}
+
+// IsDefault reports whether the file is written like the default csv
+func (l ColumnLayout) IsDefault() bool {
+	return len(l.Columns) == 0 && !l.Headerless && (l.Delimiter == "" || l.Delimiter == ",") && (l.Quote == "" || l.Quote == `"`)
+}
+
+// Validate checks that every column of the layout is one of the default headers and the separators are single characters
+func (l ColumnLayout) Validate(headers []string) error {
+	for column, value := range l.Columns {
+		known := false
+		for _, header := range headers {
+			known = known || column == header
+		}
+		if !known {
+			return fmt.Errorf("column %s is unknown, use %s", column, strings.Join(headers, ", "))
+		}
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("column %s is mapped to an empty header", column)
+		}
+		if l.Headerless {
+			position, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || position < 1 {
+				return fmt.Errorf("column %s must be mapped to a position from 1 when the file has no header", column)
+			}
+		}
+	}
+	if l.Headerless && len(l.Columns) == 0 {
+		return errors.New("columns must be mapped to their position when the file has no header")
+	}
+
+	delimiter, quote := l.Separators()
+	if utf8.RuneCountInString(l.Delimiter) > 1 || delimiter == '\n' || delimiter == '\r' {
+		return errors.New("delimiter must be a single character")
+	}
+	if utf8.RuneCountInString(l.Quote) > 1 || quote == '\n' || quote == '\r' {
+		return errors.New("quote must be a single character")
+	}
+	if delimiter == quote {
+		return errors.New("delimiter and quote must be different characters")
+	}
+	return nil
+}
+
+// Separators returns the delimiter and quote characters of the file
+func (l ColumnLayout) Separators() (delimiter, quote rune) {
+	delimiter, quote = ',', '"'
+	if l.Delimiter != "" {
+		delimiter, _ = utf8.DecodeRuneInString(l.Delimiter)
+	}
+	if l.Quote != "" {
+		quote, _ = utf8.DecodeRuneInString(l.Quote)
+	}
+	return delimiter, quote
+}
+
+// DefaultBankSourcePattern reads the bank source of unique_identifier written like BCA_123
+var DefaultBankSourcePattern = regexp.MustCompile(`^(?P<bank>[^_]*)_[^_]*$`)
+
+// WithDefaults fills the options that are not set, amounts are written like 1,500,000.00 in rupiah by default
+func (o FileOptions) WithDefaults() FileOptions {
+	if o.NumberFormat == "" {
+		o.NumberFormat = money.FormatEnglish
+	}
+	if o.Currency == "" {
+		o.Currency = money.DefaultCurrency
+	}
+	if o.BankSourcePattern == nil {
+		o.BankSourcePattern = DefaultBankSourcePattern
+	}
+	if o.Location == nil {
+		o.Location = DefaultLocation
+	}
+	return o
+}
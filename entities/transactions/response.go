@@ -1,10 +1,48 @@
 package transactions
 
+import "amartha-test/money"
+
 type DoReconciliationResponse struct {
-	TransactionsProceed       int `json:"transaction_proceed"`
-	MatchedTransaction        int `json:"matched_transaction"`
-	UnmatchedTransaction      int `json:"unmatched_transaction"`
+	RunID                     string                      `json:"run_id,omitempty"` // id of the stored run, empty when runs are not stored
+	TransactionsProceed       int                         `json:"transaction_proceed"`
+	MatchedTransaction        int                         `json:"matched_transaction"`
+	UnmatchedTransaction      int                         `json:"unmatched_transaction"`
+	MatchedTransactions       []MatchedTransaction        `json:"matched_transactions"`
+	MatchedWithDifference     []MatchedTransaction        `json:"matched_with_difference"`
 	MissingBankStatements     map[string][]BankStatements `json:"missing_bank_statements"`
-	MissingSystemTransactions []SystemTransactions `json:"missing_system_transactions"`
-	TotalDiscrepancies        float64 `json:"total_discripencies"`
+	MissingSystemTransactions []SystemTransactions        `json:"missing_system_transactions"`
+	TotalDiscrepancies        money.Money                 `json:"total_discripencies"` // sum of the absolute difference of every matched pair
+	ReportingCurrency         string                      `json:"reporting_currency"`
+	TimeZone                  string                      `json:"time_zone"` // zone of the booking days the records are matched on
+	CurrencySubtotals         map[string]CurrencySubtotal `json:"currency_subtotals"`
+	RejectedRows              []RowError                  `json:"rejected_rows"` // invalid rows skipped in lenient validation mode
+}
+
+// RowError describes one invalid value of an uploaded file
+type RowError struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"` // line number in the file, the header is line 1
+	Column string `json:"column"`
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+// CurrencySubtotal sums the amounts of the records written in one currency, before any exchange
+type CurrencySubtotal struct {
+	MatchedAmount                   money.Money `json:"matched_amount"` // amount of the matched system transactions
+	MissingBankStatementsAmount     money.Money `json:"missing_bank_statements_amount"`
+	MissingSystemTransactionsAmount money.Money `json:"missing_system_transactions_amount"`
+}
+
+// MatchedTransaction is a system transaction paired with the bank statement that settled it
+type MatchedTransaction struct {
+	TransactionID    string      `json:"trxID"`
+	UniqueIdentifier string      `json:"unique_identifier"`
+	BankSource       string      `json:"bank_source"`
+	Type             int         `json:"type"`
+	Amount           money.Money `json:"amount"`
+	BankAmount       money.Money `json:"bank_amount"`
+	Difference       money.Money `json:"difference"` // bank amount minus system amount in reporting currency, negative when the bank received less
+	TransactionTime  string      `json:"transactionTime"`
+	Date             string      `json:"date"`
 }
@@ -0,0 +1,100 @@
+package transactions
+
+import (
+	"amartha-test/dates"
+	"amartha-test/money"
+	"time"
+)
+
+// RunStatus is the outcome of a reconciliation run
+type RunStatus string
+
+const (
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed" // the error of the reconciliation is kept with the run
+)
+
+// ReconciliationRun is a reconciliation stored with what it was run on, so it can be looked up and audited later
+type ReconciliationRun struct {
+	ID          string                   `json:"id"`
+	Status      RunStatus                `json:"status"`
+	Error       string                   `json:"error,omitempty"`
+	StartedAt   time.Time                `json:"started_at"`
+	FinishedAt  time.Time                `json:"finished_at"`
+	PeriodStart string                   `json:"period_start"` // first booking day reconciled, written with DateRangeFormat
+	PeriodEnd   string                   `json:"period_end"`   // last booking day reconciled, written with DateRangeFormat
+	Parameters  RunParameters            `json:"parameters"`
+	Checksums   []FileChecksum           `json:"checksums"`
+	Result      DoReconciliationResponse `json:"result"`
+}
+
+// RunParameters are the parameters of the request of a reconciliation run
+type RunParameters struct {
+	StartDate              string         `json:"start_date,omitempty"`
+	EndDate                string         `json:"end_date,omitempty"`
+	DateToleranceDays      int            `json:"date_tolerance_days"`
+	AmountTolerance        string         `json:"amount_tolerance"`
+	AmountTolerancePercent float64        `json:"amount_tolerance_percent"`
+	ReportingCurrency      string         `json:"reporting_currency,omitempty"`
+	ValidationMode         ValidationMode `json:"validation_mode,omitempty"`
+	BankStatementsFormat   string         `json:"bank_statements_format,omitempty"`
+	BankSourcePattern      string         `json:"bank_source_pattern,omitempty"`
+	TimeZone               string         `json:"time_zone"`
+
+	// how the uploaded files were read, the same files read with other options give another result
+	BankStatementsOptions     RunFileOptions `json:"bank_statements_options"`
+	SystemTransactionsOptions RunFileOptions `json:"system_transactions_options"`
+}
+
+// RunFileOptions are the options an uploaded file of a reconciliation run was read with
+type RunFileOptions struct {
+	NumberFormat money.NumberFormat `json:"number_format"`
+	Currency     string             `json:"currency"`
+	DateFormat   dates.Format       `json:"date_format,omitempty"`
+	TimeZone     string             `json:"time_zone"`
+	Sheet        string             `json:"sheet,omitempty"`
+	HeaderRow    int                `json:"header_row,omitempty"`
+	Layout       ColumnLayout       `json:"layout"`
+}
+
+// FileChecksum is the SHA-256 checksum of an uploaded file
+type FileChecksum struct {
+	File   string `json:"file"` // form field of the file
+	Name   string `json:"name,omitempty"`
+	SHA256 string `json:"sha256"`
+}
+
+// ReconciliationRunSummary is a stored run without its matched pairs and unmatched items
+type ReconciliationRunSummary struct {
+	ID                   string      `json:"id"`
+	Status               RunStatus   `json:"status"`
+	Error                string      `json:"error,omitempty"`
+	StartedAt            time.Time   `json:"started_at"`
+	FinishedAt           time.Time   `json:"finished_at"`
+	PeriodStart          string      `json:"period_start"`
+	PeriodEnd            string      `json:"period_end"`
+	TransactionsProceed  int         `json:"transaction_proceed"`
+	MatchedTransaction   int         `json:"matched_transaction"`
+	UnmatchedTransaction int         `json:"unmatched_transaction"`
+	TotalDiscrepancies   money.Money `json:"total_discripencies"`
+	ReportingCurrency    string      `json:"reporting_currency"`
+}
+
+// GetReconciliationRunsRequest filters the stored runs, empty fields don't filter
+type GetReconciliationRunsRequest struct {
+	// runs that reconciled a booking day inside the range, a zero date leaves that side of the range open
+	StartDate time.Time
+	EndDate   time.Time
+
+	BankSource string // runs with a matched pair or a missing bank statement of the bank source
+	Status     RunStatus
+	Page       int // page of the runs from 1, the most recent runs first
+	PageSize   int
+}
+
+type GetReconciliationRunsResponse struct {
+	Runs     []ReconciliationRunSummary `json:"runs"`
+	Page     int                        `json:"page"`
+	PageSize int                        `json:"page_size"`
+	Total    int                        `json:"total"` // number of runs of every page
+}
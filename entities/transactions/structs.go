@@ -1,17 +1,24 @@
 package transactions
 
-import "time"
+import (
+	"amartha-test/money"
+	"time"
+)
 
 type SystemTransactions struct {
-	TransactionID       string    `json:"trxID" csv:"trxID"`
-	Amount              string    `json:"amount" csv:"amount"`
-	RealAmount          float64   `json:"-" csv:"-"`
-	Type                int       `json:"type" csv:"type"`
-	TransactionTime     string    `json:"transactionTime" csv:"transactionTime"`
-	RealTransactionTime time.Time `json:"-" csv:"-"`
+	TransactionID       string      `json:"trxID" csv:"trxID"`
+	Amount              string      `json:"amount" csv:"amount"`
+	RealAmount          money.Money `json:"-" csv:"-"`
+	Currency            string      `json:"currency" csv:"-"`
+	ReportingAmount     money.Money `json:"-" csv:"-"`    // RealAmount exchanged to the reporting currency
+	RawType             string      `json:"-" csv:"type"` // 1, 2, DEBIT or CREDIT, can be empty when the amount is signed
+	Type                int         `json:"type" csv:"-"`
+	TransactionTime     string      `json:"transactionTime" csv:"transactionTime"`
+	RealTransactionTime time.Time   `json:"-" csv:"-"`
+	Line                int         `json:"-" csv:"-"` // line number in the uploaded file
 }
 
-// SortByRealDateSystemTransaction implements sort.Interface for []BankStatements based on the RealTransactionTime field.
+// SortByRealDateSystemTransaction implements sort.Interface for []SystemTransactions based on the RealTransactionTime, Type and ReportingAmount fields.
 type SortByRealDateSystemTransaction []*SystemTransactions
 
 func (a SortByRealDateSystemTransaction) Len() int      { return len(a) }
@@ -20,28 +27,57 @@ func (a SortByRealDateSystemTransaction) Less(i, j int) bool {
 	if a[i].RealTransactionTime != a[j].RealTransactionTime {
 		return a[i].RealTransactionTime.Before(a[j].RealTransactionTime)
 	}
-	return a[i].Type < a[j].Type
+	if a[i].Type != a[j].Type {
+		return a[i].Type < a[j].Type
+	}
+	return a[i].ReportingAmount.Abs().Amount < a[j].ReportingAmount.Abs().Amount
+}
 
+// AbsoluteAmount returns the amount of the transaction without its sign
+func (s SystemTransactions) AbsoluteAmount() money.Money {
+	return s.RealAmount.Abs()
 }
 
 type BankStatements struct {
-	ID         string    `json:"unique_identifier" csv:"unique_identifier"` // contain bank source information, example : BCA_123, BRI_256, separated by underscore
-	Amount     string    `json:"amount" csv:"amount"`                       // if negative, then type is DEBIT, else CREDIT
-	RealAmount float64   `json:"-" csv:"-"`
-	Date       string    `json:"date" csv:"date"`
-	RealDate   time.Time `json:"-" csv:"-"`
-	BankSource string    `json:"bank_source"  csv:"-"`
-	Type       int       `json:"-" csv:"-"`
+	ID              string      `json:"unique_identifier" csv:"unique_identifier"` // contain bank source information, example : BCA_123, BRI_256, separated by underscore
+	Amount          string      `json:"amount" csv:"amount"`                       // if negative, then type is DEBIT, else CREDIT
+	RealAmount      money.Money `json:"-" csv:"-"`
+	Currency        string      `json:"currency" csv:"-"`
+	ReportingAmount money.Money `json:"-" csv:"-"` // RealAmount exchanged to the reporting currency
+	Date            string      `json:"date" csv:"date"`
+	RealDate        time.Time   `json:"-" csv:"-"`
+	Bank            string      `json:"-" csv:"bank"` // optional column with the bank source, used instead of the unique_identifier
+	BankSource      string      `json:"bank_source"  csv:"-"`
+	SourceFile      string      `json:"source_file" csv:"-"` // name of the uploaded file of the bank statement
+	Description     string      `json:"description" csv:"-"` // narrative of the bank, only given by some statement formats
+	Type            int         `json:"-" csv:"-"`
+	Line            int         `json:"-" csv:"-"` // line number in the uploaded file
 }
 
-// SortByRealDateSystemTransaction implements sort.Interface for []BankStatements based on the RealDate field.
+// SortByRealDateBankStatement implements sort.Interface for []BankStatements based on the RealDate, Type and ReportingAmount fields.
 type SortByRealDateBankStatement []*BankStatements
 
-func (a SortByRealDateBankStatement) Len() int           { return len(a) }
-func (a SortByRealDateBankStatement) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a SortByRealDateBankStatement) Less(i, j int) bool { 	
+func (a SortByRealDateBankStatement) Len() int      { return len(a) }
+func (a SortByRealDateBankStatement) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a SortByRealDateBankStatement) Less(i, j int) bool {
 	if a[i].RealDate != a[j].RealDate {
 		return a[i].RealDate.Before(a[j].RealDate)
 	}
-	return a[i].Type < a[j].Type
+	if a[i].Type != a[j].Type {
+		return a[i].Type < a[j].Type
+	}
+	return a[i].ReportingAmount.Abs().Amount < a[j].ReportingAmount.Abs().Amount
+}
+
+// AbsoluteAmount returns the amount of the statement without its sign, DEBIT statements have negative amount
+func (b BankStatements) AbsoluteAmount() money.Money {
+	return b.RealAmount.Abs()
+}
+
+// ExchangeRate is one row of the exchange rates file, one unit of From currency is worth Rate units of To currency from Date
+type ExchangeRate struct {
+	Date string `json:"date" csv:"date"`
+	From string `json:"from" csv:"from"`
+	To   string `json:"to" csv:"to"`
+	Rate string `json:"rate" csv:"rate"`
 }
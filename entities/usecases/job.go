@@ -0,0 +1,16 @@
+package usecases
+
+import (
+	"amartha-test/entities/transactions"
+	"context"
+)
+
+//go:generate mockgen -destination mock/mock_jobs.go -source=job.go JobUsecase
+
+// JobUsecase runs reconciliations in the background
+type JobUsecase interface {
+	// SubmitReconciliation takes the files of the request when the job is queued, they are closed when the job
+	// finishes
+	SubmitReconciliation(ctx context.Context, param transactions.DoReconciliationRequest, callbackURL string) (transactions.ReconciliationJob, error)
+	GetJob(ctx context.Context, id string) (transactions.ReconciliationJob, error)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: job.go
+
+// Package mock_usecases is a generated GoMock package.
+package mock_usecases
+
+import (
+	transactions "amartha-test/entities/transactions"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockJobUsecase is a mock of JobUsecase interface.
+type MockJobUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobUsecaseMockRecorder
+}
+
+// MockJobUsecaseMockRecorder is the mock recorder for MockJobUsecase.
+type MockJobUsecaseMockRecorder struct {
+	mock *MockJobUsecase
+}
+
+// NewMockJobUsecase creates a new mock instance.
+func NewMockJobUsecase(ctrl *gomock.Controller) *MockJobUsecase {
+	mock := &MockJobUsecase{ctrl: ctrl}
+	mock.recorder = &MockJobUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobUsecase) EXPECT() *MockJobUsecaseMockRecorder {
+	return m.recorder
+}
+
+// GetJob mocks base method.
+func (m *MockJobUsecase) GetJob(ctx context.Context, id string) (transactions.ReconciliationJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(transactions.ReconciliationJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockJobUsecaseMockRecorder) GetJob(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockJobUsecase)(nil).GetJob), ctx, id)
+}
+
+// SubmitReconciliation mocks base method.
+func (m *MockJobUsecase) SubmitReconciliation(ctx context.Context, param transactions.DoReconciliationRequest, callbackURL string) (transactions.ReconciliationJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitReconciliation", ctx, param, callbackURL)
+	ret0, _ := ret[0].(transactions.ReconciliationJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitReconciliation indicates an expected call of SubmitReconciliation.
+func (mr *MockJobUsecaseMockRecorder) SubmitReconciliation(ctx, param, callbackURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitReconciliation", reflect.TypeOf((*MockJobUsecase)(nil).SubmitReconciliation), ctx, param, callbackURL)
+}
@@ -49,3 +49,33 @@ func (mr *MockTransactionUsecaseMockRecorder) DoReconciliation(ctx, param interf
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoReconciliation", reflect.TypeOf((*MockTransactionUsecase)(nil).DoReconciliation), ctx, param)
 }
+
+// GetReconciliationRun mocks base method.
+func (m *MockTransactionUsecase) GetReconciliationRun(ctx context.Context, id string) (transactions.ReconciliationRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReconciliationRun", ctx, id)
+	ret0, _ := ret[0].(transactions.ReconciliationRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReconciliationRun indicates an expected call of GetReconciliationRun.
+func (mr *MockTransactionUsecaseMockRecorder) GetReconciliationRun(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReconciliationRun", reflect.TypeOf((*MockTransactionUsecase)(nil).GetReconciliationRun), ctx, id)
+}
+
+// GetReconciliationRuns mocks base method.
+func (m *MockTransactionUsecase) GetReconciliationRuns(ctx context.Context, param transactions.GetReconciliationRunsRequest) (transactions.GetReconciliationRunsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReconciliationRuns", ctx, param)
+	ret0, _ := ret[0].(transactions.GetReconciliationRunsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReconciliationRuns indicates an expected call of GetReconciliationRuns.
+func (mr *MockTransactionUsecaseMockRecorder) GetReconciliationRuns(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReconciliationRuns", reflect.TypeOf((*MockTransactionUsecase)(nil).GetReconciliationRuns), ctx, param)
+}
@@ -10,4 +10,6 @@ import (
 
 type TransactionUsecase interface {
 	DoReconciliation(ctx context.Context, param transactions.DoReconciliationRequest) (transactions.DoReconciliationResponse, error)
+	GetReconciliationRuns(ctx context.Context, param transactions.GetReconciliationRunsRequest) (transactions.GetReconciliationRunsResponse, error)
+	GetReconciliationRun(ctx context.Context, id string) (transactions.ReconciliationRun, error)
 }
\ No newline at end of file
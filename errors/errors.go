@@ -31,6 +31,12 @@ func SetInternalServerErrorForHandler(w http.ResponseWriter, errValue error) (er
 func SetError(w http.ResponseWriter, errValue interface{}) (err error) {
 	if errType, ok := errValue.(*ErrorMessage); ok {
 		return SetBadRequestErrorForHandler(w, errType.ErrorDescription)
+	} else if errType, ok := errValue.(*ValidationError); ok {
+		return SetUnprocessableEntityErrorForHandler(w, errType)
+	} else if errType, ok := errValue.(*NotFoundError); ok {
+		return SetNotFoundErrorForHandler(w, errType.ErrorDescription)
+	} else if errType, ok := errValue.(*ServiceUnavailableError); ok {
+		return SetServiceUnavailableErrorForHandler(w, errType.ErrorDescription)
 	} else if errType, ok := errValue.(error); ok {
 		return SetInternalServerErrorForHandler(w, errType)
 	}
@@ -52,3 +58,81 @@ func NewBadRequestError(errValue string) *ErrorMessage {
 		ErrorDescription: errValue,
 	}
 }
+
+// ValidationError is returned when some rows of the uploaded data are invalid, Errors lists every invalid value
+type ValidationError struct {
+	ErrorDescription string      `json:"error_description"`
+	Errors           interface{} `json:"errors"`
+}
+
+func NewValidationError(errValue string, errors interface{}) *ValidationError {
+	return &ValidationError{
+		ErrorDescription: errValue,
+		Errors:           errors,
+	}
+}
+
+func (e *ValidationError) Error() string {
+	return e.ErrorDescription
+}
+
+func SetUnprocessableEntityErrorForHandler(w http.ResponseWriter, errValue *ValidationError) (err error) {
+	_, err = response.WriteJSONResponse(w, http.StatusUnprocessableEntity, errValue)
+
+	return
+}
+
+// SetPayloadTooLargeErrorForHandler writes the error of a request body bigger than the server accepts
+func SetPayloadTooLargeErrorForHandler(w http.ResponseWriter, errValue string) (err error) {
+	_, err = response.WriteJSONResponse(w, http.StatusRequestEntityTooLarge, &ErrorMessage{
+		ErrorDescription: errValue,
+	})
+
+	return
+}
+
+// NotFoundError is returned when the resource of the request does not exist
+type NotFoundError struct {
+	ErrorDescription string `json:"error_description"`
+}
+
+func NewNotFoundError(errValue string) *NotFoundError {
+	return &NotFoundError{
+		ErrorDescription: errValue,
+	}
+}
+
+func (e *NotFoundError) Error() string {
+	return e.ErrorDescription
+}
+
+func SetNotFoundErrorForHandler(w http.ResponseWriter, errValue string) (err error) {
+	_, err = response.WriteJSONResponse(w, http.StatusNotFound, &NotFoundError{
+		ErrorDescription: errValue,
+	})
+
+	return
+}
+
+// ServiceUnavailableError is returned when the server can't take the request now, it can be sent again later
+type ServiceUnavailableError struct {
+	ErrorDescription string `json:"error_description"`
+}
+
+func NewServiceUnavailableError(errValue string) *ServiceUnavailableError {
+	return &ServiceUnavailableError{
+		ErrorDescription: errValue,
+	}
+}
+
+func (e *ServiceUnavailableError) Error() string {
+	return e.ErrorDescription
+}
+
+func SetServiceUnavailableErrorForHandler(w http.ResponseWriter, errValue string) (err error) {
+	_, err = response.WriteJSONResponse(w, http.StatusServiceUnavailable, &ServiceUnavailableError{
+		ErrorDescription: errValue,
+	})
+
+	return
+}
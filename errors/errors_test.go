@@ -82,6 +82,30 @@ func TestSetError(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Succesful Validation Error",
+			args: args{
+				w:        httptest.NewRecorder(),
+				errValue: NewValidationError("error", []string{"error"}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Succesful Not Found Error",
+			args: args{
+				w:        httptest.NewRecorder(),
+				errValue: NewNotFoundError("error"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "Succesful Service Unavailable Error",
+			args: args{
+				w:        httptest.NewRecorder(),
+				errValue: NewServiceUnavailableError("error"),
+			},
+			wantErr: false,
+		},
 		{
 			name: "Succesful Error",
 			args: args{
@@ -163,3 +187,59 @@ func TestNewErrorMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestSetUnprocessableEntityErrorForHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := SetUnprocessableEntityErrorForHandler(w, NewValidationError("error", []string{"invalid"}))
+	if err != nil {
+		t.Errorf("SetUnprocessableEntityErrorForHandler() error = %v", err)
+	}
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("SetUnprocessableEntityErrorForHandler() status = %v, want %v", w.Code, http.StatusUnprocessableEntity)
+	}
+	if w.Body.String() != `{"error_description":"error","errors":["invalid"]}` {
+		t.Errorf("SetUnprocessableEntityErrorForHandler() body = %v", w.Body.String())
+	}
+}
+
+func TestSetPayloadTooLargeErrorForHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := SetPayloadTooLargeErrorForHandler(w, "error")
+	if err != nil {
+		t.Errorf("SetPayloadTooLargeErrorForHandler() error = %v", err)
+	}
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("SetPayloadTooLargeErrorForHandler() status = %v, want %v", w.Code, http.StatusRequestEntityTooLarge)
+	}
+	if w.Body.String() != `{"error_description":"error"}` {
+		t.Errorf("SetPayloadTooLargeErrorForHandler() body = %v", w.Body.String())
+	}
+}
+
+func TestSetNotFoundErrorForHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := SetNotFoundErrorForHandler(w, "error")
+	if err != nil {
+		t.Errorf("SetNotFoundErrorForHandler() error = %v", err)
+	}
+	if w.Code != http.StatusNotFound {
+		t.Errorf("SetNotFoundErrorForHandler() status = %v, want %v", w.Code, http.StatusNotFound)
+	}
+	if w.Body.String() != `{"error_description":"error"}` {
+		t.Errorf("SetNotFoundErrorForHandler() body = %v", w.Body.String())
+	}
+}
+
+func TestSetServiceUnavailableErrorForHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := SetServiceUnavailableErrorForHandler(w, "error")
+	if err != nil {
+		t.Errorf("SetServiceUnavailableErrorForHandler() error = %v", err)
+	}
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("SetServiceUnavailableErrorForHandler() status = %v, want %v", w.Code, http.StatusServiceUnavailable)
+	}
+	if w.Body.String() != `{"error_description":"error"}` {
+		t.Errorf("SetServiceUnavailableErrorForHandler() body = %v", w.Body.String())
+	}
+}
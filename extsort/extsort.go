@@ -0,0 +1,239 @@
+package extsort
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"sort"
+)
+
+// Sorter sorts more items than fit in memory. Items are kept in memory up to the buffer size, every full buffer is
+// sorted and written to a temporary file as a run, and the runs are merged when the items are read back. Items that
+// are equal keep the order they were added in. Items are written with encoding/gob so only their exported fields
+// are kept
+type Sorter[T any] struct {
+	less       func(a, b T) bool
+	bufferSize int
+	dir        string
+
+	buffer []T
+	runs   []string
+	count  int
+}
+
+// New creates a sorter that keeps at most bufferSize items in memory, the runs are written in dir or in the
+// default directory for temporary files when dir is empty
+func New[T any](less func(a, b T) bool, bufferSize int, dir string) *Sorter[T] {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	return &Sorter[T]{
+		less:       less,
+		bufferSize: bufferSize,
+		dir:        dir,
+	}
+}
+
+// Add adds an item, the buffer is written as a run when it is full
+func (s *Sorter[T]) Add(item T) error {
+	s.buffer = append(s.buffer, item)
+	s.count++
+	if len(s.buffer) < s.bufferSize {
+		return nil
+	}
+	return s.spill()
+}
+
+// Len returns the number of items added
+func (s *Sorter[T]) Len() int {
+	return s.count
+}
+
+// Runs returns the number of runs written to disk
+func (s *Sorter[T]) Runs() int {
+	return len(s.runs)
+}
+
+// spill sorts the buffer and writes it to a new run
+func (s *Sorter[T]) spill() (err error) {
+	sort.SliceStable(s.buffer, func(i, j int) bool {
+		return s.less(s.buffer[i], s.buffer[j])
+	})
+
+	file, err := os.CreateTemp(s.dir, "extsort-*.run")
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, file.Name())
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	writer := bufio.NewWriter(file)
+	encoder := gob.NewEncoder(writer)
+	for _, item := range s.buffer {
+		err = encoder.Encode(item)
+		if err != nil {
+			return err
+		}
+	}
+
+	clear(s.buffer)
+	s.buffer = s.buffer[:0]
+	return writer.Flush()
+}
+
+// Sorted returns an iterator over every item added in sorted order, no item can be added after
+func (s *Sorter[T]) Sorted() (*Iterator[T], error) {
+	sort.SliceStable(s.buffer, func(i, j int) bool {
+		return s.less(s.buffer[i], s.buffer[j])
+	})
+
+	iterator := &Iterator[T]{runs: runHeap[T]{less: s.less}}
+	for index, name := range s.runs {
+		file, err := os.Open(name)
+		if err != nil {
+			iterator.Close()
+			return nil, err
+		}
+
+		source := &runSource[T]{index: index, file: file, decoder: gob.NewDecoder(bufio.NewReader(file))}
+		ok, err := source.next()
+		if err != nil {
+			file.Close()
+			iterator.Close()
+			return nil, err
+		}
+		iterator.files = append(iterator.files, file)
+		if ok {
+			iterator.runs.sources = append(iterator.runs.sources, source)
+		}
+	}
+
+	// the buffer holds the items added last so it is merged after every run
+	if len(s.buffer) > 0 {
+		source := &runSource[T]{index: len(s.runs), items: s.buffer}
+		source.next()
+		iterator.runs.sources = append(iterator.runs.sources, source)
+	}
+
+	heap.Init(&iterator.runs)
+	return iterator, nil
+}
+
+// Close removes the runs written to disk
+func (s *Sorter[T]) Close() error {
+	var errs []error
+	for _, name := range s.runs {
+		err := os.Remove(name)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	s.runs = nil
+	s.buffer = nil
+	return errors.Join(errs...)
+}
+
+// runSource reads the items of one run, from its file or from memory
+type runSource[T any] struct {
+	index   int
+	file    *os.File
+	decoder *gob.Decoder
+	items   []T
+	current T
+}
+
+// next reads the next item of the run into current, it returns false when the run has no item left
+func (r *runSource[T]) next() (bool, error) {
+	if r.decoder == nil {
+		if len(r.items) == 0 {
+			return false, nil
+		}
+		r.current, r.items = r.items[0], r.items[1:]
+		return true, nil
+	}
+
+	var item T
+	err := r.decoder.Decode(&item)
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	r.current = item
+	return true, nil
+}
+
+// Iterator reads the items of a sorter in sorted order by merging its runs
+type Iterator[T any] struct {
+	runs  runHeap[T]
+	files []*os.File
+}
+
+// Next returns the next item in sorted order, ok is false when every item has been read
+func (it *Iterator[T]) Next() (item T, ok bool, err error) {
+	if len(it.runs.sources) == 0 {
+		return item, false, nil
+	}
+
+	source := it.runs.sources[0]
+	item = source.current
+
+	more, err := source.next()
+	if err != nil {
+		return item, false, err
+	}
+	if more {
+		heap.Fix(&it.runs, 0)
+	} else {
+		heap.Pop(&it.runs)
+	}
+	return item, true, nil
+}
+
+// Close closes the files of the runs
+func (it *Iterator[T]) Close() error {
+	var errs []error
+	for _, file := range it.files {
+		errs = append(errs, file.Close())
+	}
+	it.files = nil
+	it.runs.sources = nil
+	return errors.Join(errs...)
+}
+
+// runHeap orders the runs by their current item, equal items are read from the run written first
+type runHeap[T any] struct {
+	less    func(a, b T) bool
+	sources []*runSource[T]
+}
+
+func (h runHeap[T]) Len() int { return len(h.sources) }
+
+func (h runHeap[T]) Swap(i, j int) { h.sources[i], h.sources[j] = h.sources[j], h.sources[i] }
+
+func (h runHeap[T]) Less(i, j int) bool {
+	a, b := h.sources[i], h.sources[j]
+	if h.less(a.current, b.current) {
+		return true
+	}
+	if h.less(b.current, a.current) {
+		return false
+	}
+	return a.index < b.index
+}
+
+func (h *runHeap[T]) Push(x any) { h.sources = append(h.sources, x.(*runSource[T])) }
+
+func (h *runHeap[T]) Pop() any {
+	last := h.sources[len(h.sources)-1]
+	h.sources = h.sources[:len(h.sources)-1]
+	return last
+}
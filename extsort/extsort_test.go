@@ -0,0 +1,117 @@
+package extsort
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+type record struct {
+	Key   int
+	Value string
+}
+
+func TestSorter(t *testing.T) {
+	records := []record{
+		{Key: 3, Value: "a"},
+		{Key: 1, Value: "b"},
+		{Key: 2, Value: "c"},
+		{Key: 1, Value: "d"},
+		{Key: 3, Value: "e"},
+		{Key: 0, Value: "f"},
+		{Key: 1, Value: "g"},
+	}
+
+	tests := []struct {
+		name       string
+		bufferSize int
+		records    []record
+		want       []record
+		wantRuns   int
+	}{
+		{
+			name:       "Succesful in memory",
+			bufferSize: 10,
+			records:    records,
+			want: []record{
+				{Key: 0, Value: "f"},
+				{Key: 1, Value: "b"},
+				{Key: 1, Value: "d"},
+				{Key: 1, Value: "g"},
+				{Key: 2, Value: "c"},
+				{Key: 3, Value: "a"},
+				{Key: 3, Value: "e"},
+			},
+			wantRuns: 0,
+		},
+		{
+			name:       "Succesful with runs on disk",
+			bufferSize: 2,
+			records:    records,
+			want: []record{
+				{Key: 0, Value: "f"},
+				{Key: 1, Value: "b"},
+				{Key: 1, Value: "d"},
+				{Key: 1, Value: "g"},
+				{Key: 2, Value: "c"},
+				{Key: 3, Value: "a"},
+				{Key: 3, Value: "e"},
+			},
+			wantRuns: 3,
+		},
+		{
+			name:       "Succesful without records",
+			bufferSize: 2,
+			records:    nil,
+			want:       nil,
+			wantRuns:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			sorter := New(func(a, b record) bool { return a.Key < b.Key }, tt.bufferSize, dir)
+			for _, r := range tt.records {
+				if err := sorter.Add(r); err != nil {
+					t.Fatalf("Sorter.Add() error = %v", err)
+				}
+			}
+			if sorter.Runs() != tt.wantRuns {
+				t.Errorf("Sorter.Runs() = %v, want %v", sorter.Runs(), tt.wantRuns)
+			}
+			if sorter.Len() != len(tt.records) {
+				t.Errorf("Sorter.Len() = %v, want %v", sorter.Len(), len(tt.records))
+			}
+
+			iterator, err := sorter.Sorted()
+			if err != nil {
+				t.Fatalf("Sorter.Sorted() error = %v", err)
+			}
+			var got []record
+			for {
+				r, ok, err := iterator.Next()
+				if err != nil {
+					t.Fatalf("Iterator.Next() error = %v", err)
+				}
+				if !ok {
+					break
+				}
+				got = append(got, r)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Iterator.Next() = %v, want %v", got, tt.want)
+			}
+
+			if err := iterator.Close(); err != nil {
+				t.Errorf("Iterator.Close() error = %v", err)
+			}
+			if err := sorter.Close(); err != nil {
+				t.Errorf("Sorter.Close() error = %v", err)
+			}
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 0 {
+				t.Errorf("Sorter.Close() left %d runs", len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	libError "amartha-test/errors"
+	"amartha-test/response"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleGetJob returns the status and progress of the asynchronous reconciliation of the id
+func (handler TransactionHandler) HandleGetJob(w http.ResponseWriter, r *http.Request) {
+	if handler.JobUsecase == nil {
+		libError.SetBadRequestErrorForHandler(w, "asynchronous reconciliation is not available")
+		return
+	}
+
+	result, err := handler.JobUsecase.GetJob(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	response.SetOK(w, result)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"amartha-test/entities/transactions"
+	"amartha-test/entities/usecases"
+	usecaseMock "amartha-test/entities/usecases/mock"
+	libError "amartha-test/errors"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionHandler_HandleGetJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Mock JobUsecase
+	mockJobUsecase := usecaseMock.NewMockJobUsecase(ctrl)
+
+	tests := []struct {
+		name       string
+		mock       func()
+		httpStatus int
+		jobUsecase usecases.JobUsecase
+		id         string
+	}{
+		{
+			name: "Succesful",
+			mock: func() {
+				mockJobUsecase.EXPECT().GetJob(gomock.Any(), "job-1").
+					Return(transactions.ReconciliationJob{ID: "job-1", Status: transactions.JobRunning}, nil)
+			},
+			httpStatus: http.StatusOK,
+			jobUsecase: mockJobUsecase,
+			id:         "job-1",
+		},
+		{
+			name: "Failed with unknown id",
+			mock: func() {
+				mockJobUsecase.EXPECT().GetJob(gomock.Any(), "job-2").
+					Return(transactions.ReconciliationJob{}, libError.NewNotFoundError("reconciliation job job-2 is not found"))
+			},
+			httpStatus: http.StatusNotFound,
+			jobUsecase: mockJobUsecase,
+			id:         "job-2",
+		},
+		{
+			name:       "Failed without jobs",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
+			id:         "job-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			handler := TransactionHandler{JobUsecase: tt.jobUsecase}
+			routeContext := chi.NewRouteContext()
+			routeContext.URLParams.Add("id", tt.id)
+			r := httptest.NewRequest(http.MethodGet, "/jobs/"+tt.id, nil)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeContext))
+			w := httptest.NewRecorder()
+			handler.HandleGetJob(w, r)
+			assert.Equal(t, tt.httpStatus, w.Code)
+		})
+	}
+}
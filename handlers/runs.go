@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"amartha-test/entities/transactions"
+	libError "amartha-test/errors"
+	"amartha-test/response"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+)
+
+// HandleGetReconciliationRuns lists the stored runs filtered by the start_date, end_date, bank_source and status
+// query parameters, a page of page_size runs at a time
+func (handler TransactionHandler) HandleGetReconciliationRuns(w http.ResponseWriter, r *http.Request) {
+	startDate, err := formValueDate(r, "start_date")
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	endDate, err := formValueDate(r, "end_date")
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	page, err := formValueInt(r, "page")
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	pageSize, err := formValueInt(r, "page_size")
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	result, err := handler.TransactionUsecase.GetReconciliationRuns(r.Context(), transactions.GetReconciliationRunsRequest{
+		StartDate:  startDate,
+		EndDate:    endDate,
+		BankSource: strings.ToUpper(r.FormValue("bank_source")),
+		Status:     transactions.RunStatus(r.FormValue("status")),
+		Page:       page,
+		PageSize:   pageSize,
+	})
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	response.SetOK(w, result)
+}
+
+// HandleGetReconciliationRun returns the stored run of the id with its result
+func (handler TransactionHandler) HandleGetReconciliationRun(w http.ResponseWriter, r *http.Request) {
+	result, err := handler.TransactionUsecase.GetReconciliationRun(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	response.SetOK(w, result)
+}
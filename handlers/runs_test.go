@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"amartha-test/entities/transactions"
+	usecaseMock "amartha-test/entities/usecases/mock"
+	libError "amartha-test/errors"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionHandler_HandleGetReconciliationRuns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Mock TransactionUsecase
+	mockUsecase := usecaseMock.NewMockTransactionUsecase(ctrl)
+
+	tests := []struct {
+		name       string
+		mock       func()
+		httpStatus int
+		query      string
+	}{
+		{
+			name: "Succesful",
+			mock: func() {
+				mockUsecase.EXPECT().GetReconciliationRuns(gomock.Any(), transactions.GetReconciliationRunsRequest{
+					StartDate:  time.Date(2024, time.Month(1), 3, 0, 0, 0, 0, time.Local),
+					EndDate:    time.Date(2024, time.Month(1), 3, 0, 0, 0, 0, time.Local),
+					BankSource: "BRI",
+					Status:     transactions.RunSucceeded,
+					Page:       2,
+					PageSize:   10,
+				}).Return(transactions.GetReconciliationRunsResponse{}, nil)
+			},
+			httpStatus: http.StatusOK,
+			query:      "?start_date=2024-01-03&end_date=2024-01-03&bank_source=bri&status=succeeded&page=2&page_size=10",
+		},
+		{
+			name:       "Failed with invalid date",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
+			query:      "?start_date=03/01/2024",
+		},
+		{
+			name:       "Failed with invalid page",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
+			query:      "?page=first",
+		},
+		{
+			name: "Failed in usecase",
+			mock: func() {
+				mockUsecase.EXPECT().GetReconciliationRuns(gomock.Any(), gomock.Any()).
+					Return(transactions.GetReconciliationRunsResponse{}, libError.NewBadRequestError("page size can not be bigger than 100"))
+			},
+			httpStatus: http.StatusBadRequest,
+			query:      "?page_size=1000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			handler := TransactionHandler{TransactionUsecase: mockUsecase}
+			r := httptest.NewRequest(http.MethodGet, "/reconciliations"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.HandleGetReconciliationRuns(w, r)
+			assert.Equal(t, tt.httpStatus, w.Code)
+		})
+	}
+}
+
+func TestTransactionHandler_HandleGetReconciliationRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Mock TransactionUsecase
+	mockUsecase := usecaseMock.NewMockTransactionUsecase(ctrl)
+
+	tests := []struct {
+		name       string
+		mock       func()
+		httpStatus int
+		id         string
+	}{
+		{
+			name: "Succesful",
+			mock: func() {
+				mockUsecase.EXPECT().GetReconciliationRun(gomock.Any(), "run-1").
+					Return(transactions.ReconciliationRun{ID: "run-1"}, nil)
+			},
+			httpStatus: http.StatusOK,
+			id:         "run-1",
+		},
+		{
+			name: "Failed with unknown id",
+			mock: func() {
+				mockUsecase.EXPECT().GetReconciliationRun(gomock.Any(), "run-2").
+					Return(transactions.ReconciliationRun{}, libError.NewNotFoundError("reconciliation run run-2 is not found"))
+			},
+			httpStatus: http.StatusNotFound,
+			id:         "run-2",
+		},
+		{
+			name: "Failed in usecase",
+			mock: func() {
+				mockUsecase.EXPECT().GetReconciliationRun(gomock.Any(), "run-3").
+					Return(transactions.ReconciliationRun{}, errMock)
+			},
+			httpStatus: http.StatusInternalServerError,
+			id:         "run-3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			handler := TransactionHandler{TransactionUsecase: mockUsecase}
+			routeContext := chi.NewRouteContext()
+			routeContext.URLParams.Add("id", tt.id)
+			r := httptest.NewRequest(http.MethodGet, "/reconciliations/"+tt.id, nil)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeContext))
+			w := httptest.NewRecorder()
+			handler.HandleGetReconciliationRun(w, r)
+			assert.Equal(t, tt.httpStatus, w.Code)
+		})
+	}
+}
@@ -1,17 +1,34 @@
 package handlers
 
 import (
+	"amartha-test/dates"
 	"amartha-test/entities/transactions"
 	"amartha-test/entities/usecases"
 	libError "amartha-test/errors"
+	"amartha-test/money"
 	"amartha-test/response"
-	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// DefaultMaxUploadSize is the biggest request body accepted when TransactionHandler.MaxUploadSize is not set, big
+// enough for monthly files of a few million rows
+const DefaultMaxUploadSize = 1 << 30
+
 type TransactionHandler struct {
 	TransactionUsecase usecases.TransactionUsecase
+	JobUsecase         usecases.JobUsecase                  // runs the reconciliations sent with async=true
+	ColumnProfiles     map[string]transactions.ColumnLayout // saved column layouts by their name, chosen with the <file>_profile form field
+	MaxUploadSize      int64                                // biggest request body in bytes, DefaultMaxUploadSize when 0
+	TempDir            string                               // directory of the uploaded files, the default directory for temporary files when empty
 }
 
 func NewTransactionHandler(handler TransactionHandler) TransactionHandler {
@@ -19,51 +36,168 @@ func NewTransactionHandler(handler TransactionHandler) TransactionHandler {
 }
 
 func (handler TransactionHandler) HandleReconciliation(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	// the reconciliation stops when the client goes away, an asynchronous reconciliation runs on after the request
+	ctx := r.Context()
 
-	// to limit the file size to be no greater than 10 MB
-	err := r.ParseMultipartForm(10 << 20)
+	// the uploaded files are written to disk as they are received, only the other fields are kept in memory
+	maxUploadSize := handler.MaxUploadSize
+	if maxUploadSize <= 0 {
+		maxUploadSize = DefaultMaxUploadSize
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	files, err := readForm(r, handler.TempDir)
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		libError.SetPayloadTooLargeErrorForHandler(w, fmt.Sprintf("uploaded files can not be bigger than %d MB", maxUploadSize>>20))
+		return
+	}
 	if err != nil {
+		libError.SetBadRequestErrorForHandler(w, fmt.Sprintf("form is invalid, %s", err))
 		return
 	}
+	// the files of an asynchronous reconciliation are removed by its job
+	submitted := false
+	defer func() {
+		if !submitted {
+			files.close()
+		}
+	}()
 
-	// get file 1 from form
-	bankStatements, bankStatementsFileHeader, err := r.FormFile("bank_statements")
+	async, err := formValueBool(r, "async")
 	if err != nil {
-		if errors.Is(err, http.ErrMissingFile) {
-			libError.SetBadRequestErrorForHandler(w, "File is not found")
-			return
-		}
+		libError.SetError(w, err)
+		return
+	}
+	if async && handler.JobUsecase == nil {
+		libError.SetBadRequestErrorForHandler(w, "asynchronous reconciliation is not available")
+		return
+	}
+	callbackURL := r.FormValue("callback_url")
+	if callbackURL != "" && !async {
+		libError.SetBadRequestErrorForHandler(w, "callback_url can only be sent with async=true")
 		return
 	}
 
-	// get file 2 from form
-	systemTransactions, systemTransactionsFileHeader, err := r.FormFile("system_transactions")
+	// get every bank statements file from form
+	bankStatements, err := formBankStatements(files)
 	if err != nil {
-		if errors.Is(err, http.ErrMissingFile) {
-			libError.SetBadRequestErrorForHandler(w, "File is not found")
-			return
-		}
-		libError.SetInternalServerErrorForHandler(w, err)
+		libError.SetError(w, err)
 		return
 	}
 
-	// check if file is csv
-	if len(bankStatementsFileHeader.Header["Content-Type"]) > 0 && bankStatementsFileHeader.Header["Content-Type"][0] != "text/csv" {
-		libError.SetBadRequestErrorForHandler(w, "File Upload is not csv")
+	// get file 2 from form
+	systemTransactions, ok := files.first("system_transactions")
+	if !ok {
+		libError.SetBadRequestErrorForHandler(w, "File is not found")
+		return
+	}
+
+	// check if file is csv or xlsx
+	if systemTransactions.contentType != "" && systemTransactions.contentType != "text/csv" && systemTransactions.contentType != xlsxContentType {
+		libError.SetBadRequestErrorForHandler(w, "File Upload is not csv or xlsx")
 		return
 	}
 
+	// get optional exchange rates file from form
+	exchangeRates, _ := files.first("exchange_rates")
+
 	// check if file is csv
-	if len(systemTransactionsFileHeader.Header["Content-Type"]) > 0 && systemTransactionsFileHeader.Header["Content-Type"][0] != "text/csv" {
+	if exchangeRates.contentType != "" && exchangeRates.contentType != "text/csv" {
 		libError.SetBadRequestErrorForHandler(w, "File Upload is not csv")
 		return
 	}
 
-	result, err := handler.TransactionUsecase.DoReconciliation(ctx, transactions.DoReconciliationRequest{
-		SystemTransactions: systemTransactions,
-		BankStatements:     bankStatements,
-	})
+	dateToleranceDays, err := formValueInt(r, "date_tolerance_days")
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	amountTolerance, err := formValueMoney(r, "amount_tolerance")
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	amountTolerancePercent, err := formValueFloat(r, "amount_tolerance_percent")
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+	if amountTolerancePercent > 100 {
+		libError.SetBadRequestErrorForHandler(w, "amount_tolerance_percent can not be more than 100")
+		return
+	}
+
+	startDate, err := formValueDate(r, "start_date")
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	endDate, err := formValueDate(r, "end_date")
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	bankStatementsOptions, err := handler.formFileOptions(r, transactions.BankStatementsFile, transactions.BankStatementsColumns)
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	bankStatementsOptions.Format = strings.ToLower(r.FormValue("bank_statements_format"))
+
+	bankStatementsOptions.BankSourcePattern, err = formValueBankSourcePattern(r, "bank_source_pattern")
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	systemTransactionsOptions, err := handler.formFileOptions(r, transactions.SystemTransactionsFile, transactions.SystemTransactionsColumns)
+	if err != nil {
+		libError.SetError(w, err)
+		return
+	}
+
+	validationMode := transactions.ValidationMode(r.FormValue("validation_mode"))
+	if validationMode != "" && validationMode != transactions.ValidationStrict && validationMode != transactions.ValidationLenient {
+		libError.SetBadRequestErrorForHandler(w, fmt.Sprintf("validation_mode must be %s or %s", transactions.ValidationStrict, transactions.ValidationLenient))
+		return
+	}
+
+	param := transactions.DoReconciliationRequest{
+		SystemTransactions:     systemTransactions.file,
+		BankStatements:         bankStatements,
+		DateToleranceDays:      dateToleranceDays,
+		AmountTolerance:        amountTolerance,
+		AmountTolerancePercent: amountTolerancePercent,
+		StartDate:              startDate,
+		EndDate:                endDate,
+
+		BankStatementsOptions:     bankStatementsOptions,
+		SystemTransactionsOptions: systemTransactionsOptions,
+
+		ReportingCurrency: strings.ToUpper(r.FormValue("reporting_currency")),
+		ExchangeRates:     exchangeRates.file,
+
+		ValidationMode: validationMode,
+	}
+
+	if async {
+		job, err := handler.JobUsecase.SubmitReconciliation(ctx, param, callbackURL)
+		if err != nil {
+			libError.SetError(w, err)
+			return
+		}
+		submitted = true
+
+		response.SetAccepted(w, job)
+		return
+	}
+
+	result, err := handler.TransactionUsecase.DoReconciliation(ctx, param)
 	if err != nil {
 		libError.SetError(w, err)
 		return
@@ -72,3 +206,241 @@ func (handler TransactionHandler) HandleReconciliation(w http.ResponseWriter, r
 	response.SetOK(w, result)
 
 }
+
+// formBankStatements returns the bank statements files uploaded in repeated bank_statements fields or in
+// bank_statements[BANK] fields, the bank between the brackets is the bank source of every row of that file
+func formBankStatements(files uploads) (result []transactions.BankStatementsUpload, err error) {
+	// sort the fields so the files are always reconciled in the same order
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		bank := ""
+		if key != "bank_statements" {
+			if !strings.HasPrefix(key, "bank_statements[") || !strings.HasSuffix(key, "]") {
+				continue
+			}
+			bank = strings.ToUpper(strings.TrimSuffix(strings.TrimPrefix(key, "bank_statements["), "]"))
+		}
+
+		for _, file := range files[key] {
+			// check if file is csv, xml or text
+			if file.contentType != "" && !isBankStatementsContentType(file.contentType) {
+				return nil, libError.NewBadRequestError("File Upload is not csv, xlsx, xml or text")
+			}
+
+			result = append(result, transactions.BankStatementsUpload{
+				File: file.file,
+				Name: file.name,
+				Bank: bank,
+			})
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, libError.NewBadRequestError("File is not found")
+	}
+	return result, nil
+}
+
+// xlsxContentType is the content type of xlsx workbooks, they can be uploaded for both files
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// bankStatementsContentTypes are the content types of the csv and xlsx exports, camt xml and MT940 text statements of the banks
+var bankStatementsContentTypes = []string{"text/csv", xlsxContentType, "text/xml", "application/xml", "text/plain"}
+
+func isBankStatementsContentType(contentType string) bool {
+	for _, bankStatementsContentType := range bankStatementsContentTypes {
+		if contentType == bankStatementsContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// formValueInt reads an optional integer form field, an empty field is read as 0
+func formValueInt(r *http.Request, key string) (int, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, libError.NewBadRequestError(fmt.Sprintf("%s must be a number", key))
+	}
+
+	return result, nil
+}
+
+// formValueBool reads an optional true or false form field, an empty field is read as false
+func formValueBool(r *http.Request, key string) (bool, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return false, nil
+	}
+
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, libError.NewBadRequestError(fmt.Sprintf("%s must be true or false", key))
+	}
+
+	return result, nil
+}
+
+// formValueFloat reads an optional decimal form field, an empty field is read as 0. NaN and infinity are not
+// numbers of the form
+func formValueFloat(r *http.Request, key string) (float64, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, libError.NewBadRequestError(fmt.Sprintf("%s must be a number", key))
+	}
+
+	return result, nil
+}
+
+// formValueDate reads an optional date form field in transactions.DateRangeFormat, an empty field is read as zero time
+func formValueDate(r *http.Request, key string) (time.Time, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	result, err := time.ParseInLocation(transactions.DateRangeFormat, value, time.Local)
+	if err != nil {
+		return time.Time{}, libError.NewBadRequestError(fmt.Sprintf("%s must be a date with format %s", key, transactions.DateRangeFormat))
+	}
+
+	return result, nil
+}
+
+// formValueMoney reads an optional decimal amount form field without currency, an empty field is read as 0
+func formValueMoney(r *http.Request, key string) (money.Money, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return money.Money{}, nil
+	}
+
+	result, err := money.Parse(value, "")
+	if err != nil {
+		return money.Money{}, libError.NewBadRequestError(fmt.Sprintf("%s must be a number", key))
+	}
+
+	return result, nil
+}
+
+// formValueBankSourcePattern reads an optional regular expression form field that must capture the bank source in
+// the group named transactions.BankSourceGroup, an empty field is read as the default pattern
+func formValueBankSourcePattern(r *http.Request, key string) (*regexp.Regexp, error) {
+	value := r.FormValue(key)
+	if value == "" {
+		return transactions.DefaultBankSourcePattern, nil
+	}
+
+	result, err := regexp.Compile(value)
+	if err != nil {
+		return nil, libError.NewBadRequestError(fmt.Sprintf("%s must be a valid regular expression, %s", key, err))
+	}
+	if result.SubexpIndex(transactions.BankSourceGroup) < 0 {
+		return nil, libError.NewBadRequestError(fmt.Sprintf("%s must have a group named %s, for example (?P<%s>[A-Z]+)", key, transactions.BankSourceGroup, transactions.BankSourceGroup))
+	}
+
+	return result, nil
+}
+
+// formFileOptions reads the options of an uploaded file from the form fields prefixed with the name of its file field,
+// headers are the default headers of the columns of the file
+func (handler TransactionHandler) formFileOptions(r *http.Request, file string, headers []string) (transactions.FileOptions, error) {
+	options := transactions.FileOptions{
+		NumberFormat: money.NumberFormat(r.FormValue(file + "_number_format")),
+		Currency:     strings.ToUpper(r.FormValue(file + "_currency")),
+		Sheet:        r.FormValue(file + "_sheet"),
+	}
+
+	if options.NumberFormat != "" && !options.NumberFormat.IsValid() {
+		return options, libError.NewBadRequestError(fmt.Sprintf("%s_number_format must be one of %s, %s or %s", file, money.FormatEnglish, money.FormatIndonesian, money.FormatAuto))
+	}
+
+	options.DateFormat = dates.Format(r.FormValue(file + "_date_format"))
+	if options.DateFormat != "" && !options.DateFormat.IsValid() {
+		return options, libError.NewBadRequestError(fmt.Sprintf("%s_date_format must be a layout like 02/01/2006 15:04:05, or one of %s, %s, %s or %s", file, dates.FormatISO8601, dates.FormatUnix, dates.FormatUnixMilli, dates.FormatAuto))
+	}
+
+	if value := r.FormValue(file + "_time_zone"); value != "" {
+		location, err := time.LoadLocation(value)
+		if err != nil {
+			return options, libError.NewBadRequestError(fmt.Sprintf("%s_time_zone must be a time zone like Asia/Jakarta or UTC", file))
+		}
+		options.Location = location
+	}
+
+	headerRow, err := formValueInt(r, file+"_header_row")
+	if err != nil {
+		return options, err
+	}
+	if headerRow < 0 {
+		return options, libError.NewBadRequestError(fmt.Sprintf("%s_header_row can not be negative", file))
+	}
+	options.HeaderRow = headerRow
+
+	options.Layout, err = handler.formColumnLayout(r, file, headers)
+	if err != nil {
+		return options, err
+	}
+
+	return options.WithDefaults(), nil
+}
+
+// formColumnLayout reads the column layout of an uploaded file, the saved profile named in the <file>_profile field
+// is the starting point and the <file>_columns, <file>_headerless, <file>_delimiter and <file>_quote fields replace
+// its values. <file>_columns is a JSON object of the file headers by their default header, like {"amount":"Nominal"}
+func (handler TransactionHandler) formColumnLayout(r *http.Request, file string, headers []string) (layout transactions.ColumnLayout, err error) {
+	if name := r.FormValue(file + "_profile"); name != "" {
+		profile, ok := handler.ColumnProfiles[name]
+		if !ok {
+			return layout, libError.NewBadRequestError(fmt.Sprintf("%s_profile %s is not found", file, name))
+		}
+		layout = profile
+	}
+
+	if value := r.FormValue(file + "_columns"); value != "" {
+		layout.Columns = nil
+		err = json.Unmarshal([]byte(value), &layout.Columns)
+		if err != nil {
+			return layout, libError.NewBadRequestError(fmt.Sprintf("%s_columns must be a JSON object of headers by column, like {\"amount\":\"Nominal\"}", file))
+		}
+	}
+
+	if value := r.FormValue(file + "_headerless"); value != "" {
+		layout.Headerless, err = strconv.ParseBool(value)
+		if err != nil {
+			return layout, libError.NewBadRequestError(fmt.Sprintf("%s_headerless must be true or false", file))
+		}
+	}
+
+	if value := r.FormValue(file + "_delimiter"); value != "" {
+		// a tab is hard to type in a form field
+		if value == "tab" || value == `\t` {
+			value = "\t"
+		}
+		layout.Delimiter = value
+	}
+
+	if value := r.FormValue(file + "_quote"); value != "" {
+		layout.Quote = value
+	}
+
+	err = layout.Validate(headers)
+	if err != nil {
+		return layout, libError.NewBadRequestError(fmt.Sprintf("column layout of %s is invalid, %s", file, err))
+	}
+	return layout, nil
+}
@@ -2,13 +2,18 @@ package handlers
 
 import (
 	"amartha-test/entities/transactions"
+	"amartha-test/entities/usecases"
 	usecaseMock "amartha-test/entities/usecases/mock"
+	libError "amartha-test/errors"
+	"amartha-test/money"
 	"bytes"
+	"context"
 	"errors"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/textproto"
+	"os"
 	"reflect"
 	"testing"
 
@@ -53,27 +58,548 @@ func TestTransactionHandler_HandleReconciliation(t *testing.T) {
 
 	// Mock TransactionUsecase
 	mockUsecase := usecaseMock.NewMockTransactionUsecase(ctrl)
+	// Mock JobUsecase
+	mockJobUsecase := usecaseMock.NewMockJobUsecase(ctrl)
+
+	// generateUploadsWithFields returns the generator of a form with a bank statement, a system transaction and the
+	// fields written as name and value pairs
+	generateUploadsWithFields := func(fields ...string) func() (bytes.Buffer, string) {
+		return func() (bytes.Buffer, string) {
+			var buf bytes.Buffer
+			writer := multipart.NewWriter(&buf)
+
+			bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+				"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+				"Content-Type":        []string{"text/csv"},
+			})
+			if err != nil {
+				t.Errorf("error in creating bank_statements data")
+			}
+			bankStatements.Write([]byte("unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024"))
+
+			systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+				"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+				"Content-Type":        []string{"text/csv"},
+			})
+			if err != nil {
+				t.Errorf("error in creating system_transactions data")
+			}
+			systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+			for index := 0; index+1 < len(fields); index += 2 {
+				err = writer.WriteField(fields[index], fields[index+1])
+				if err != nil {
+					t.Errorf("error in creating %s data", fields[index])
+				}
+			}
+
+			err = writer.Close()
+			if err != nil {
+				t.Errorf("error in writing data")
+			}
+
+			return buf, writer.FormDataContentType()
+		}
+	}
+	// generateUploads returns a form with a bank statement and a system transaction
+	generateUploads := generateUploadsWithFields()
 
 	tests := []struct {
-		name         string
-		mock         func()
-		httpStatus   int
-		r            *http.Request
-		generateData func() (data bytes.Buffer, contentType string)
+		name          string
+		mock          func()
+		httpStatus    int
+		r             *http.Request
+		query         string
+		jobUsecase    usecases.JobUsecase
+		maxUploadSize int64
+		wantUploads   int // uploaded files left for the job when the reconciliation runs in background
+		generateData  func() (data bytes.Buffer, contentType string)
 	}{
 		{
-			name: "Succesful",
-			mock: func() {
-				mockUsecase.EXPECT().DoReconciliation(gomock.Any(), gomock.Any()).
-					Return(transactions.DoReconciliationResponse{}, nil)
+			name: "Succesful in background",
+			mock: func() {
+				mockJobUsecase.EXPECT().SubmitReconciliation(gomock.Any(), gomock.Any(), "").
+					Return(transactions.ReconciliationJob{ID: "job-1", Status: transactions.JobQueued}, nil)
+			},
+			httpStatus:   http.StatusAccepted,
+			query:        "?async=true",
+			jobUsecase:   mockJobUsecase,
+			wantUploads:  2,
+			generateData: generateUploads,
+		},
+		{
+			name: "Succesful in background with callback",
+			mock: func() {
+				mockJobUsecase.EXPECT().SubmitReconciliation(gomock.Any(), gomock.Any(), "https://ledger.example.com/reconciliations").
+					Return(transactions.ReconciliationJob{ID: "job-1", Status: transactions.JobQueued}, nil)
+			},
+			httpStatus:   http.StatusAccepted,
+			query:        "?async=true&callback_url=https://ledger.example.com/reconciliations",
+			jobUsecase:   mockJobUsecase,
+			wantUploads:  2,
+			generateData: generateUploads,
+		},
+		{
+			name:         "callback_url is sent without async",
+			mock:         func() {},
+			httpStatus:   http.StatusBadRequest,
+			query:        "?callback_url=https://ledger.example.com/reconciliations",
+			jobUsecase:   mockJobUsecase,
+			generateData: generateUploads,
+		},
+		{
+			name: "Failed with too many jobs in background",
+			mock: func() {
+				mockJobUsecase.EXPECT().SubmitReconciliation(gomock.Any(), gomock.Any(), "").
+					Return(transactions.ReconciliationJob{}, libError.NewServiceUnavailableError("too many reconciliation jobs are queued, try again later"))
+			},
+			httpStatus:   http.StatusServiceUnavailable,
+			query:        "?async=true",
+			jobUsecase:   mockJobUsecase,
+			generateData: generateUploads,
+		},
+		{
+			name:         "Failed in background without jobs",
+			mock:         func() {},
+			httpStatus:   http.StatusBadRequest,
+			query:        "?async=true",
+			generateData: generateUploads,
+		},
+		{
+			name:         "async is not a boolean",
+			mock:         func() {},
+			httpStatus:   http.StatusBadRequest,
+			query:        "?async=later",
+			jobUsecase:   mockJobUsecase,
+			generateData: generateUploads,
+		},
+		{
+			name: "Succesful",
+			mock: func() {
+				mockUsecase.EXPECT().DoReconciliation(gomock.Any(), gomock.Any()).
+					Return(transactions.DoReconciliationResponse{}, nil)
+
+			},
+			httpStatus: http.StatusOK,
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				// Create form file fields
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte("unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024"))
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+			},
+		},
+		{
+			name: "Failed",
+			mock: func() {
+				mockUsecase.EXPECT().DoReconciliation(gomock.Any(), gomock.Any()).
+					Return(transactions.DoReconciliationResponse{}, errMock)
+			},
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte("unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024"))
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+			},
+			httpStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "Succesful with camt statement",
+			mock: func() {
+				mockUsecase.EXPECT().DoReconciliation(gomock.Any(), gomock.Any()).
+					Return(transactions.DoReconciliationResponse{}, nil)
+			},
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.xml"`},
+					"Content-Type":        []string{"application/xml"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte(`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"></Document>`))
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+			},
+			httpStatus: http.StatusOK,
+		},
+		{
+			name: "Succesful with multiple bank statements files",
+			mock: func() {
+				mockUsecase.EXPECT().DoReconciliation(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, param transactions.DoReconciliationRequest) (transactions.DoReconciliationResponse, error) {
+						banks := []string{}
+						for _, bankStatements := range param.BankStatements {
+							banks = append(banks, bankStatements.Bank)
+						}
+						assert.Equal(t, []string{"", "", "BCA", "BRI"}, banks)
+						return transactions.DoReconciliationResponse{}, nil
+					})
+			},
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				for _, field := range []string{"bank_statements[BCA]", "bank_statements[BRI]", "bank_statements", "bank_statements"} {
+					bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+						"Content-Disposition": []string{`form-data; name="` + field + `"; filename="bank.csv"`},
+						"Content-Type":        []string{"text/csv"},
+					})
+					if err != nil {
+						t.Errorf("error in creating bank_statements data")
+					}
+					bankStatements.Write([]byte("unique_identifier,amount,date\n12345,\"Rp1,500,000\",01/01/2024"))
+				}
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+			},
+			httpStatus: http.StatusOK,
+		},
+		{
+			name: "Failed with invalid rows",
+			mock: func() {
+				mockUsecase.EXPECT().DoReconciliation(gomock.Any(), gomock.Any()).
+					Return(transactions.DoReconciliationResponse{}, libError.NewValidationError("uploaded data has 1 invalid values", []transactions.RowError{
+						{File: transactions.BankStatementsFile, Line: 2, Column: "amount", Value: "abc", Reason: "amount format is invalid"},
+					}))
+			},
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte("unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024"))
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+			},
+			httpStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "bank_source_pattern has no bank group",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte("unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024"))
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.WriteField("bank_source_pattern", `^(?P<source>[A-Z]+)_\d+$`)
+				if err != nil {
+					t.Errorf("error in creating bank_source_pattern data")
+				}
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+			},
+		},
+		{
+			name: "Succesful with column layout",
+			mock: func() {
+				mockUsecase.EXPECT().DoReconciliation(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, param transactions.DoReconciliationRequest) (transactions.DoReconciliationResponse, error) {
+						assert.Equal(t, transactions.ColumnLayout{
+							Columns:   map[string]string{"unique_identifier": "ID", "amount": "Nominal"},
+							Delimiter: ";",
+							Quote:     "'",
+						}, param.BankStatementsOptions.Layout)
+						assert.Equal(t, transactions.ColumnLayout{Delimiter: "\t"}, param.SystemTransactionsOptions.Layout)
+						return transactions.DoReconciliationResponse{}, nil
+					})
+			},
+			httpStatus: http.StatusOK,
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte("ID;Nominal;date\nBCA_12345;Rp1.500.000;01/01/2024"))
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.WriteField("bank_statements_profile", `semicolon`)
+				if err != nil {
+					t.Errorf("error in creating bank_statements_profile data")
+				}
+
+				err = writer.WriteField("bank_statements_columns", `{"unique_identifier":"ID","amount":"Nominal"}`)
+				if err != nil {
+					t.Errorf("error in creating bank_statements_columns data")
+				}
+
+				err = writer.WriteField("bank_statements_quote", `'`)
+				if err != nil {
+					t.Errorf("error in creating bank_statements_quote data")
+				}
+
+				err = writer.WriteField("system_transactions_delimiter", `tab`)
+				if err != nil {
+					t.Errorf("error in creating system_transactions_delimiter data")
+				}
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+			},
+		},
+		{
+			name:       "bank_statements_profile is not found",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte("unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024"))
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.WriteField("bank_statements_profile", `comma`)
+				if err != nil {
+					t.Errorf("error in creating bank_statements_profile data")
+				}
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+			},
+		},
+		{
+			name:       "system_transactions_columns has unknown column",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte("unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024"))
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.WriteField("system_transactions_columns", `{"date":"Tanggal"}`)
+				if err != nil {
+					t.Errorf("error in creating system_transactions_columns data")
+				}
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+			},
+		},
+		{
+			name:       "bank_statements_columns is not a JSON object",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte("unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024"))
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.WriteField("bank_statements_columns", `amount=Nominal`)
+				if err != nil {
+					t.Errorf("error in creating bank_statements_columns data")
+				}
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
 
+				return buf, writer.FormDataContentType()
 			},
-			httpStatus: http.StatusOK,
+		},
+		{
+			name:       "bank_statements_date_format is unknown",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
 			generateData: func() (bytes.Buffer, string) {
 				var buf bytes.Buffer
 				writer := multipart.NewWriter(&buf)
 
-				// Create form file fields
 				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
 					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
 					"Content-Type":        []string{"text/csv"},
@@ -92,6 +618,11 @@ func TestTransactionHandler_HandleReconciliation(t *testing.T) {
 				}
 				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
 
+				err = writer.WriteField("bank_statements_date_format", `dd/mm/yyyy`)
+				if err != nil {
+					t.Errorf("error in creating bank_statements_date_format data")
+				}
+
 				err = writer.Close()
 				if err != nil {
 					t.Errorf("error in writing data")
@@ -101,11 +632,163 @@ func TestTransactionHandler_HandleReconciliation(t *testing.T) {
 			},
 		},
 		{
-			name: "Failed",
+			name:       "system_transactions_time_zone is unknown",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte("unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024"))
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.WriteField("system_transactions_time_zone", `Asia/Bandung`)
+				if err != nil {
+					t.Errorf("error in creating system_transactions_time_zone data")
+				}
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+			},
+		},
+		{
+			name:       "validation_mode is unknown",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte("unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024"))
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.WriteField("validation_mode", "skip")
+				if err != nil {
+					t.Errorf("error in creating validation_mode data")
+				}
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+			},
+		},
+		{
+			name:       "date_tolerance_days is not a number",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte("unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024"))
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.WriteField("date_tolerance_days", "two")
+				if err != nil {
+					t.Errorf("error in creating date_tolerance_days data")
+				}
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+			},
+		},
+		{
+			name: "Succesful with amount tolerance",
 			mock: func() {
 				mockUsecase.EXPECT().DoReconciliation(gomock.Any(), gomock.Any()).
-					Return(transactions.DoReconciliationResponse{}, errMock)
+					DoAndReturn(func(_ context.Context, param transactions.DoReconciliationRequest) (transactions.DoReconciliationResponse, error) {
+						assert.Equal(t, money.MustParse("1000.50", ""), param.AmountTolerance)
+						assert.Equal(t, 2.5, param.AmountTolerancePercent)
+						return transactions.DoReconciliationResponse{}, nil
+					})
 			},
+			httpStatus:   http.StatusOK,
+			generateData: generateUploadsWithFields("amount_tolerance", "1000.50", "amount_tolerance_percent", "2.5"),
+		},
+		{
+			name:         "amount_tolerance_percent is NaN",
+			mock:         func() {},
+			httpStatus:   http.StatusBadRequest,
+			generateData: generateUploadsWithFields("amount_tolerance_percent", "NaN"),
+		},
+		{
+			name:         "amount_tolerance_percent is infinite",
+			mock:         func() {},
+			httpStatus:   http.StatusBadRequest,
+			generateData: generateUploadsWithFields("amount_tolerance_percent", "1e400"),
+		},
+		{
+			name:         "amount_tolerance_percent is more than 100",
+			mock:         func() {},
+			httpStatus:   http.StatusBadRequest,
+			generateData: generateUploadsWithFields("amount_tolerance_percent", "1e300"),
+		},
+		{
+			name:         "amount_tolerance is not a number",
+			mock:         func() {},
+			httpStatus:   http.StatusBadRequest,
+			generateData: generateUploadsWithFields("amount_tolerance", "Inf"),
+		},
+		{
+			name:       "bank_statements_number_format is unknown",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
 			generateData: func() (bytes.Buffer, string) {
 				var buf bytes.Buffer
 				writer := multipart.NewWriter(&buf)
@@ -128,6 +811,50 @@ func TestTransactionHandler_HandleReconciliation(t *testing.T) {
 				}
 				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
 
+				err = writer.WriteField("bank_statements_number_format", "fr")
+				if err != nil {
+					t.Errorf("error in creating bank_statements_number_format data")
+				}
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+			},
+		},
+		{
+			name:       "start_date is not a date",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte("unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024"))
+
+				systemTransactions, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="system_transactions"; filename="system.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating system_transactions data")
+				}
+				systemTransactions.Write([]byte("unique_identifier,amount,date\nSYS_12345,\"Rp1,500,000\",01/01/2024"))
+
+				err = writer.WriteField("start_date", "01/01/2024")
+				if err != nil {
+					t.Errorf("error in creating start_date data")
+				}
+
 				err = writer.Close()
 				if err != nil {
 					t.Errorf("error in writing data")
@@ -135,7 +862,6 @@ func TestTransactionHandler_HandleReconciliation(t *testing.T) {
 
 				return buf, writer.FormDataContentType()
 			},
-			httpStatus: http.StatusInternalServerError,
 		},
 		{
 			name:       "bank_statements file is not csv",
@@ -147,7 +873,7 @@ func TestTransactionHandler_HandleReconciliation(t *testing.T) {
 
 				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
 					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.txt"`},
-					"Content-Type":        []string{"text/plain"},
+					"Content-Type":        []string{"application/pdf"},
 				})
 				if err != nil {
 					t.Errorf("error in creating bank_statements data")
@@ -250,22 +976,73 @@ func TestTransactionHandler_HandleReconciliation(t *testing.T) {
 
 			},
 		},
+		{
+			name:          "Uploaded files are too big",
+			mock:          func() {},
+			httpStatus:    http.StatusRequestEntityTooLarge,
+			maxUploadSize: 1 << 20,
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+
+				bankStatements, err := writer.CreatePart(textproto.MIMEHeader{
+					"Content-Disposition": []string{`form-data; name="bank_statements"; filename="bank.csv"`},
+					"Content-Type":        []string{"text/csv"},
+				})
+				if err != nil {
+					t.Errorf("error in creating bank_statements data")
+				}
+				bankStatements.Write([]byte("unique_identifier,amount,date\n"))
+				bankStatements.Write(bytes.Repeat([]byte("BCA_12345,\"Rp1,500,000\",01/01/2024\n"), 1<<20/32))
+
+				err = writer.Close()
+				if err != nil {
+					t.Errorf("error in writing data")
+				}
+
+				return buf, writer.FormDataContentType()
+
+			},
+		},
+		{
+			name:       "Form is not multipart",
+			mock:       func() {},
+			httpStatus: http.StatusBadRequest,
+			generateData: func() (bytes.Buffer, string) {
+				var buf bytes.Buffer
+				buf.WriteString("unique_identifier,amount,date")
+
+				return buf, "text/csv"
+
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
 			data, contentType := tt.generateData()
 
-			r := httptest.NewRequest(http.MethodPost, "/reconciliation", &data)
+			r := httptest.NewRequest(http.MethodPost, "/reconciliation"+tt.query, &data)
 			r.Header.Set("Content-Type", contentType)
 
 			w := httptest.NewRecorder()
+			tempDir := t.TempDir()
 			handler := TransactionHandler{
 				TransactionUsecase: mockUsecase,
+				JobUsecase:         tt.jobUsecase,
+				ColumnProfiles: map[string]transactions.ColumnLayout{
+					"semicolon": {Columns: map[string]string{"date": "Tanggal"}, Delimiter: ";"},
+				},
+				MaxUploadSize: tt.maxUploadSize,
+				TempDir:       tempDir,
 			}
 			tt.mock()
 			handler.HandleReconciliation(w, r)
 			assert.Equal(t, tt.httpStatus, w.Code)
+
+			// the uploaded files are removed with the request unless a job takes them
+			uploads, _ := os.ReadDir(tempDir)
+			assert.Equal(t, tt.wantUploads, len(uploads))
 		})
 	}
 }
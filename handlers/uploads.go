@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// maxFormValuesSize is the biggest size of the form fields that are not files, they are kept in memory
+const maxFormValuesSize = 10 << 20
+
+// uploadFile is an uploaded file written to disk, it is removed when it is closed
+type uploadFile struct {
+	*os.File
+}
+
+func (f uploadFile) Close() error {
+	return errors.Join(f.File.Close(), os.Remove(f.File.Name()))
+}
+
+// upload is a file of the form with the name and content type it was sent with
+type upload struct {
+	file        multipart.File
+	name        string
+	contentType string
+}
+
+// uploads are the files of the form by their field name
+type uploads map[string][]upload
+
+// first returns the first file of the field, ok is false when the field has no file
+func (u uploads) first(key string) (upload, bool) {
+	if len(u[key]) == 0 {
+		return upload{}, false
+	}
+	return u[key][0], true
+}
+
+// close removes every file of the form
+func (u uploads) close() {
+	for _, files := range u {
+		for _, file := range files {
+			file.file.Close()
+		}
+	}
+}
+
+// readForm reads the multipart form of the request one part at a time. Every file is copied once from the request
+// to a temporary file in dir, the other fields are kept in r.Form so they are read with r.FormValue
+func readForm(r *http.Request, dir string) (_ uploads, err error) {
+	// the query is parsed before the body is read, a multipart body is left to the multipart reader
+	err = r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	result := uploads{}
+	defer func() {
+		if err != nil {
+			result.close()
+		}
+	}()
+
+	values := url.Values{}
+	valuesSize := int64(0)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		key := part.FormName()
+		if key == "" {
+			continue
+		}
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, maxFormValuesSize-valuesSize+1))
+			if err != nil {
+				return nil, err
+			}
+			valuesSize += int64(len(value))
+			if valuesSize > maxFormValuesSize {
+				return nil, fmt.Errorf("fields that are not files can not be bigger than %d MB", maxFormValuesSize>>20)
+			}
+			values.Add(key, string(value))
+			continue
+		}
+
+		file, err := os.CreateTemp(dir, "upload-*")
+		if err != nil {
+			return nil, err
+		}
+		copied := uploadFile{file}
+		result[key] = append(result[key], upload{file: copied, name: part.FileName(), contentType: part.Header.Get("Content-Type")})
+
+		_, err = io.Copy(file, part)
+		if err != nil {
+			return nil, err
+		}
+		_, err = file.Seek(0, io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the fields of the body come before the ones of the query like with r.ParseMultipartForm
+	for key, value := range values {
+		r.PostForm[key] = value
+		r.Form[key] = append(append([]string(nil), value...), r.Form[key]...)
+	}
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	// maxUploadSizeEnv is the environment variable with the biggest request body accepted in MB
+	maxUploadSizeEnv = "MAX_UPLOAD_SIZE_MB"
+	// sortBufferSizeEnv is the environment variable with the number of records of a file sorted in memory, bigger
+	// files are sorted in runs written to the directory for temporary files, TMPDIR on Unix
+	sortBufferSizeEnv = "SORT_BUFFER_SIZE"
+	// jobWorkersEnv is the environment variable with the number of asynchronous reconciliations run at the same time
+	jobWorkersEnv = "JOB_WORKERS"
+	// jobQueueSizeEnv is the environment variable with the number of asynchronous reconciliations that can wait for
+	// a worker, more are rejected with 503
+	jobQueueSizeEnv = "JOB_QUEUE_SIZE"
+	// callbackAttemptsEnv is the environment variable with the number of attempts to deliver the callback of an
+	// asynchronous reconciliation
+	callbackAttemptsEnv = "CALLBACK_ATTEMPTS"
+)
+
+// loadPositiveInt reads a positive number from the environment variable, it returns 0 when the variable is not set
+func loadPositiveInt(name string) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number", name)
+	}
+	return number, nil
+}
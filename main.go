@@ -3,29 +3,96 @@ package main
 import (
 	httphandlers "amartha-test/entities/http_handlers"
 	"amartha-test/handlers"
+	"amartha-test/parsers"
 	usecase "amartha-test/usecases"
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/go-chi/chi"
 )
 
+// callbackSecretEnv is the environment variable with the key the callbacks of asynchronous reconciliations are signed
+// with, callback_url is rejected when it is not set
+const callbackSecretEnv = "CALLBACK_SECRET"
+
 func getRoutes(modules module) *chi.Mux {
 	router := chi.NewRouter()
 
 	router.Route("/", func(path chi.Router) {
 		path.Post("/reconciliation", modules.httpHandler.TransactionHandler.HandleReconciliation)
+		path.Get("/reconciliations", modules.httpHandler.TransactionHandler.HandleGetReconciliationRuns)
+		path.Get("/reconciliations/{id}", modules.httpHandler.TransactionHandler.HandleGetReconciliationRun)
+		path.Get("/jobs/{id}", modules.httpHandler.TransactionHandler.HandleGetJob)
 	})
 
 	return router
 }
 
 func main() {
+	// the running jobs are canceled when the server is stopped
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	columnProfiles, err := loadColumnProfiles(os.Getenv(columnProfilesEnv))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	maxUploadSize, err := loadPositiveInt(maxUploadSizeEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sortBufferSize, err := loadPositiveInt(sortBufferSizeEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	jobWorkers, err := loadPositiveInt(jobWorkersEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	jobQueueSize, err := loadPositiveInt(jobQueueSizeEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	callbackAttempts, err := loadPositiveInt(callbackAttemptsEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	transactionsUsecase := usecase.NewTransactionUsecase(usecase.TransactionUsecase{})
+	reconciliationRepository, err := loadReconciliationRepository(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	transactionsUsecase := usecase.NewTransactionUsecase(usecase.TransactionUsecase{
+		BankStatementsParsers:    parsers.BankStatementsParsers(),
+		SortBufferSize:           sortBufferSize,
+		ReconciliationRepository: reconciliationRepository,
+	})
+
+	jobUsecase := usecase.NewJobUsecase(usecase.JobUsecase{
+		TransactionUsecase: transactionsUsecase,
+		Workers:            jobWorkers,
+		QueueSize:          jobQueueSize,
+		CallbackSecret:     os.Getenv(callbackSecretEnv),
+		CallbackAttempts:   callbackAttempts,
+	})
+	jobUsecase.Start(ctx)
 
 	transactionsHandler := handlers.NewTransactionHandler(handlers.TransactionHandler{
 		TransactionUsecase: transactionsUsecase,
+		JobUsecase:         jobUsecase,
+		ColumnProfiles:     columnProfiles,
+		MaxUploadSize:      int64(maxUploadSize) << 20,
 	})
 
 	modules := loadModules(httphandlers.Handlers{
@@ -34,5 +101,17 @@ func main() {
 
     router := getRoutes(modules)
 
-	log.Fatal(http.ListenAndServe(":8000", router))
+	server := &http.Server{Addr: ":8000", Handler: router}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	err = server.ListenAndServe()
+	if !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+
+	// wait for the canceled jobs to store their runs, remove their files and send their callbacks
+	jobUsecase.Wait()
 }
\ No newline at end of file
@@ -0,0 +1,132 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// MaxRateAge is how long an exchange rate applies after its date when no newer rate of the pair is given, it covers
+// the weekends and holidays without rates but a rate older than that is not used
+const MaxRateAge = 7 * 24 * time.Hour
+
+// ErrNoExchangeRate is returned when there is no rate of the pair for the date
+var ErrNoExchangeRate = errors.New("no exchange rate")
+
+// ExchangeRates holds the exchange rates between pairs of currencies, each rate applies from its date
+// until the date of the next rate of the same pair, for at most MaxRateAge
+type ExchangeRates struct {
+	rates map[currencyPair][]exchangeRate
+}
+
+type currencyPair struct {
+	from string
+	to   string
+}
+
+type exchangeRate struct {
+	date time.Time
+	rate *big.Rat
+}
+
+func NewExchangeRates() *ExchangeRates {
+	return &ExchangeRates{
+		rates: map[currencyPair][]exchangeRate{},
+	}
+}
+
+// Add registers that one unit of currency from is worth rate units of currency to starting from the date,
+// the rate is a decimal string like "15600.50"
+func (e *ExchangeRates) Add(date time.Time, from, to string, rate string) error {
+	value, ok := new(big.Rat).SetString(rate)
+	if !ok || value.Sign() <= 0 {
+		return fmt.Errorf("invalid exchange rate %q", rate)
+	}
+
+	// keep the rates of each pair sorted by date so the latest one can be found with a binary search
+	pair := currencyPair{from: from, to: to}
+	rates := e.rates[pair]
+	index := sort.Search(len(rates), func(i int) bool {
+		return !rates[i].date.Before(date)
+	})
+	if index < len(rates) && rates[index].date.Equal(date) {
+		return fmt.Errorf("exchange rate from %s to %s on %s is duplicated", from, to, date.Format(time.DateOnly))
+	}
+
+	rates = append(rates, exchangeRate{})
+	copy(rates[index+1:], rates[index:])
+	rates[index] = exchangeRate{date: date, rate: value}
+	e.rates[pair] = rates
+
+	return nil
+}
+
+// rate returns the latest rate of the pair on or before the date and at most MaxRateAge old, the inverse of the opposite pair is used
+// when the pair itself is unknown
+func (e *ExchangeRates) rate(from, to string, date time.Time) (*big.Rat, bool) {
+	if rate, ok := latestRate(e.rates[currencyPair{from: from, to: to}], date); ok {
+		return rate, true
+	}
+	if rate, ok := latestRate(e.rates[currencyPair{from: to, to: from}], date); ok {
+		return new(big.Rat).Inv(rate), true
+	}
+	return nil, false
+}
+
+func latestRate(rates []exchangeRate, date time.Time) (*big.Rat, bool) {
+	index := sort.Search(len(rates), func(i int) bool {
+		return rates[i].date.After(date)
+	})
+	if index == 0 || date.Sub(rates[index-1].date) > MaxRateAge {
+		return nil, false
+	}
+	return rates[index-1].rate, true
+}
+
+// Convert exchanges the money to another currency with the rate of the date, the result is rounded half
+// away from zero to the minor unit of the new currency
+func (e *ExchangeRates) Convert(m Money, to string, date time.Time) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+
+	rate, ok := e.rate(m.Currency, to, date)
+	if !ok {
+		return Money{}, fmt.Errorf("%w from %s to %s on %s or up to %d days before", ErrNoExchangeRate, m.Currency, to, date.Format(time.DateOnly), MaxRateAge/(24*time.Hour))
+	}
+
+	// amount in minor unit of the new currency = amount * rate * 10^(minor unit of to - minor unit of from)
+	amount := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), rate)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(MinorUnit(to)-MinorUnit(m.Currency)))), nil))
+	if MinorUnit(to) >= MinorUnit(m.Currency) {
+		amount.Mul(amount, scale)
+	} else {
+		amount.Quo(amount, scale)
+	}
+
+	return New(roundRat(amount), to), nil
+}
+
+// roundRat rounds the number half away from zero
+func roundRat(number *big.Rat) int64 {
+	numerator, denominator := new(big.Int).Abs(number.Num()), number.Denom()
+
+	// (2 * numerator + denominator) / (2 * denominator) rounds half up for positive numbers
+	rounded := new(big.Int).Mul(numerator, big.NewInt(2))
+	rounded.Add(rounded, denominator)
+	rounded.Quo(rounded, new(big.Int).Mul(denominator, big.NewInt(2)))
+
+	if number.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+	return rounded.Int64()
+}
+
+func abs(number int) int {
+	if number < 0 {
+		return -number
+	}
+	return number
+}
@@ -0,0 +1,153 @@
+package money
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExchangeRates_Convert(t *testing.T) {
+	rates := NewExchangeRates()
+	for _, rate := range []struct {
+		date     time.Time
+		from, to string
+		rate     string
+	}{
+		{time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, time.Local), "USD", "IDR", "15500"},
+		{time.Date(2024, time.Month(1), 3, 0, 0, 0, 0, time.Local), "USD", "IDR", "15600.5"},
+		{time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, time.Local), "IDR", "JPY", "0.0095"},
+	} {
+		if err := rates.Add(rate.date, rate.from, rate.to, rate.rate); err != nil {
+			t.Fatalf("ExchangeRates.Add() error = %v", err)
+		}
+	}
+
+	type args struct {
+		money Money
+		to    string
+		date  time.Time
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Money
+		wantErr bool
+	}{
+		{
+			name: "Succesful",
+			args: args{
+				money: MustParse("100.10", "USD"),
+				to:    "IDR",
+				date:  time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, time.Local),
+			},
+			want:    MustParse("1551550", "IDR"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful with latest rate",
+			args: args{
+				money: MustParse("-0.01", "USD"),
+				to:    "IDR",
+				date:  time.Date(2024, time.Month(1), 5, 0, 0, 0, 0, time.Local),
+			},
+			want:    MustParse("-156.01", "IDR"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful with inverse rate",
+			args: args{
+				money: MustParse("31001", "IDR"),
+				to:    "USD",
+				date:  time.Date(2024, time.Month(1), 3, 0, 0, 0, 0, time.Local),
+			},
+			want:    MustParse("1.99", "USD"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful to currency without minor unit",
+			args: args{
+				money: MustParse("1500000", "IDR"),
+				to:    "JPY",
+				date:  time.Date(2024, time.Month(1), 3, 0, 0, 0, 0, time.Local),
+			},
+			want:    MustParse("14250", "JPY"),
+			wantErr: false,
+		},
+		{
+			name: "Same currency",
+			args: args{
+				money: MustParse("1500000", "IDR"),
+				to:    "IDR",
+			},
+			want:    MustParse("1500000", "IDR"),
+			wantErr: false,
+		},
+		{
+			name: "No rate before the date",
+			args: args{
+				money: MustParse("100", "USD"),
+				to:    "IDR",
+				date:  time.Date(2023, time.Month(12), 31, 0, 0, 0, 0, time.Local),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Succesful with rate of the maximum age",
+			args: args{
+				money: MustParse("1", "USD"),
+				to:    "IDR",
+				date:  time.Date(2024, time.Month(1), 10, 0, 0, 0, 0, time.Local),
+			},
+			want:    MustParse("15600.50", "IDR"),
+			wantErr: false,
+		},
+		{
+			name: "Rate is too old",
+			args: args{
+				money: MustParse("1", "USD"),
+				to:    "IDR",
+				date:  time.Date(2024, time.Month(1), 10, 0, 0, 1, 0, time.Local),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown pair",
+			args: args{
+				money: MustParse("100", "EUR"),
+				to:    "IDR",
+				date:  time.Date(2024, time.Month(1), 3, 0, 0, 0, 0, time.Local),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rates.Convert(tt.args.money, tt.args.to, tt.args.date)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExchangeRates.Convert() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExchangeRates.Convert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExchangeRates_Add(t *testing.T) {
+	rates := NewExchangeRates()
+	date := time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, time.Local)
+
+	if err := rates.Add(date, "USD", "IDR", "15500"); err != nil {
+		t.Errorf("ExchangeRates.Add() error = %v", err)
+	}
+	if err := rates.Add(date, "USD", "IDR", "15600"); err == nil {
+		t.Errorf("ExchangeRates.Add() expected error for duplicated rate")
+	}
+	if err := rates.Add(date, "EUR", "IDR", "-1"); err == nil {
+		t.Errorf("ExchangeRates.Add() expected error for negative rate")
+	}
+	if err := rates.Add(date, "EUR", "IDR", "abc"); err == nil {
+		t.Errorf("ExchangeRates.Add() expected error for invalid rate")
+	}
+}
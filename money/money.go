@@ -0,0 +1,189 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is used for amounts that don't carry their own currency
+const DefaultCurrency = "IDR"
+
+// minorUnits is the number of decimal digits of the minor unit of each currency, currencies that are
+// not listed use two digits
+var minorUnits = map[string]int{
+	"IDR": 2,
+	"USD": 2,
+	"EUR": 2,
+	"SGD": 2,
+	"GBP": 2,
+	"JPY": 0,
+}
+
+// Money is an exact amount of money stored in the minor unit of its currency, for example
+// Rp1,500,000.00 is stored as 150000000 with currency IDR
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// New creates money from an amount in the minor unit of the currency
+func New(amount int64, currency string) Money {
+	return Money{
+		Amount:   amount,
+		Currency: currency,
+	}
+}
+
+// MinorUnit returns the number of decimal digits of the minor unit of the currency
+func MinorUnit(currency string) int {
+	if digits, ok := minorUnits[currency]; ok {
+		return digits
+	}
+	return 2
+}
+
+// Parse converts a plain decimal string like "-1500000.50" to money of the given currency without
+// losing precision, it fails when the value has more decimal digits than the currency minor unit
+func Parse(value string, currency string) (Money, error) {
+	digits := MinorUnit(currency)
+
+	negative := strings.HasPrefix(value, "-")
+	unsigned := strings.TrimPrefix(value, "-")
+
+	integer, fraction, hasFraction := strings.Cut(unsigned, ".")
+	if integer == "" || (hasFraction && fraction == "") {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+	if len(fraction) > digits {
+		return Money{}, fmt.Errorf("amount %q has more than %d decimal digits", value, digits)
+	}
+
+	// parse the whole amount in minor unit, "1500000.5" becomes "150000050"
+	minor := integer + fraction + strings.Repeat("0", digits-len(fraction))
+	for _, char := range minor {
+		if char < '0' || char > '9' {
+			return Money{}, fmt.Errorf("invalid amount %q", value)
+		}
+	}
+
+	amount, err := strconv.ParseInt(minor, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return New(amount, currency), nil
+}
+
+// MustParse is like Parse but panics when the value is invalid
+func MustParse(value string, currency string) Money {
+	result, err := Parse(value, currency)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// WithCurrency reads the same decimal amount in another currency without any exchange, it is used for
+// amounts that were given without currency. Digits below the minor unit of the new currency are dropped
+func (m Money) WithCurrency(currency string) Money {
+	amount := m.Amount
+	for digits := MinorUnit(m.Currency); digits < MinorUnit(currency); digits++ {
+		amount *= 10
+	}
+	for digits := MinorUnit(m.Currency); digits > MinorUnit(currency); digits-- {
+		amount /= 10
+	}
+	return New(amount, currency)
+}
+
+// Add returns the sum of both amounts, both must be in the same currency
+func (m Money) Add(other Money) Money {
+	return New(m.Amount+other.Amount, m.currencyWith(other))
+}
+
+// Sub returns the difference of both amounts, both must be in the same currency
+func (m Money) Sub(other Money) Money {
+	return New(m.Amount-other.Amount, m.currencyWith(other))
+}
+
+// currencyWith returns the currency of an operation with other money, a zero value has no currency yet
+func (m Money) currencyWith(other Money) string {
+	if m.Currency == "" {
+		return other.Currency
+	}
+	return m.Currency
+}
+
+// Abs returns the amount without its sign
+func (m Money) Abs() Money {
+	if m.Amount < 0 {
+		return New(-m.Amount, m.Currency)
+	}
+	return m
+}
+
+// IsZero checks whether the amount is zero regardless of the currency
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative checks whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// String formats the amount as a plain decimal string like "-1500000.50"
+func (m Money) String() string {
+	digits := MinorUnit(m.Currency)
+
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	value := strconv.FormatInt(amount, 10)
+	if digits == 0 {
+		return sign + value
+	}
+	if len(value) <= digits {
+		value = strings.Repeat("0", digits-len(value)+1) + value
+	}
+
+	return sign + value[:len(value)-digits] + "." + value[len(value)-digits:]
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON writes the amount as exact decimal string together with its currency
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{
+		Amount:   m.String(),
+		Currency: m.Currency,
+	})
+}
+
+// UnmarshalJSON reads money written by MarshalJSON
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var value moneyJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	result, err := Parse(value.Amount, value.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = result
+	return nil
+}
@@ -0,0 +1,194 @@
+package money
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	type args struct {
+		value    string
+		currency string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Money
+		wantErr bool
+	}{
+		{
+			name: "Succesful",
+			args: args{
+				value:    "1500000",
+				currency: "IDR",
+			},
+			want:    New(150000000, "IDR"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful with fraction",
+			args: args{
+				value:    "-1500000.5",
+				currency: "USD",
+			},
+			want:    New(-150000050, "USD"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful without minor unit",
+			args: args{
+				value:    "1500",
+				currency: "JPY",
+			},
+			want:    New(1500, "JPY"),
+			wantErr: false,
+		},
+		{
+			name: "Too many decimal digits",
+			args: args{
+				value:    "1500000.005",
+				currency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Not a number",
+			args: args{
+				value:    "1,500",
+				currency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Empty",
+			args: args{
+				value:    "",
+				currency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Empty fraction",
+			args: args{
+				value:    "15.",
+				currency: "IDR",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.args.value, tt.args.currency)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		want  string
+	}{
+		{
+			name:  "Succesful",
+			money: New(150000000, "IDR"),
+			want:  "1500000.00",
+		},
+		{
+			name:  "Negative below one",
+			money: New(-5, "USD"),
+			want:  "-0.05",
+		},
+		{
+			name:  "Without minor unit",
+			money: New(1500, "JPY"),
+			want:  "1500",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.String(); got != tt.want {
+				t.Errorf("Money.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	amount := MustParse("2000000", "IDR")
+	fee := MustParse("6500", "IDR")
+
+	if got := amount.Sub(fee); !reflect.DeepEqual(got, MustParse("1993500", "IDR")) {
+		t.Errorf("Money.Sub() = %v", got)
+	}
+	if got := (Money{}).Add(fee); !reflect.DeepEqual(got, fee) {
+		t.Errorf("Money.Add() = %v", got)
+	}
+	if got := fee.Sub(amount).Abs(); !reflect.DeepEqual(got, MustParse("1993500", "IDR")) {
+		t.Errorf("Money.Abs() = %v", got)
+	}
+	if !fee.Sub(amount).IsNegative() || fee.IsZero() {
+		t.Errorf("Money sign check is wrong")
+	}
+}
+
+func TestMoney_WithCurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		money    Money
+		currency string
+		want     Money
+	}{
+		{
+			name:     "Same minor unit",
+			money:    MustParse("6500.50", ""),
+			currency: "IDR",
+			want:     MustParse("6500.50", "IDR"),
+		},
+		{
+			name:     "Without minor unit",
+			money:    MustParse("6500.50", ""),
+			currency: "JPY",
+			want:     MustParse("6500", "JPY"),
+		},
+		{
+			name:     "With more minor unit",
+			money:    MustParse("6500", "JPY"),
+			currency: "USD",
+			want:     MustParse("6500", "USD"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.WithCurrency(tt.currency); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Money.WithCurrency() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(MustParse("-1500000.10", "IDR"))
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(data) != `{"amount":"-1500000.10","currency":"IDR"}` {
+		t.Errorf("json.Marshal() = %s", data)
+	}
+
+	var got Money
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, MustParse("-1500000.10", "IDR")) {
+		t.Errorf("json.Unmarshal() = %v", got)
+	}
+}
@@ -0,0 +1,195 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// NumberFormat defines which separators are used for thousands and decimals in an amount
+type NumberFormat string
+
+const (
+	// FormatEnglish writes amounts like 1,500,000.00
+	FormatEnglish NumberFormat = "en"
+	// FormatIndonesian writes amounts like 1.500.000,00
+	FormatIndonesian NumberFormat = "id"
+	// FormatAuto detects the separators from each amount, amounts that can be read both ways are rejected
+	FormatAuto NumberFormat = "auto"
+)
+
+// IsValid checks whether the number format is known
+func (f NumberFormat) IsValid() bool {
+	switch f {
+	case FormatEnglish, FormatIndonesian, FormatAuto:
+		return true
+	}
+	return false
+}
+
+// currencySymbols maps currency symbols and codes to ISO 4217 codes, longer symbols come first so
+// "US$" is not read as "$"
+var currencySymbols = []struct {
+	symbol   string
+	currency string
+}{
+	{"IDR", "IDR"},
+	{"USD", "USD"},
+	{"EUR", "EUR"},
+	{"SGD", "SGD"},
+	{"GBP", "GBP"},
+	{"JPY", "JPY"},
+	{"US$", "USD"},
+	{"S$", "SGD"},
+	{"Rp", "IDR"},
+	{"$", "USD"},
+	{"€", "EUR"},
+	{"£", "GBP"},
+	{"¥", "JPY"},
+}
+
+// ParseAmount converts an amount written with a currency symbol or code like "-Rp1,500,000.00",
+// "€1.000,50", "USD 25.10" or "8,500,000 IDR" to money. The separators are read with the given
+// number format, and amounts without currency get the default currency
+func ParseAmount(value string, format NumberFormat, defaultCurrency string) (Money, error) {
+	if !format.IsValid() {
+		return Money{}, fmt.Errorf("unknown number format %q", format)
+	}
+
+	number := strings.TrimSpace(value)
+
+	// the minus sign can be written before or after the currency, like "-Rp100" or "Rp-100"
+	negative := false
+	if strings.HasPrefix(number, "-") {
+		negative = true
+		number = strings.TrimSpace(strings.TrimPrefix(number, "-"))
+	}
+
+	number, currency := cutCurrency(number)
+	if currency == "" {
+		currency = defaultCurrency
+	}
+
+	if strings.HasPrefix(number, "-") {
+		if negative {
+			return Money{}, fmt.Errorf("invalid amount %q", value)
+		}
+		negative = true
+		number = strings.TrimSpace(strings.TrimPrefix(number, "-"))
+	}
+
+	decimal, err := normalizeNumber(number, format)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q: %w", value, err)
+	}
+	if negative {
+		decimal = "-" + decimal
+	}
+
+	return Parse(decimal, currency)
+}
+
+// cutCurrency removes the currency symbol or code at the start or end of the value and returns
+// its ISO 4217 code, the currency is empty when the value has none
+func cutCurrency(value string) (string, string) {
+	for _, currencySymbol := range currencySymbols {
+		if len(value) >= len(currencySymbol.symbol) && strings.EqualFold(value[:len(currencySymbol.symbol)], currencySymbol.symbol) {
+			return strings.TrimSpace(value[len(currencySymbol.symbol):]), currencySymbol.currency
+		}
+		if len(value) >= len(currencySymbol.symbol) && strings.EqualFold(value[len(value)-len(currencySymbol.symbol):], currencySymbol.symbol) {
+			return strings.TrimSpace(value[:len(value)-len(currencySymbol.symbol)]), currencySymbol.currency
+		}
+	}
+	return value, ""
+}
+
+// normalizeNumber converts the digits and separators of an amount to a plain decimal string like "1500000.00"
+func normalizeNumber(number string, format NumberFormat) (string, error) {
+	if number == "" {
+		return "", fmt.Errorf("amount is empty")
+	}
+	for _, char := range number {
+		if !unicode.IsDigit(char) && char != ',' && char != '.' {
+			return "", fmt.Errorf("unexpected character %q", char)
+		}
+	}
+
+	if format == FormatAuto {
+		var err error
+		format, err = detectNumberFormat(number)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	thousands, decimal := ",", "."
+	if format == FormatIndonesian {
+		thousands, decimal = ".", ","
+	}
+
+	if strings.Count(number, decimal) > 1 {
+		return "", fmt.Errorf("more than one decimal separator %q", decimal)
+	}
+
+	integer, fraction, hasFraction := strings.Cut(number, decimal)
+	if strings.Contains(fraction, thousands) {
+		return "", fmt.Errorf("thousands separator %q after decimal separator", thousands)
+	}
+	if strings.HasPrefix(integer, thousands) || strings.HasSuffix(integer, thousands) || strings.Contains(integer, thousands+thousands) {
+		return "", fmt.Errorf("misplaced thousands separator %q", thousands)
+	}
+
+	// separators that don't group three digits belong to the other number format, like 100,50 in FormatEnglish
+	groups := strings.Split(integer, thousands)
+	for index, group := range groups {
+		if len(groups) > 1 && (len(group) > 3 || index > 0 && len(group) != 3) {
+			return "", fmt.Errorf("thousands separator %q must group three digits, set the number format", thousands)
+		}
+	}
+
+	integer = strings.ReplaceAll(integer, thousands, "")
+	if !hasFraction {
+		return integer, nil
+	}
+	return integer + "." + fraction, nil
+}
+
+// detectNumberFormat guesses the number format from the separators of the amount. When both
+// separators are used the last one is the decimal separator, a separator used more than once is the
+// thousands separator, and a single separator followed by exactly three digits is ambiguous
+func detectNumberFormat(number string) (NumberFormat, error) {
+	lastComma, lastDot := strings.LastIndex(number, ","), strings.LastIndex(number, ".")
+
+	switch {
+	case lastComma < 0 && lastDot < 0:
+		return FormatEnglish, nil
+	case lastComma >= 0 && lastDot >= 0:
+		if lastComma > lastDot {
+			return FormatIndonesian, nil
+		}
+		return FormatEnglish, nil
+	}
+
+	separator, last := ",", lastComma
+	if lastDot >= 0 {
+		separator, last = ".", lastDot
+	}
+
+	// a repeated separator can only group thousands
+	if strings.Count(number, separator) > 1 {
+		if separator == "," {
+			return FormatEnglish, nil
+		}
+		return FormatIndonesian, nil
+	}
+
+	if len(number)-last-1 == 3 {
+		return "", fmt.Errorf("%q can be read both as thousands and decimal separator, set the number format", separator)
+	}
+
+	// a single separator not followed by three digits can only be the decimal separator
+	if separator == "." {
+		return FormatEnglish, nil
+	}
+	return FormatIndonesian, nil
+}
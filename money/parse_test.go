@@ -0,0 +1,250 @@
+package money
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	type args struct {
+		value           string
+		format          NumberFormat
+		defaultCurrency string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Money
+		wantErr bool
+	}{
+		{
+			name: "Succesful rupiah",
+			args: args{
+				value:           "Rp1,500,000",
+				format:          FormatEnglish,
+				defaultCurrency: "IDR",
+			},
+			want:    MustParse("1500000", "IDR"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful negative rupiah",
+			args: args{
+				value:           "-Rp8,500,000.50",
+				format:          FormatEnglish,
+				defaultCurrency: "IDR",
+			},
+			want:    MustParse("-8500000.50", "IDR"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful minus after currency",
+			args: args{
+				value:           "Rp-8,500,000",
+				format:          FormatEnglish,
+				defaultCurrency: "IDR",
+			},
+			want:    MustParse("-8500000", "IDR"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful indonesian format",
+			args: args{
+				value:           "Rp1.500.000,00",
+				format:          FormatIndonesian,
+				defaultCurrency: "IDR",
+			},
+			want:    MustParse("1500000", "IDR"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful euro",
+			args: args{
+				value:           "€1.000,50",
+				format:          FormatIndonesian,
+				defaultCurrency: "IDR",
+			},
+			want:    MustParse("1000.50", "EUR"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful currency code after amount",
+			args: args{
+				value:           "25.10 usd",
+				format:          FormatEnglish,
+				defaultCurrency: "IDR",
+			},
+			want:    MustParse("25.10", "USD"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful without currency",
+			args: args{
+				value:           "1500000",
+				format:          FormatEnglish,
+				defaultCurrency: "SGD",
+			},
+			want:    MustParse("1500000", "SGD"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful auto with both separators",
+			args: args{
+				value:           "$1.500.000,25",
+				format:          FormatAuto,
+				defaultCurrency: "IDR",
+			},
+			want:    MustParse("1500000.25", "USD"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful auto with repeated separator",
+			args: args{
+				value:           "Rp1.500.000",
+				format:          FormatAuto,
+				defaultCurrency: "IDR",
+			},
+			want:    MustParse("1500000", "IDR"),
+			wantErr: false,
+		},
+		{
+			name: "Succesful auto with decimal separator",
+			args: args{
+				value:           "Rp1500,5",
+				format:          FormatAuto,
+				defaultCurrency: "IDR",
+			},
+			want:    MustParse("1500.5", "IDR"),
+			wantErr: false,
+		},
+		{
+			name: "Ambiguous auto",
+			args: args{
+				value:           "€1.000",
+				format:          FormatAuto,
+				defaultCurrency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Indonesian amount read as english",
+			args: args{
+				value:           "Rp1.500.000,00",
+				format:          FormatEnglish,
+				defaultCurrency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown currency",
+			args: args{
+				value:           "XYZ1,500",
+				format:          FormatEnglish,
+				defaultCurrency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Misplaced thousands separator",
+			args: args{
+				value:           "1,,500",
+				format:          FormatEnglish,
+				defaultCurrency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Double minus",
+			args: args{
+				value:           "-Rp-1,500",
+				format:          FormatEnglish,
+				defaultCurrency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown number format",
+			args: args{
+				value:           "1,500",
+				format:          "fr",
+				defaultCurrency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Failed english format with decimal comma",
+			args: args{
+				value:           "Rp100,50",
+				format:          FormatEnglish,
+				defaultCurrency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Failed english format with one decimal comma",
+			args: args{
+				value:           "1,5",
+				format:          FormatEnglish,
+				defaultCurrency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Failed indonesian format with decimal dot",
+			args: args{
+				value:           "1.50",
+				format:          FormatIndonesian,
+				defaultCurrency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Failed indonesian format with short group",
+			args: args{
+				value:           "1.500.00",
+				format:          FormatIndonesian,
+				defaultCurrency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Failed english format with long first group",
+			args: args{
+				value:           "1500,000",
+				format:          FormatEnglish,
+				defaultCurrency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Failed auto format with short group",
+			args: args{
+				value:           "1,50,000",
+				format:          FormatAuto,
+				defaultCurrency: "IDR",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Empty",
+			args: args{
+				value:           "Rp",
+				format:          FormatEnglish,
+				defaultCurrency: "IDR",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAmount(tt.args.value, tt.args.format, tt.args.defaultCurrency)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseAmount() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
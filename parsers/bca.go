@@ -0,0 +1,108 @@
+package parsers
+
+import (
+	"amartha-test/entities/transactions"
+	"amartha-test/money"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// bcaHeader is the header of the transactions table of a KlikBCA Bisnis account statement export
+var bcaHeader = []string{"Tanggal Transaksi", "Keterangan", "Cabang", "Jumlah"}
+
+// BCA reads the account statement csv of KlikBCA Bisnis. The export starts with the account number and period,
+// every transaction is dated without year like '13/01 and its amount is followed by CR or DB, and the table
+// ends with the balance and total rows
+//
+//	No. rekening : ,'0123456789
+//	Periode : ,01/01/2024 - 31/01/2024
+//	Tanggal Transaksi,Keterangan,Cabang,Jumlah,,Saldo
+//	'13/01,TRSF E-BANKING CR 1301/FTSCY/WS95031,'0000,"2,000,000.00",CR,"12,000,000.00"
+//	Saldo Awal : ,"10,000,000.00"
+type BCA struct{}
+
+func (BCA) Detect(content []byte) bool {
+	return detectHeader(content, bcaHeader)
+}
+
+func (BCA) NumberFormat() money.NumberFormat {
+	return money.FormatEnglish
+}
+
+func (BCA) Parse(file io.Reader) (result []*transactions.BankStatements, err error) {
+	records, err := readRecords(file)
+	if err != nil {
+		return nil, err
+	}
+
+	header := findHeader(records, bcaHeader)
+	if header < 0 {
+		return nil, errors.New("transactions table of BCA statement is not found")
+	}
+
+	// the transactions only have day and month, the year is taken from the period of the statement
+	start, end, err := bcaPeriod(findValue(records[:header], "Periode"))
+	if err != nil {
+		return nil, err
+	}
+	account := findValue(records[:header], "No. rekening")
+
+	for _, r := range records[header+1:] {
+		// the table ends at the first empty row or the balance rows
+		if r.isEmpty() || strings.HasSuffix(r.field(0), ":") {
+			break
+		}
+
+		amount := r.field(3)
+		switch strings.ToUpper(r.field(4)) {
+		case "DB":
+			amount = "-" + amount
+		case "CR":
+		default:
+			return nil, fmt.Errorf("line %d has unknown mutation type %q, use CR or DB", r.line, r.field(4))
+		}
+
+		result = append(result, &transactions.BankStatements{
+			ID:     fmt.Sprintf("BCA_%s-%d", account, r.line),
+			Amount: amount,
+			Date:   bcaDate(strings.TrimPrefix(r.field(0), "'"), start, end),
+			Bank:   "BCA",
+			Line:   r.line,
+		})
+	}
+
+	return result, nil
+}
+
+// bcaPeriod reads the period of the statement written like 01/01/2024 - 31/01/2024
+func bcaPeriod(period string) (start, end time.Time, err error) {
+	startValue, endValue, _ := strings.Cut(period, "-")
+
+	start, err = time.Parse(dateFormat, strings.TrimSpace(startValue))
+	if err != nil {
+		return start, end, fmt.Errorf("period of BCA statement %q is invalid", period)
+	}
+	end, err = time.Parse(dateFormat, strings.TrimSpace(endValue))
+	if err != nil {
+		return start, end, fmt.Errorf("period of BCA statement %q is invalid", period)
+	}
+
+	return start, end, nil
+}
+
+// bcaDate adds the year of the period to a date written like 13/01, a period can span the new year so the
+// date is in the year of the end of the period when it would be before the start of the period, or when it
+// is the 29th of February that the year of the start of the period does not have
+func bcaDate(value string, start, end time.Time) string {
+	date, err := time.Parse(dateFormat, fmt.Sprintf("%s/%d", value, start.Year()))
+	if err != nil || date.Before(start) {
+		date, err = time.Parse(dateFormat, fmt.Sprintf("%s/%d", value, end.Year()))
+		if err != nil {
+			return value
+		}
+	}
+	return date.Format(dateFormat)
+}
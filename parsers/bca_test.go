@@ -0,0 +1,114 @@
+package parsers
+
+import (
+	"amartha-test/entities/transactions"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const bcaStatement = `No. rekening : ,'0123456789
+Nama : ,PT AMARTHA
+Periode : ,15/12/2023 - 14/01/2024
+Kode Mata Uang : ,Rp
+,
+Tanggal Transaksi,Keterangan,Cabang,Jumlah,,Saldo
+'28/12,TRSF E-BANKING CR 2812/FTSCY/WS95031,'0000,"2,000,000.00",CR,"12,000,000.00"
+'13/01,BIAYA ADM,'0000,"15,000.00",DB,"11,985,000.00"
+,
+Saldo Awal : ,"10,000,000.00"
+Mutasi Kredit : ,"2,000,000.00",1
+Mutasi Debet : ,"15,000.00",1
+Saldo Akhir : ,"11,985,000.00"
+`
+
+func TestBCA_Parse(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantResult []*transactions.BankStatements
+		wantErr    bool
+	}{
+		{
+			name:    "Succesful",
+			content: bcaStatement,
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "BCA_0123456789-7",
+					Amount: "2,000,000.00",
+					Date:   "28/12/2023",
+					Bank:   "BCA",
+					Line:   7,
+				},
+				{
+					ID:     "BCA_0123456789-8",
+					Amount: "-15,000.00",
+					Date:   "13/01/2024",
+					Bank:   "BCA",
+					Line:   8,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Succesful leap day in the year of the end of the period",
+			content: strings.NewReplacer(
+				"15/12/2023 - 14/01/2024", "15/12/2023 - 29/02/2024",
+				"'13/01,BIAYA ADM", "'29/02,BIAYA ADM",
+			).Replace(bcaStatement),
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "BCA_0123456789-7",
+					Amount: "2,000,000.00",
+					Date:   "28/12/2023",
+					Bank:   "BCA",
+					Line:   7,
+				},
+				{
+					ID:     "BCA_0123456789-8",
+					Amount: "-15,000.00",
+					Date:   "29/02/2024",
+					Bank:   "BCA",
+					Line:   8,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Transactions table is not found",
+			content: "unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024",
+			wantErr: true,
+		},
+		{
+			name:    "Period is invalid",
+			content: strings.Replace(bcaStatement, "15/12/2023 - 14/01/2024", "Desember 2023", 1),
+			wantErr: true,
+		},
+		{
+			name:    "Mutation type is unknown",
+			content: strings.Replace(bcaStatement, `"15,000.00",DB`, `"15,000.00",XX`, 1),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := BCA{}.Parse(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BCA.Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResult, tt.wantResult) {
+				t.Errorf("BCA.Parse() = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestBCA_Detect(t *testing.T) {
+	if !(BCA{}).Detect([]byte(bcaStatement)) {
+		t.Errorf("BCA.Detect() = false, want true")
+	}
+	if (BCA{}).Detect([]byte(briStatement)) {
+		t.Errorf("BCA.Detect() = true, want false")
+	}
+}
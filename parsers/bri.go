@@ -0,0 +1,74 @@
+package parsers
+
+import (
+	"amartha-test/entities/transactions"
+	"amartha-test/money"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// briHeader is the header of the transactions table of a BRI CMS account statement export
+var briHeader = []string{"TANGGAL", "URAIAN TRANSAKSI", "TELLER", "DEBET", "KREDIT", "SALDO"}
+
+// BRI reads the account statement csv of BRI CMS. The export starts with the title and account number, every
+// transaction has separate debit and credit columns, and the table ends with the balance and total rows
+//
+//	LAPORAN MUTASI REKENING
+//	Nomor Rekening,0123-01-000123-30-1
+//	TANGGAL,URAIAN TRANSAKSI,TELLER,DEBET,KREDIT,SALDO
+//	13/01/24 08:20:11,TRANSFER DARI AMARTHA,8888071,0.00,"2,000,000.00","12,000,000.00"
+//	SALDO AWAL,"10,000,000.00"
+type BRI struct{}
+
+func (BRI) Detect(content []byte) bool {
+	return detectHeader(content, briHeader)
+}
+
+func (BRI) NumberFormat() money.NumberFormat {
+	return money.FormatEnglish
+}
+
+func (BRI) Parse(file io.Reader) (result []*transactions.BankStatements, err error) {
+	records, err := readRecords(file)
+	if err != nil {
+		return nil, err
+	}
+
+	header := findHeader(records, briHeader)
+	if header < 0 {
+		return nil, errors.New("transactions table of BRI statement is not found")
+	}
+	account := strings.ReplaceAll(findValue(records[:header], "Nomor Rekening"), "-", "")
+
+	for _, r := range records[header+1:] {
+		// the table ends at the first empty row or the balance and total rows
+		if r.isEmpty() || isBRIFooter(r.field(0)) {
+			break
+		}
+
+		amount, err := signedAmount(r, r.field(3), r.field(4))
+		if err != nil {
+			return nil, err
+		}
+
+		// transactions are timestamped like 13/01/24 08:20:11
+		date, _, _ := strings.Cut(r.field(0), " ")
+
+		result = append(result, &transactions.BankStatements{
+			ID:     fmt.Sprintf("BRI_%s-%d", account, r.line),
+			Amount: amount,
+			Date:   formatDate(date, "02/01/06"),
+			Bank:   "BRI",
+			Line:   r.line,
+		})
+	}
+
+	return result, nil
+}
+
+func isBRIFooter(value string) bool {
+	value = strings.ToUpper(value)
+	return strings.HasPrefix(value, "SALDO") || strings.HasPrefix(value, "TOTAL")
+}
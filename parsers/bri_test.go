@@ -0,0 +1,95 @@
+package parsers
+
+import (
+	"amartha-test/entities/transactions"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const briStatement = `LAPORAN MUTASI REKENING
+Nomor Rekening,0123-01-000123-30-1
+Periode,01/01/2024 s/d 31/01/2024
+
+TANGGAL,URAIAN TRANSAKSI,TELLER,DEBET,KREDIT,SALDO
+13/01/24 08:20:11,TRANSFER DARI AMARTHA,8888071,0.00,"2,000,000.00","12,000,000.00"
+14/01/24 10:01:00,BIAYA ADM,8888071,"15,000.00",0.00,"11,985,000.00"
+SALDO AWAL,"10,000,000.00"
+TOTAL MUTASI DEBET,"15,000.00"
+TOTAL MUTASI KREDIT,"2,000,000.00"
+SALDO AKHIR,"11,985,000.00"
+`
+
+func TestBRI_Parse(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantResult []*transactions.BankStatements
+		wantErr    bool
+	}{
+		{
+			name:    "Succesful",
+			content: briStatement,
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "BRI_012301000123301-6",
+					Amount: "2,000,000.00",
+					Date:   "13/01/2024",
+					Bank:   "BRI",
+					Line:   6,
+				},
+				{
+					ID:     "BRI_012301000123301-7",
+					Amount: "-15,000.00",
+					Date:   "14/01/2024",
+					Bank:   "BRI",
+					Line:   7,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Succesful keeps invalid date",
+			content: strings.Replace(briStatement, "13/01/24 08:20:11", "2024-01-13", 1),
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "BRI_012301000123301-6",
+					Amount: "2,000,000.00",
+					Date:   "2024-01-13",
+					Bank:   "BRI",
+					Line:   6,
+				},
+				{
+					ID:     "BRI_012301000123301-7",
+					Amount: "-15,000.00",
+					Date:   "14/01/2024",
+					Bank:   "BRI",
+					Line:   7,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Both debit and credit",
+			content: strings.Replace(briStatement, `0.00,"2,000,000.00"`, `"1.00","2,000,000.00"`, 1),
+			wantErr: true,
+		},
+		{
+			name:    "Transactions table is not found",
+			content: bcaStatement,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := BRI{}.Parse(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BRI.Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResult, tt.wantResult) {
+				t.Errorf("BRI.Parse() = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package parsers
+
+import (
+	"amartha-test/entities/transactions"
+	"amartha-test/money"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// banks by the first four letters of their BIC, the statements of other banks are sourced with their BIC
+var bicBanks = map[string]string{
+	"CENA": "BCA",
+	"BRIN": "BRI",
+	"BMRI": "MANDIRI",
+	"BNIN": "BNI",
+	"BBBA": "PERMATA",
+	"BNIA": "CIMB",
+}
+
+// Camt reads ISO 20022 camt.053 end of day statements and camt.054 debit and credit notifications. Every
+// booked entry becomes a bank statement identified by its account servicer reference
+//
+//	<Ntry>
+//	  <Amt Ccy="IDR">2000000.00</Amt>
+//	  <CdtDbtInd>CRDT</CdtDbtInd>
+//	  <Sts>BOOK</Sts>
+//	  <BookgDt><Dt>2024-01-13</Dt></BookgDt>
+//	  <AcctSvcrRef>FT24013ABC</AcctSvcrRef>
+//	</Ntry>
+type Camt struct{}
+
+type camtAccount struct {
+	IBAN    string `xml:"Id>IBAN"`
+	OtherID string `xml:"Id>Othr>Id"`
+	BIC     string `xml:"Svcr>FinInstnId>BIC"`
+	BICF    string `xml:"Svcr>FinInstnId>BICFI"`
+}
+
+// bank returns the bank that services the account, the BIC of banks not in bicBanks, or the account itself when
+// its servicer is not written
+func (a camtAccount) bank() string {
+	bic := strings.TrimSpace(a.BIC)
+	if bic == "" {
+		bic = strings.TrimSpace(a.BICF)
+	}
+	if len(bic) >= 4 {
+		if bank, ok := bicBanks[strings.ToUpper(bic[:4])]; ok {
+			return bank
+		}
+	}
+	for _, bank := range []string{bic, a.IBAN, a.OtherID} {
+		// the bank source is written before an underscore in unique_identifier
+		if bank = strings.ReplaceAll(strings.TrimSpace(bank), "_", ""); bank != "" {
+			return strings.ToUpper(bank)
+		}
+	}
+	return ""
+}
+
+type camtEntry struct {
+	Amount struct {
+		Value    string `xml:",chardata"`
+		Currency string `xml:"Ccy,attr"`
+	} `xml:"Amt"`
+	CreditDebit string `xml:"CdtDbtInd"`
+	Reversal    bool   `xml:"RvslInd"`
+	Status      struct {
+		Value string `xml:",chardata"`
+		Code  string `xml:"Cd"` // camt.053.001.08 and later wrap the status in a code
+	} `xml:"Sts"`
+	// references of the transactions of a batch entry, the entry is identified by them when it has no reference
+	Details []struct {
+		ServicerRef string `xml:"Refs>AcctSvcrRef"`
+		EndToEndID  string `xml:"Refs>EndToEndId"`
+	} `xml:"NtryDtls>TxDtls"`
+	BookingDate string `xml:"BookgDt>Dt"`
+	BookingTime string `xml:"BookgDt>DtTm"`
+	ServicerRef string `xml:"AcctSvcrRef"`
+	EntryRef    string `xml:"NtryRef"`
+	ValueDate   string `xml:"ValDt>Dt"`
+	ValueTime   string `xml:"ValDt>DtTm"`
+}
+
+// isBooked reports whether the entry is booked, pending and information entries are not settled yet
+func (e camtEntry) isBooked() bool {
+	status := strings.TrimSpace(e.Status.Code)
+	if status == "" {
+		status = strings.TrimSpace(e.Status.Value)
+	}
+	return status == "" || strings.EqualFold(status, "BOOK")
+}
+
+// date returns the booking date of the entry, or the value date when the entry has no booking date
+func (e camtEntry) date() string {
+	for _, date := range []string{e.BookingDate, e.BookingTime, e.ValueDate, e.ValueTime} {
+		if date = strings.TrimSpace(date); date != "" {
+			// date times are written like 2024-01-13T08:20:00+07:00
+			if len(date) > len("2006-01-02") {
+				date = date[:len("2006-01-02")]
+			}
+			return formatDate(date, "2006-01-02")
+		}
+	}
+	return ""
+}
+
+// reference returns the account servicer reference of the entry, or the first other reference written
+func (e camtEntry) reference() string {
+	references := []string{e.ServicerRef, e.EntryRef}
+	for _, details := range e.Details {
+		references = append(references, details.ServicerRef, details.EndToEndID)
+	}
+	for _, reference := range references {
+		// NOTPROVIDED is written when the end to end id is missing
+		if reference = strings.TrimSpace(reference); reference != "" && reference != "NOTPROVIDED" {
+			return reference
+		}
+	}
+	return ""
+}
+
+func (Camt) Detect(content []byte) bool {
+	return bytes.Contains(content, []byte("urn:iso:std:iso:20022:tech:xsd:camt.053")) ||
+		bytes.Contains(content, []byte("urn:iso:std:iso:20022:tech:xsd:camt.054"))
+}
+
+func (Camt) NumberFormat() money.NumberFormat {
+	return money.FormatEnglish
+}
+
+func (Camt) Parse(file io.Reader) (result []*transactions.BankStatements, err error) {
+	decoder := xml.NewDecoder(file)
+
+	// the account of a statement or notification is written before its entries
+	bank := ""
+	found := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "BkToCstmrStmt", "BkToCstmrDbtCdtNtfctn":
+			found = true
+
+		case "Acct":
+			line, _ := decoder.InputPos()
+
+			var account camtAccount
+			err = decoder.DecodeElement(&account, &start)
+			if err != nil {
+				return nil, err
+			}
+			bank = account.bank()
+			if bank == "" {
+				return nil, fmt.Errorf("line %d: account has no IBAN, id or servicer BIC", line)
+			}
+
+		case "Ntry":
+			line, _ := decoder.InputPos()
+			if bank == "" {
+				return nil, fmt.Errorf("line %d: entry is written before its account", line)
+			}
+
+			var entry camtEntry
+			err = decoder.DecodeElement(&entry, &start)
+			if err != nil {
+				return nil, err
+			}
+			if !entry.isBooked() {
+				continue
+			}
+
+			bankStatement, err := camtBankStatement(entry, bank, line)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, bankStatement)
+		}
+	}
+
+	if !found {
+		return nil, errors.New("camt statement or notification is not found")
+	}
+	return result, nil
+}
+
+func camtBankStatement(entry camtEntry, bank string, line int) (*transactions.BankStatements, error) {
+	debit := false
+	switch strings.TrimSpace(entry.CreditDebit) {
+	case "DBIT":
+		debit = true
+	case "CRDT":
+	default:
+		return nil, fmt.Errorf("line %d has unknown credit debit indicator %q, use CRDT or DBIT", line, entry.CreditDebit)
+	}
+	// a reversal entry cancels an earlier entry of the opposite direction
+	if entry.Reversal {
+		debit = !debit
+	}
+
+	amount := strings.TrimSpace(entry.Amount.Value)
+	if debit {
+		amount = "-" + amount
+	}
+	if entry.Amount.Currency != "" {
+		amount += " " + entry.Amount.Currency
+	}
+
+	reference := entry.reference()
+	if reference == "" {
+		return nil, fmt.Errorf("line %d has no AcctSvcrRef, NtryRef or EndToEndId", line)
+	}
+
+	return &transactions.BankStatements{
+		ID:     bank + "_" + reference,
+		Amount: amount,
+		Date:   entry.date(),
+		Bank:   bank,
+		Line:   line,
+	}, nil
+}
@@ -0,0 +1,179 @@
+package parsers
+
+import (
+	"amartha-test/entities/transactions"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const camtStatement = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <GrpHdr>
+      <MsgId>STMT20240114</MsgId>
+      <CreDtTm>2024-01-14T23:00:00+07:00</CreDtTm>
+    </GrpHdr>
+    <Stmt>
+      <Id>STMT20240114-1</Id>
+      <Acct>
+        <Id><Othr><Id>0123456789</Id></Othr></Id>
+        <Ccy>IDR</Ccy>
+        <Svcr><FinInstnId><BIC>CENAIDJA</BIC></FinInstnId></Svcr>
+      </Acct>
+      <Ntry>
+        <Amt Ccy="IDR">2000000.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2024-01-13</Dt></BookgDt>
+        <ValDt><Dt>2024-01-13</Dt></ValDt>
+        <AcctSvcrRef>FT24013ABC</AcctSvcrRef>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="IDR">15000.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><DtTm>2024-01-14T10:01:00+07:00</DtTm></BookgDt>
+        <AcctSvcrRef>FT24014XYZ</AcctSvcrRef>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="IDR">500000.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts>PDNG</Sts>
+        <ValDt><Dt>2024-01-15</Dt></ValDt>
+        <AcctSvcrRef>FT24015PND</AcctSvcrRef>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>
+`
+
+const camtNotification = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.054.001.08">
+  <BkToCstmrDbtCdtNtfctn>
+    <Ntfctn>
+      <Acct>
+        <Id><Othr><Id>0123456789</Id></Othr></Id>
+        <Svcr><FinInstnId><BICFI>XXXXIDJA</BICFI></FinInstnId></Svcr>
+      </Acct>
+      <Ntry>
+        <NtryRef>77</NtryRef>
+        <Amt Ccy="USD">200.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <RvslInd>true</RvslInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><Dt>2024-01-13</Dt></BookgDt>
+      </Ntry>
+    </Ntfctn>
+  </BkToCstmrDbtCdtNtfctn>
+</Document>
+`
+
+func TestCamt_Parse(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantResult []*transactions.BankStatements
+		wantErr    bool
+	}{
+		{
+			name:    "Succesful statement",
+			content: camtStatement,
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "BCA_FT24013ABC",
+					Amount: "2000000.00 IDR",
+					Date:   "13/01/2024",
+					Bank:   "BCA",
+					Line:   15,
+				},
+				{
+					ID:     "BCA_FT24014XYZ",
+					Amount: "-15000.00 IDR",
+					Date:   "14/01/2024",
+					Bank:   "BCA",
+					Line:   23,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Succesful notification of unknown bank",
+			content: camtNotification,
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "XXXXIDJA_77",
+					Amount: "200.00 USD",
+					Date:   "13/01/2024",
+					Bank:   "XXXXIDJA",
+					Line:   9,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Succesful account without servicer and batch entry",
+			content: strings.NewReplacer(
+				"<Svcr><FinInstnId><BICFI>XXXXIDJA</BICFI></FinInstnId></Svcr>", "",
+				"<Othr><Id>0123456789</Id></Othr>", "<IBAN>id12_0123456789</IBAN>",
+				"<NtryRef>77</NtryRef>", "<NtryDtls><TxDtls><Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs></TxDtls><TxDtls><Refs><EndToEndId>E2E-1</EndToEndId></Refs></TxDtls></NtryDtls>",
+			).Replace(camtNotification),
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "ID120123456789_E2E-1",
+					Amount: "200.00 USD",
+					Date:   "13/01/2024",
+					Bank:   "ID120123456789",
+					Line:   9,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Entry has no reference",
+			content: strings.Replace(camtNotification, "<NtryRef>77</NtryRef>", "", 1),
+			wantErr: true,
+		},
+		{
+			name:    "Account has no id",
+			content: strings.NewReplacer("<Id><Othr><Id>0123456789</Id></Othr></Id>", "", "<Svcr><FinInstnId><BICFI>XXXXIDJA</BICFI></FinInstnId></Svcr>", "").Replace(camtNotification),
+			wantErr: true,
+		},
+		{
+			name:    "Credit debit indicator is unknown",
+			content: strings.Replace(camtStatement, "<CdtDbtInd>CRDT</CdtDbtInd>", "<CdtDbtInd>C</CdtDbtInd>", 1),
+			wantErr: true,
+		},
+		{
+			name:    "Not a camt document",
+			content: `<?xml version="1.0"?><Document></Document>`,
+			wantErr: true,
+		},
+		{
+			name:    "Not xml",
+			content: bcaStatement,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := Camt{}.Parse(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Camt.Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResult, tt.wantResult) {
+				t.Errorf("Camt.Parse() = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestCamt_Detect(t *testing.T) {
+	if !(Camt{}).Detect([]byte(camtNotification)) {
+		t.Errorf("Camt.Detect() = false, want true")
+	}
+	if (Camt{}).Detect([]byte(mandiriStatement)) {
+		t.Errorf("Camt.Detect() = true, want false")
+	}
+}
@@ -0,0 +1,66 @@
+package parsers
+
+import (
+	"amartha-test/entities/transactions"
+	"amartha-test/money"
+	"errors"
+	"io"
+	"strings"
+)
+
+// mandiriHeader is the header of the transactions table of a Mandiri Cash Management account statement export
+var mandiriHeader = []string{"Account No", "Ccy", "Post Date", "Value Date", "Transaction Code", "Description", "Reference No.", "Debit", "Credit"}
+
+// Mandiri reads the account statement csv of Mandiri Cash Management. Every transaction has its currency, a
+// reference number and separate debit and credit columns written like 2.000.000,00, and the table ends with
+// the balance rows
+//
+//	Account No,Ccy,Post Date,Value Date,Transaction Code,Description,Reference No.,Debit,Credit,Balance
+//	1234567890,IDR,13/01/2024 08:20,13/01/2024,ATR,TRANSFER AMARTHA,FT24013ABC,",00","2.000.000,00","12.000.000,00"
+//	Opening Balance,"10.000.000,00"
+type Mandiri struct{}
+
+func (Mandiri) Detect(content []byte) bool {
+	return detectHeader(content, mandiriHeader)
+}
+
+func (Mandiri) NumberFormat() money.NumberFormat {
+	return money.FormatIndonesian
+}
+
+func (Mandiri) Parse(file io.Reader) (result []*transactions.BankStatements, err error) {
+	records, err := readRecords(file)
+	if err != nil {
+		return nil, err
+	}
+
+	header := findHeader(records, mandiriHeader)
+	if header < 0 {
+		return nil, errors.New("transactions table of Mandiri statement is not found")
+	}
+
+	for _, r := range records[header+1:] {
+		// the table ends at the first empty row or the balance rows
+		if r.isEmpty() || strings.HasSuffix(strings.ToLower(r.field(0)), "balance") {
+			break
+		}
+
+		amount, err := signedAmount(r, r.field(7), r.field(8))
+		if err != nil {
+			return nil, err
+		}
+
+		// the transaction is dated with its post date, written with the time it was posted
+		date, _, _ := strings.Cut(r.field(2), " ")
+
+		result = append(result, &transactions.BankStatements{
+			ID:     "MANDIRI_" + r.field(6),
+			Amount: strings.TrimSpace(amount + " " + r.field(1)),
+			Date:   date,
+			Bank:   "MANDIRI",
+			Line:   r.line,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package parsers
+
+import (
+	"amartha-test/entities/transactions"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const mandiriStatement = `Account No,Ccy,Post Date,Value Date,Transaction Code,Description,Reference No.,Debit,Credit,Balance
+1234567890,IDR,13/01/2024 08:20,13/01/2024,ATR,TRANSFER AMARTHA,FT24013ABC,",00","2.000.000,00","12.000.000,00"
+1234567890,IDR,14/01/2024 10:01,15/01/2024,ADM,BIAYA ADM,FT24014XYZ,"15.000,00",",00","11.985.000,00"
+Opening Balance,"10.000.000,00"
+Closing Balance,"11.985.000,00"
+`
+
+func TestMandiri_Parse(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantResult []*transactions.BankStatements
+		wantErr    bool
+	}{
+		{
+			name:    "Succesful",
+			content: mandiriStatement,
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "MANDIRI_FT24013ABC",
+					Amount: "2.000.000,00 IDR",
+					Date:   "13/01/2024",
+					Bank:   "MANDIRI",
+					Line:   2,
+				},
+				{
+					ID:     "MANDIRI_FT24014XYZ",
+					Amount: "-15.000,00 IDR",
+					Date:   "14/01/2024",
+					Bank:   "MANDIRI",
+					Line:   3,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Both debit and credit",
+			content: strings.Replace(mandiriStatement, `",00","2.000.000,00"`, `"1,00","2.000.000,00"`, 1),
+			wantErr: true,
+		},
+		{
+			name:    "Transactions table is not found",
+			content: briStatement,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := Mandiri{}.Parse(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Mandiri.Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResult, tt.wantResult) {
+				t.Errorf("Mandiri.Parse() = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
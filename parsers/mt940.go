@@ -0,0 +1,234 @@
+package parsers
+
+import (
+	"amartha-test/entities/transactions"
+	"amartha-test/money"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// mt940StatementLine reads the :61: field, value date YYMMDD, optional entry date MMDD, debit credit mark,
+// optional funds code, amount with decimal comma, transaction type and the references
+var mt940StatementLine = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d*)([NSF][A-Z0-9]{3})(.*)$`)
+
+// mt940Balance reads the currency of the :60F: and :60M: opening balance fields
+var mt940Balance = regexp.MustCompile(`^[CD]\d{6}([A-Z]{3})`)
+
+// MT940 reads SWIFT MT940 customer statements. The bank source is read from the :25: account identification,
+// every :61: statement line becomes a bank statement identified by its bank reference, or by its customer
+// reference when the bank gives none, and the :86: narrative that follows it is kept as its description. A statement
+// line without reference is identified by the account, the :28C: statement number and its place in the statement,
+// so the same statement gives the same identifiers whatever is written around it
+//
+//	:20:STMT20240114
+//	:25:CENAIDJA/0123456789
+//	:60F:C240112IDR10000000,00
+//	:61:2401130113C2000000,00NTRFAMARTHA//FT24013ABC
+//	:86:TRANSFER DARI AMARTHA
+//	:62F:C240114IDR12000000,00
+type MT940 struct{}
+
+// mt940Field is one field of the statement with the line number of its tag
+type mt940Field struct {
+	tag   string
+	value string
+	line  int
+}
+
+func (MT940) Detect(content []byte) bool {
+	text := string(content)
+	return strings.Contains(text, ":20:") && strings.Contains(text, ":25:") && strings.Contains(text, ":61:")
+}
+
+func (MT940) NumberFormat() money.NumberFormat {
+	return money.FormatIndonesian
+}
+
+func (MT940) Parse(file io.Reader) (result []*transactions.BankStatements, err error) {
+	fields, err := readMT940Fields(file)
+	if err != nil {
+		return nil, err
+	}
+
+	bank := ""
+	account := ""
+	statement := ""
+	currency := ""
+	entry := 0
+	var last *transactions.BankStatements
+	for _, field := range fields {
+		switch field.tag {
+		case "20":
+			// a new statement starts, it is numbered with its transaction reference unless it has a :28C: field
+			bank, account, currency, last, entry = "", "", "", nil, 0
+			statement = strings.TrimSpace(field.value)
+
+		case "25":
+			bank = mt940Bank(field.value)
+			account = mt940Account(field.value)
+
+		case "28C":
+			statement = strings.TrimSpace(field.value)
+
+		case "60F", "60M":
+			match := mt940Balance.FindStringSubmatch(field.value)
+			if match == nil {
+				return nil, fmt.Errorf("line %d: opening balance %q is invalid", field.line, field.value)
+			}
+			currency = match[1]
+
+		case "61":
+			if bank == "" {
+				return nil, fmt.Errorf("line %d: statement line is written before the :25: account identification", field.line)
+			}
+
+			entry++
+			last, err = mt940BankStatement(field, bank, currency, fmt.Sprintf("%s_%s_%d", account, statement, entry))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, last)
+
+		case "86":
+			// the narrative belongs to the statement line right before it
+			if last != nil {
+				last.Description = field.value
+			}
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("MT940 statement is empty")
+	}
+	return result, nil
+}
+
+// readMT940Fields splits the statement into its fields, lines without tag continue the field before them
+func readMT940Fields(file io.Reader) (fields []mt940Field, err error) {
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r ")
+
+		// the fields can be wrapped in the text block {4: ... -} of a SWIFT message
+		text = strings.TrimPrefix(text, "{4:")
+		if text == "" || text == "-" || text == "-}" || strings.HasPrefix(text, "{") {
+			continue
+		}
+
+		if strings.HasPrefix(text, ":") {
+			tag, value, ok := strings.Cut(text[1:], ":")
+			if !ok {
+				return nil, fmt.Errorf("line %d: field tag is not closed", line)
+			}
+			fields = append(fields, mt940Field{tag: tag, value: value, line: line})
+			continue
+		}
+
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("line %d: text is written before the first field", line)
+		}
+		fields[len(fields)-1].value += "\n" + text
+	}
+
+	return fields, scanner.Err()
+}
+
+// mt940Bank returns the bank of an account identification written like BIC/account, an account identification
+// without BIC is the bank source itself
+func mt940Bank(account string) string {
+	bic, _, ok := strings.Cut(strings.TrimSpace(account), "/")
+	if !ok {
+		return strings.ToUpper(strings.TrimSpace(account))
+	}
+	if len(bic) >= 4 {
+		if bank, ok := bicBanks[strings.ToUpper(bic[:4])]; ok {
+			return bank
+		}
+	}
+	return strings.ToUpper(bic)
+}
+
+// mt940Account returns the account number of an account identification written like BIC/account, an account
+// identification without BIC is the account itself
+func mt940Account(account string) string {
+	_, number, ok := strings.Cut(strings.TrimSpace(account), "/")
+	if !ok {
+		return strings.TrimSpace(account)
+	}
+	return number
+}
+
+// mt940Date returns the entry date of a statement line, its year is the one of the value date that is the closest
+// to it because the entry date is written without year. A statement line without entry date is dated with its value
+// date
+func mt940Date(valueDate, entryDate string) (string, error) {
+	value, err := time.Parse("060102", valueDate)
+	if err != nil || entryDate == "" {
+		return formatDate(valueDate, "060102"), nil
+	}
+
+	var result time.Time
+	for year := value.Year() - 1; year <= value.Year()+1; year++ {
+		// the 29th of February only exists in the leap years
+		date, err := time.Parse("20060102", fmt.Sprint(year)+entryDate)
+		if err != nil {
+			continue
+		}
+		if result.IsZero() || date.Sub(value).Abs() < result.Sub(value).Abs() {
+			result = date
+		}
+	}
+	if result.IsZero() {
+		return "", fmt.Errorf("entry date %q is invalid", entryDate)
+	}
+	return result.Format(dateFormat), nil
+}
+
+// mt940BankStatement reads a :61: statement line, sequence identifies it when it has no reference
+func mt940BankStatement(field mt940Field, bank, currency, sequence string) (*transactions.BankStatements, error) {
+	// the supplementary details are written on the next line
+	statementLine, _, _ := strings.Cut(field.value, "\n")
+
+	match := mt940StatementLine.FindStringSubmatch(statementLine)
+	if match == nil {
+		return nil, fmt.Errorf("line %d: statement line %q is invalid", field.line, statementLine)
+	}
+
+	// a reversal of a credit takes the money back like a debit, and the other way around
+	amount := strings.TrimSuffix(match[5], ",")
+	if match[3] == "D" || match[3] == "RC" {
+		amount = "-" + amount
+	}
+	if currency != "" {
+		amount += " " + currency
+	}
+
+	customerReference, bankReference, _ := strings.Cut(match[7], "//")
+	reference := strings.TrimSpace(bankReference)
+	if reference == "" && customerReference != "NONREF" {
+		reference = strings.TrimSpace(customerReference)
+	}
+	if reference == "" {
+		reference = sequence
+	}
+
+	date, err := mt940Date(match[1], match[2])
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", field.line, err)
+	}
+
+	return &transactions.BankStatements{
+		ID:     bank + "_" + reference,
+		Amount: amount,
+		Date:   date,
+		Bank:   bank,
+		Line:   field.line,
+	}, nil
+}
@@ -0,0 +1,207 @@
+package parsers
+
+import (
+	"amartha-test/entities/transactions"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const mt940Statement = `{1:F01CENAIDJAXXXX0000000000}{2:O9401200240114CENAIDJAXXXX00000000002401142300N}{4:
+:20:STMT20240114
+:25:CENAIDJA/0123456789
+:28C:00001/001
+:60F:C240112IDR10000000,00
+:61:2401130113C2000000,00NTRFAMARTHA//FT24013ABC
+:86:TRANSFER DARI AMARTHA
+PT AMARTHA MIKRO FINTEK
+:61:240114D15000,NCHGNONREF
+:86:BIAYA ADM
+:62F:C240114IDR11985000,00
+-}
+`
+
+func TestMT940_Parse(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantResult []*transactions.BankStatements
+		wantErr    bool
+	}{
+		{
+			name:    "Succesful",
+			content: mt940Statement,
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:          "BCA_FT24013ABC",
+					Amount:      "2000000,00 IDR",
+					Date:        "13/01/2024",
+					Bank:        "BCA",
+					Description: "TRANSFER DARI AMARTHA\nPT AMARTHA MIKRO FINTEK",
+					Line:        6,
+				},
+				{
+					ID:          "BCA_0123456789_00001/001_2",
+					Amount:      "-15000 IDR",
+					Date:        "14/01/2024",
+					Bank:        "BCA",
+					Description: "BIAYA ADM",
+					Line:        9,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Succesful account without BIC",
+			content: strings.Replace(mt940Statement, ":25:CENAIDJA/0123456789", ":25:bri", 1),
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:          "BRI_FT24013ABC",
+					Amount:      "2000000,00 IDR",
+					Date:        "13/01/2024",
+					Bank:        "BRI",
+					Description: "TRANSFER DARI AMARTHA\nPT AMARTHA MIKRO FINTEK",
+					Line:        6,
+				},
+				{
+					ID:          "BRI_bri_00001/001_2",
+					Amount:      "-15000 IDR",
+					Date:        "14/01/2024",
+					Bank:        "BRI",
+					Description: "BIAYA ADM",
+					Line:        9,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Succesful dated with the entry date",
+			content: strings.NewReplacer(
+				":61:2401130113C2000000,00NTRFAMARTHA//FT24013ABC", ":61:2401130114C2000000,00NTRFAMARTHA//FT24013ABC",
+				":61:240114D15000,NCHGNONREF", ":61:2312291231D15000,NCHGNONREF",
+			).Replace(mt940Statement),
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:          "BCA_FT24013ABC",
+					Amount:      "2000000,00 IDR",
+					Date:        "14/01/2024",
+					Bank:        "BCA",
+					Description: "TRANSFER DARI AMARTHA\nPT AMARTHA MIKRO FINTEK",
+					Line:        6,
+				},
+				{
+					ID:          "BCA_0123456789_00001/001_2",
+					Amount:      "-15000 IDR",
+					Date:        "31/12/2023",
+					Bank:        "BCA",
+					Description: "BIAYA ADM",
+					Line:        9,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Succesful entry date in the next year",
+			content: strings.Replace(mt940Statement, ":61:240114D15000,NCHGNONREF", ":61:2312310101D15000,NCHGNONREF", 1),
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:          "BCA_FT24013ABC",
+					Amount:      "2000000,00 IDR",
+					Date:        "13/01/2024",
+					Bank:        "BCA",
+					Description: "TRANSFER DARI AMARTHA\nPT AMARTHA MIKRO FINTEK",
+					Line:        6,
+				},
+				{
+					ID:          "BCA_0123456789_00001/001_2",
+					Amount:      "-15000 IDR",
+					Date:        "01/01/2024",
+					Bank:        "BCA",
+					Description: "BIAYA ADM",
+					Line:        9,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Succesful statement without number",
+			content: strings.NewReplacer(
+				":28C:00001/001\n", "",
+				":61:2401130113C2000000,00NTRFAMARTHA//FT24013ABC", ":61:2401130113C2000000,00NTRFNONREF",
+			).Replace(mt940Statement),
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:          "BCA_0123456789_STMT20240114_1",
+					Amount:      "2000000,00 IDR",
+					Date:        "13/01/2024",
+					Bank:        "BCA",
+					Description: "TRANSFER DARI AMARTHA\nPT AMARTHA MIKRO FINTEK",
+					Line:        5,
+				},
+				{
+					ID:          "BCA_0123456789_STMT20240114_2",
+					Amount:      "-15000 IDR",
+					Date:        "14/01/2024",
+					Bank:        "BCA",
+					Description: "BIAYA ADM",
+					Line:        8,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Entry date is invalid",
+			content: strings.Replace(mt940Statement, ":61:240114D15000,NCHGNONREF", ":61:2401141332D15000,NCHGNONREF", 1),
+			wantErr: true,
+		},
+		{
+			name:    "Statement line is invalid",
+			content: strings.Replace(mt940Statement, ":61:240114D15000,NCHGNONREF", ":61:240114X15000,NCHGNONREF", 1),
+			wantErr: true,
+		},
+		{
+			name:    "Opening balance is invalid",
+			content: strings.Replace(mt940Statement, ":60F:C240112IDR10000000,00", ":60F:10000000", 1),
+			wantErr: true,
+		},
+		{
+			name:    "Account identification is missing",
+			content: strings.Replace(mt940Statement, ":25:CENAIDJA/0123456789\n", "", 1),
+			wantErr: true,
+		},
+		{
+			name:    "Empty",
+			content: "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := MT940{}.Parse(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MT940.Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResult, tt.wantResult) {
+				t.Errorf("MT940.Parse() = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestMT940_ParseErrorLine(t *testing.T) {
+	content := strings.Replace(mt940Statement, ":61:240114D15000,NCHGNONREF", ":61:240114X15000,NCHGNONREF", 1)
+	_, err := MT940{}.Parse(strings.NewReader(content))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 9:") {
+		t.Errorf("MT940.Parse() error = %v, want error on line 9", err)
+	}
+}
+
+func TestMT940_Detect(t *testing.T) {
+	if !(MT940{}).Detect([]byte(mt940Statement)) {
+		t.Errorf("MT940.Detect() = false, want true")
+	}
+	if (MT940{}).Detect([]byte(bcaStatement)) {
+		t.Errorf("MT940.Detect() = true, want false")
+	}
+}
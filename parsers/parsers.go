@@ -0,0 +1,145 @@
+package parsers
+
+import (
+	"amartha-test/entities/parsers"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// dateFormat is the layout of the dates returned by every parser, the same layout as the csv format
+const dateFormat = "02/01/2006"
+
+// BankStatementsParsers returns the built-in bank statements parsers by their format name
+func BankStatementsParsers() map[string]parsers.BankStatementsParser {
+	return map[string]parsers.BankStatementsParser{
+		"bca":     BCA{},
+		"bri":     BRI{},
+		"camt":    Camt{},
+		"mandiri": Mandiri{},
+		"mt940":   MT940{},
+	}
+}
+
+// record is one row of a csv export with its line number in the file
+type record struct {
+	line   int
+	fields []string
+}
+
+// field returns the trimmed value of a column, or empty when the row is shorter
+func (r record) field(index int) string {
+	if index >= len(r.fields) {
+		return ""
+	}
+	return strings.TrimSpace(r.fields[index])
+}
+
+// isEmpty reports whether every column of the row is empty, exports use those rows to separate their sections
+func (r record) isEmpty() bool {
+	for index := range r.fields {
+		if r.field(index) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// readRecords reads every row of a csv export, rows can have a different number of columns
+func readRecords(file io.Reader) ([]record, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var records []record
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		records = append(records, record{line: line, fields: fields})
+	}
+
+	return records, nil
+}
+
+// findHeader returns the index of the row that starts with the header columns, or -1 when there is none
+func findHeader(records []record, header []string) int {
+	for index, r := range records {
+		if hasColumns(r, header) {
+			return index
+		}
+	}
+	return -1
+}
+
+func hasColumns(r record, header []string) bool {
+	if len(r.fields) < len(header) {
+		return false
+	}
+	for index, column := range header {
+		if !strings.EqualFold(r.field(index), column) {
+			return false
+		}
+	}
+	return true
+}
+
+// detectHeader reports whether the content has a row that starts with the header columns
+func detectHeader(content []byte, header []string) bool {
+	records, err := readRecords(bytes.NewReader(content))
+	if err != nil {
+		return false
+	}
+	return findHeader(records, header) >= 0
+}
+
+// findValue returns the value after the label of a preamble row written like "Label : ,value"
+func findValue(records []record, label string) string {
+	for _, r := range records {
+		name := strings.TrimSpace(strings.TrimSuffix(r.field(0), ":"))
+		if strings.EqualFold(name, label) {
+			return strings.TrimPrefix(r.field(1), "'")
+		}
+	}
+	return ""
+}
+
+// isZeroAmount reports whether the amount has no digit other than zero, an empty amount is zero
+func isZeroAmount(amount string) bool {
+	for _, char := range amount {
+		if char >= '1' && char <= '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// signedAmount returns the amount of a row that has separate debit and credit columns, debit amounts are negative
+func signedAmount(r record, debit, credit string) (string, error) {
+	switch {
+	case !isZeroAmount(debit) && !isZeroAmount(credit):
+		return "", fmt.Errorf("line %d has both debit and credit amount", r.line)
+	case !isZeroAmount(debit):
+		return "-" + debit, nil
+	default:
+		return credit, nil
+	}
+}
+
+// formatDate rewrites the date to dateFormat, a value that can't be read is returned as written
+func formatDate(value, layout string) string {
+	date, err := time.Parse(layout, value)
+	if err != nil {
+		return value
+	}
+	return date.Format(dateFormat)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"amartha-test/entities/transactions"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// columnProfilesEnv is the environment variable with the path of the JSON file of saved column layouts, written
+// like {"bca_semicolon": {"columns": {"amount": "Nominal"}, "delimiter": ";"}}
+const columnProfilesEnv = "COLUMN_PROFILES_FILE"
+
+// loadColumnProfiles reads the saved column layouts by their name, there is no saved layout when path is empty
+func loadColumnProfiles(path string) (map[string]transactions.ColumnLayout, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles map[string]transactions.ColumnLayout
+	err = json.Unmarshal(content, &profiles)
+	if err != nil {
+		return nil, fmt.Errorf("column profiles file %s is invalid, %s", path, err)
+	}
+	return profiles, nil
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "github.com/lib/pq"  // registers the postgres driver
+	_ "modernc.org/sqlite" // registers the sqlite driver
+)
+
+// database drivers the runs can be stored with
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Open opens the database of the driver at the data source name, a file path for sqlite and a connection
+// string for postgres
+func Open(driver, dataSourceName string) (*sql.DB, error) {
+	switch driver {
+	case DriverSQLite:
+		// the write lock of sqlite is held by one connection at a time
+		db, err := sql.Open(driver, dataSourceName+separator(dataSourceName)+"_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)")
+		if err != nil {
+			return nil, err
+		}
+		db.SetMaxOpenConns(1)
+		return db, nil
+	case DriverPostgres:
+		return sql.Open(driver, dataSourceName)
+	}
+	return nil, fmt.Errorf("database driver %s is not supported, use %s or %s", driver, DriverSQLite, DriverPostgres)
+}
+
+// rebind rewrites the ? placeholders of a query to the $1 placeholders of postgres
+func rebind(driver, query string) string {
+	if driver != DriverPostgres {
+		return query
+	}
+	var result strings.Builder
+	position := 0
+	for _, char := range query {
+		if char != '?' {
+			result.WriteRune(char)
+			continue
+		}
+		position++
+		result.WriteString("$" + strconv.Itoa(position))
+	}
+	return result.String()
+}
+
+// separator returns the separator of the next parameter of a data source name
+func separator(dataSourceName string) string {
+	if strings.Contains(dataSourceName, "?") {
+		return "&"
+	}
+	return "?"
+}
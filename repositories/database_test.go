@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name    string
+		driver  string
+		wantErr bool
+	}{
+		{
+			name:    "Succesful with sqlite",
+			driver:  DriverSQLite,
+			wantErr: false,
+		},
+		{
+			name:    "Failed with unsupported driver",
+			driver:  "mysql",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := Open(tt.driver, filepath.Join(t.TempDir(), "reconciliation.db"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Open() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if db == nil {
+				return
+			}
+			defer db.Close()
+			if err := db.Ping(); err != nil {
+				t.Errorf("Open() database can't be reached, %v", err)
+			}
+		})
+	}
+}
+
+func Test_rebind(t *testing.T) {
+	type args struct {
+		driver string
+		query  string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "Succesful with sqlite",
+			args: args{driver: DriverSQLite, query: "SELECT * FROM runs WHERE id = ? AND status = ?"},
+			want: "SELECT * FROM runs WHERE id = ? AND status = ?",
+		},
+		{
+			name: "Succesful with postgres",
+			args: args{driver: DriverPostgres, query: "SELECT * FROM runs WHERE id = ? AND status = ?"},
+			want: "SELECT * FROM runs WHERE id = $1 AND status = $2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rebind(tt.args.driver, tt.args.query); got != tt.want {
+				t.Errorf("rebind() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package repositories
+
+import (
+	"amartha-test/entities/repositories"
+	"amartha-test/entities/transactions"
+	"amartha-test/money"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// schema of the runs, every matched pair and unmatched item of a run is a row of reconciliation_items so runs can
+// be looked up by the bank sources they reconciled. Dates are written as text so they sort the same on every driver
+const schema = `
+CREATE TABLE IF NOT EXISTS reconciliation_runs (
+	id TEXT PRIMARY KEY,
+	status TEXT NOT NULL,
+	error TEXT NOT NULL,
+	started_at TEXT NOT NULL,
+	finished_at TEXT NOT NULL,
+	period_start TEXT NOT NULL,
+	period_end TEXT NOT NULL,
+	parameters TEXT NOT NULL,
+	checksums TEXT NOT NULL,
+	transactions_proceed BIGINT NOT NULL,
+	matched_transaction BIGINT NOT NULL,
+	unmatched_transaction BIGINT NOT NULL,
+	total_discrepancies TEXT NOT NULL,
+	reporting_currency TEXT NOT NULL,
+	summary TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS reconciliation_runs_period ON reconciliation_runs (period_start, period_end);
+CREATE TABLE IF NOT EXISTS reconciliation_items (
+	run_id TEXT NOT NULL REFERENCES reconciliation_runs (id) ON DELETE CASCADE,
+	kind TEXT NOT NULL,
+	position BIGINT NOT NULL,
+	transaction_id TEXT NOT NULL,
+	unique_identifier TEXT NOT NULL,
+	bank_source TEXT NOT NULL,
+	data TEXT NOT NULL,
+	PRIMARY KEY (run_id, kind, position)
+);
+CREATE INDEX IF NOT EXISTS reconciliation_items_upper_bank_source ON reconciliation_items (UPPER(bank_source), run_id);
+`
+
+// timeLayout is the layout of the timestamps of the runs, written in UTC with a fixed width so they sort as text
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// kinds of the items of a run
+const (
+	itemMatched                  = "matched"
+	itemMatchedWithDifference    = "matched_with_difference"
+	itemMissingBankStatement     = "missing_bank_statement"
+	itemMissingSystemTransaction = "missing_system_transaction"
+)
+
+// items are inserted itemsInsertBatchSize rows a statement
+const (
+	itemsInsertBatchSize = 100
+	itemColumns          = "(run_id, kind, position, transaction_id, unique_identifier, bank_source, data)"
+	itemPlaceholders     = "(?, ?, ?, ?, ?, ?, ?)"
+)
+
+// ReconciliationRepository stores the reconciliation runs in a sqlite or postgres database
+type ReconciliationRepository struct {
+	DB     *sql.DB
+	Driver string // DriverSQLite or DriverPostgres
+}
+
+func NewReconciliationRepository(repository ReconciliationRepository) ReconciliationRepository {
+	return repository
+}
+
+// Migrate creates the tables of the runs when they don't exist
+func (repository ReconciliationRepository) Migrate(ctx context.Context) error {
+	for _, statement := range strings.Split(schema, ";") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		_, err := repository.DB.ExecContext(ctx, statement)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// item is a matched pair or an unmatched item of a run
+type item struct {
+	kind             string
+	transactionID    string
+	uniqueIdentifier string
+	bankSource       string
+	data             interface{}
+}
+
+// runItems returns the matched pairs and unmatched items of the result, the missing bank statements are ordered by
+// bank source
+func runItems(result transactions.DoReconciliationResponse) (items []item) {
+	for _, matched := range result.MatchedTransactions {
+		items = append(items, item{itemMatched, matched.TransactionID, matched.UniqueIdentifier, matched.BankSource, matched})
+	}
+	for _, matched := range result.MatchedWithDifference {
+		items = append(items, item{itemMatchedWithDifference, matched.TransactionID, matched.UniqueIdentifier, matched.BankSource, matched})
+	}
+	bankSources := make([]string, 0, len(result.MissingBankStatements))
+	for bankSource := range result.MissingBankStatements {
+		bankSources = append(bankSources, bankSource)
+	}
+	sort.Strings(bankSources)
+	for _, bankSource := range bankSources {
+		for _, statement := range result.MissingBankStatements[bankSource] {
+			items = append(items, item{itemMissingBankStatement, "", statement.ID, statement.BankSource, statement})
+		}
+	}
+	for _, transaction := range result.MissingSystemTransactions {
+		items = append(items, item{itemMissingSystemTransaction, transaction.TransactionID, "", "", transaction})
+	}
+	return
+}
+
+// SaveRun stores the run, its matched pairs and unmatched items are stored one row each and left out of the summary
+func (repository ReconciliationRepository) SaveRun(ctx context.Context, run transactions.ReconciliationRun) error {
+	summary := run.Result
+	summary.MatchedTransactions, summary.MatchedWithDifference = nil, nil
+	summary.MissingBankStatements, summary.MissingSystemTransactions = nil, nil
+
+	parameters, err := json.Marshal(run.Parameters)
+	if err != nil {
+		return err
+	}
+	checksums, err := json.Marshal(run.Checksums)
+	if err != nil {
+		return err
+	}
+	summaryData, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	tx, err := repository.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, rebind(repository.Driver, `INSERT INTO reconciliation_runs (id, status, error, started_at,
+		finished_at, period_start, period_end, parameters, checksums, transactions_proceed, matched_transaction,
+		unmatched_transaction, total_discrepancies, reporting_currency, summary)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		run.ID, string(run.Status), run.Error, run.StartedAt.UTC().Format(timeLayout),
+		run.FinishedAt.UTC().Format(timeLayout), run.PeriodStart, run.PeriodEnd, string(parameters), string(checksums),
+		run.Result.TransactionsProceed, run.Result.MatchedTransaction, run.Result.UnmatchedTransaction,
+		run.Result.TotalDiscrepancies.String(), run.Result.ReportingCurrency, string(summaryData))
+	if err != nil {
+		return err
+	}
+
+	// items are inserted in batches, a run can have millions of them
+	items := runItems(run.Result)
+	positions := map[string]int{}
+	for start := 0; start < len(items); start += itemsInsertBatchSize {
+		batch := items[start:min(start+itemsInsertBatchSize, len(items))]
+
+		placeholders := make([]string, len(batch))
+		args := make([]interface{}, 0, len(batch)*7)
+		for index, item := range batch {
+			data, err := json.Marshal(item.data)
+			if err != nil {
+				return err
+			}
+			placeholders[index] = itemPlaceholders
+			args = append(args, run.ID, item.kind, positions[item.kind], item.transactionID, item.uniqueIdentifier,
+				item.bankSource, string(data))
+			positions[item.kind]++
+		}
+
+		_, err = tx.ExecContext(ctx, rebind(repository.Driver, "INSERT INTO reconciliation_items "+itemColumns+
+			" VALUES "+strings.Join(placeholders, ", ")), args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// runsFilter returns the conditions of the runs of the filter with their arguments
+func runsFilter(filter transactions.GetReconciliationRunsRequest) (string, []interface{}) {
+	conditions := []string{"1 = 1"}
+	var args []interface{}
+	if !filter.StartDate.IsZero() {
+		conditions = append(conditions, "period_end >= ?")
+		args = append(args, filter.StartDate.Format(transactions.DateRangeFormat))
+	}
+	if !filter.EndDate.IsZero() {
+		// runs that failed before reading any record have no period
+		conditions = append(conditions, "period_start <> '' AND period_start <= ?")
+		args = append(args, filter.EndDate.Format(transactions.DateRangeFormat))
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, string(filter.Status))
+	}
+	if filter.BankSource != "" {
+		// bank sources are stored as they are written in the files, bca_1 is a statement of BCA
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM reconciliation_items
+			WHERE reconciliation_items.run_id = reconciliation_runs.id AND UPPER(reconciliation_items.bank_source) = UPPER(?))`)
+		args = append(args, filter.BankSource)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// GetRuns returns the page of runs of the filter, the most recent first
+func (repository ReconciliationRepository) GetRuns(ctx context.Context, filter transactions.GetReconciliationRunsRequest) (runs []transactions.ReconciliationRunSummary, total int, err error) {
+	conditions, args := runsFilter(filter)
+
+	err = repository.DB.QueryRowContext(ctx, rebind(repository.Driver,
+		"SELECT COUNT(*) FROM reconciliation_runs WHERE "+conditions), args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := repository.DB.QueryContext(ctx, rebind(repository.Driver, `SELECT id, status, error, started_at,
+		finished_at, period_start, period_end, transactions_proceed, matched_transaction, unmatched_transaction,
+		total_discrepancies, reporting_currency FROM reconciliation_runs WHERE `+conditions+`
+		ORDER BY started_at DESC, id DESC LIMIT ? OFFSET ?`),
+		append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	runs = []transactions.ReconciliationRunSummary{}
+	for rows.Next() {
+		var run transactions.ReconciliationRunSummary
+		var status, startedAt, finishedAt, totalDiscrepancies string
+		err = rows.Scan(&run.ID, &status, &run.Error, &startedAt, &finishedAt, &run.PeriodStart, &run.PeriodEnd,
+			&run.TransactionsProceed, &run.MatchedTransaction, &run.UnmatchedTransaction, &totalDiscrepancies,
+			&run.ReportingCurrency)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		run.Status = transactions.RunStatus(status)
+		run.StartedAt, run.FinishedAt, err = parseRunTimes(startedAt, finishedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		run.TotalDiscrepancies, err = money.Parse(totalDiscrepancies, run.ReportingCurrency)
+		if err != nil {
+			return nil, 0, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, total, rows.Err()
+}
+
+// GetRun returns the run with its matched pairs and unmatched items, ErrRunNotFound when there is none
+func (repository ReconciliationRepository) GetRun(ctx context.Context, id string) (run transactions.ReconciliationRun, err error) {
+	var status, startedAt, finishedAt, parameters, checksums, summary string
+	err = repository.DB.QueryRowContext(ctx, rebind(repository.Driver, `SELECT id, status, error, started_at,
+		finished_at, period_start, period_end, parameters, checksums, summary FROM reconciliation_runs WHERE id = ?`), id).
+		Scan(&run.ID, &status, &run.Error, &startedAt, &finishedAt, &run.PeriodStart, &run.PeriodEnd, &parameters,
+			&checksums, &summary)
+	if errors.Is(err, sql.ErrNoRows) {
+		return run, repositories.ErrRunNotFound
+	}
+	if err != nil {
+		return run, err
+	}
+
+	run.Status = transactions.RunStatus(status)
+	run.StartedAt, run.FinishedAt, err = parseRunTimes(startedAt, finishedAt)
+	if err != nil {
+		return run, err
+	}
+	err = errors.Join(
+		json.Unmarshal([]byte(parameters), &run.Parameters),
+		json.Unmarshal([]byte(checksums), &run.Checksums),
+		json.Unmarshal([]byte(summary), &run.Result),
+	)
+	if err != nil {
+		return run, err
+	}
+	// missing bank statements are grouped by bank source even when there are none, a failed run can have
+	// stored items too
+	run.Result.MissingBankStatements = map[string][]transactions.BankStatements{}
+
+	rows, err := repository.DB.QueryContext(ctx, rebind(repository.Driver,
+		"SELECT kind, data FROM reconciliation_items WHERE run_id = ? ORDER BY kind, position"), id)
+	if err != nil {
+		return run, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind, data string
+		err = rows.Scan(&kind, &data)
+		if err != nil {
+			return run, err
+		}
+		err = addRunItem(&run.Result, kind, []byte(data))
+		if err != nil {
+			return run, err
+		}
+	}
+	return run, rows.Err()
+}
+
+// addRunItem adds a stored matched pair or unmatched item to the result of its run
+func addRunItem(result *transactions.DoReconciliationResponse, kind string, data []byte) error {
+	switch kind {
+	case itemMatched, itemMatchedWithDifference:
+		var matched transactions.MatchedTransaction
+		err := json.Unmarshal(data, &matched)
+		if err != nil {
+			return err
+		}
+		if kind == itemMatched {
+			result.MatchedTransactions = append(result.MatchedTransactions, matched)
+		} else {
+			result.MatchedWithDifference = append(result.MatchedWithDifference, matched)
+		}
+	case itemMissingBankStatement:
+		var statement transactions.BankStatements
+		err := json.Unmarshal(data, &statement)
+		if err != nil {
+			return err
+		}
+		result.MissingBankStatements[statement.BankSource] = append(result.MissingBankStatements[statement.BankSource], statement)
+	case itemMissingSystemTransaction:
+		var transaction transactions.SystemTransactions
+		err := json.Unmarshal(data, &transaction)
+		if err != nil {
+			return err
+		}
+		result.MissingSystemTransactions = append(result.MissingSystemTransactions, transaction)
+	}
+	return nil
+}
+
+// parseRunTimes reads when a run started and finished
+func parseRunTimes(startedAt, finishedAt string) (started, finished time.Time, err error) {
+	started, err = time.Parse(timeLayout, startedAt)
+	if err != nil {
+		return
+	}
+	finished, err = time.Parse(timeLayout, finishedAt)
+	return
+}
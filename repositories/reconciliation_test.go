@@ -0,0 +1,333 @@
+package repositories
+
+import (
+	"amartha-test/entities/repositories"
+	"amartha-test/entities/transactions"
+	"amartha-test/money"
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// openTestRepository returns a repository on a new sqlite database with its tables created
+func openTestRepository(t *testing.T) ReconciliationRepository {
+	t.Helper()
+	db, err := Open(DriverSQLite, filepath.Join(t.TempDir(), "reconciliation.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repository := NewReconciliationRepository(ReconciliationRepository{DB: db, Driver: DriverSQLite})
+	// the tables are only created once
+	for i := 0; i < 2; i++ {
+		if err := repository.Migrate(context.Background()); err != nil {
+			t.Fatalf("ReconciliationRepository.Migrate() error = %v", err)
+		}
+	}
+	return repository
+}
+
+// testRun is a run of one matched pair and one unmatched item of each side
+func testRun(id string) transactions.ReconciliationRun {
+	return transactions.ReconciliationRun{
+		ID:          id,
+		Status:      transactions.RunSucceeded,
+		StartedAt:   time.Date(2024, time.Month(1), 4, 9, 0, 0, 0, time.UTC),
+		FinishedAt:  time.Date(2024, time.Month(1), 4, 9, 0, 1, 0, time.UTC),
+		PeriodStart: "2024-01-03",
+		PeriodEnd:   "2024-01-03",
+		Parameters:  transactions.RunParameters{AmountTolerance: "0.00", TimeZone: "Asia/Jakarta"},
+		Checksums:   []transactions.FileChecksum{{File: "bank_statements", Name: "bri.csv", SHA256: "ba7816bf"}},
+		Result: transactions.DoReconciliationResponse{
+			RunID:                id,
+			TransactionsProceed:  2,
+			MatchedTransaction:   1,
+			UnmatchedTransaction: 2,
+			MatchedTransactions: []transactions.MatchedTransaction{
+				{
+					TransactionID:    "1",
+					UniqueIdentifier: "BRI_1",
+					BankSource:       "BRI",
+					Type:             transactions.CREDIT,
+					Amount:           money.MustParse("2000000", money.DefaultCurrency),
+					BankAmount:       money.MustParse("2000000", money.DefaultCurrency),
+					Difference:       money.MustParse("0", money.DefaultCurrency),
+					TransactionTime:  "03/01/2024 08:20:00",
+					Date:             "03/01/2024",
+				},
+			},
+			MissingBankStatements: map[string][]transactions.BankStatements{
+				"BRI": {{ID: "BRI_2", Amount: "Rp1,000", Currency: money.DefaultCurrency, Date: "03/01/2024", BankSource: "BRI"}},
+			},
+			MissingSystemTransactions: []transactions.SystemTransactions{
+				{TransactionID: "2", Amount: "Rp3,000", Currency: money.DefaultCurrency, Type: transactions.DEBIT, TransactionTime: "03/01/2024 10:00:00"},
+			},
+			TotalDiscrepancies: money.MustParse("0", money.DefaultCurrency),
+			ReportingCurrency:  money.DefaultCurrency,
+			TimeZone:           "Asia/Jakarta",
+		},
+	}
+}
+
+func TestReconciliationRepository_SaveRun(t *testing.T) {
+	repository := openTestRepository(t)
+
+	tests := []struct {
+		name      string
+		run       transactions.ReconciliationRun
+		wantItems []string
+		wantErr   bool
+	}{
+		{
+			name:      "Succesful",
+			run:       testRun("run-1"),
+			wantItems: []string{"matched BRI", "missing_bank_statement BRI", "missing_system_transaction "},
+			wantErr:   false,
+		},
+		{
+			name:    "Failed with a stored run id",
+			run:     testRun("run-1"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repository.SaveRun(context.Background(), tt.run)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReconciliationRepository.SaveRun() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var status, periodStart, totalDiscrepancies string
+			var matched int
+			err = repository.DB.QueryRow(`SELECT status, period_start, matched_transaction, total_discrepancies
+				FROM reconciliation_runs WHERE id = ?`, tt.run.ID).Scan(&status, &periodStart, &matched, &totalDiscrepancies)
+			if err != nil {
+				t.Fatalf("run is not stored, %v", err)
+			}
+			if status != "succeeded" || periodStart != "2024-01-03" || matched != 1 || totalDiscrepancies != "0.00" {
+				t.Errorf("stored run = %v %v %v %v", status, periodStart, matched, totalDiscrepancies)
+			}
+
+			rows, err := repository.DB.Query(`SELECT kind, bank_source FROM reconciliation_items WHERE run_id = ?
+				ORDER BY kind, position`, tt.run.ID)
+			if err != nil {
+				t.Fatalf("items can't be read, %v", err)
+			}
+			defer rows.Close()
+			var items []string
+			for rows.Next() {
+				var kind, bankSource string
+				if err := rows.Scan(&kind, &bankSource); err != nil {
+					t.Fatalf("items can't be read, %v", err)
+				}
+				items = append(items, kind+" "+bankSource)
+			}
+			if !reflect.DeepEqual(items, tt.wantItems) {
+				t.Errorf("stored items = %v, want %v", items, tt.wantItems)
+			}
+		})
+	}
+}
+
+func BenchmarkReconciliationRepository_SaveRun(b *testing.B) {
+	db, err := Open(DriverSQLite, filepath.Join(b.TempDir(), "reconciliation.db"))
+	if err != nil {
+		b.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+	repository := NewReconciliationRepository(ReconciliationRepository{DB: db, Driver: DriverSQLite})
+	if err := repository.Migrate(context.Background()); err != nil {
+		b.Fatalf("ReconciliationRepository.Migrate() error = %v", err)
+	}
+
+	run := testRun("")
+	matched := run.Result.MatchedTransactions[0]
+	run.Result.MatchedTransactions = nil
+	for i := 0; i < 100000; i++ {
+		run.Result.MatchedTransactions = append(run.Result.MatchedTransactions, matched)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		run.ID = time.Now().Format(time.RFC3339Nano)
+		if err := repository.SaveRun(context.Background(), run); err != nil {
+			b.Fatalf("ReconciliationRepository.SaveRun() error = %v", err)
+		}
+	}
+}
+
+func TestReconciliationRepository_GetRuns(t *testing.T) {
+	repository := openTestRepository(t)
+
+	// runs of BRI on 3 January, of BCA from 4 to 5 January and a run that failed before reading any record
+	bri := testRun("run-bri")
+	bca := testRun("run-bca")
+	bca.StartedAt = bca.StartedAt.Add(time.Hour)
+	bca.PeriodStart, bca.PeriodEnd = "2024-01-04", "2024-01-05"
+	bca.Result.MatchedTransactions[0].BankSource = "bca" // stored as written in the file
+	bca.Result.MissingBankStatements = nil
+	failed := transactions.ReconciliationRun{
+		ID:        "run-failed",
+		Status:    transactions.RunFailed,
+		Error:     "bank statements data is empty",
+		StartedAt: bca.StartedAt.Add(time.Hour),
+	}
+	for _, run := range []transactions.ReconciliationRun{bri, bca, failed} {
+		if err := repository.SaveRun(context.Background(), run); err != nil {
+			t.Fatalf("ReconciliationRepository.SaveRun() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		filter    transactions.GetReconciliationRunsRequest
+		wantRuns  []string
+		wantTotal int
+	}{
+		{
+			name:      "Succesful with every run",
+			filter:    transactions.GetReconciliationRunsRequest{Page: 1, PageSize: 20},
+			wantRuns:  []string{"run-failed", "run-bca", "run-bri"},
+			wantTotal: 3,
+		},
+		{
+			name:      "Succesful with page",
+			filter:    transactions.GetReconciliationRunsRequest{Page: 2, PageSize: 2},
+			wantRuns:  []string{"run-bri"},
+			wantTotal: 3,
+		},
+		{
+			name: "Succesful with bank source on a day",
+			filter: transactions.GetReconciliationRunsRequest{
+				StartDate:  time.Date(2024, time.Month(1), 3, 0, 0, 0, 0, time.UTC),
+				EndDate:    time.Date(2024, time.Month(1), 3, 0, 0, 0, 0, time.UTC),
+				BankSource: "BRI",
+				Page:       1,
+				PageSize:   20,
+			},
+			wantRuns:  []string{"run-bri"},
+			wantTotal: 1,
+		},
+		{
+			name:      "Succesful with bank source in another case",
+			filter:    transactions.GetReconciliationRunsRequest{BankSource: "BCA", Page: 1, PageSize: 20},
+			wantRuns:  []string{"run-bca"},
+			wantTotal: 1,
+		},
+		{
+			name: "Succesful with date range inside the period",
+			filter: transactions.GetReconciliationRunsRequest{
+				EndDate:  time.Date(2024, time.Month(1), 4, 0, 0, 0, 0, time.UTC),
+				Page:     1,
+				PageSize: 20,
+			},
+			wantRuns:  []string{"run-bca", "run-bri"},
+			wantTotal: 2,
+		},
+		{
+			name:      "Succesful with status",
+			filter:    transactions.GetReconciliationRunsRequest{Status: transactions.RunFailed, Page: 1, PageSize: 20},
+			wantRuns:  []string{"run-failed"},
+			wantTotal: 1,
+		},
+		{
+			name:      "Succesful without runs",
+			filter:    transactions.GetReconciliationRunsRequest{BankSource: "MANDIRI", Page: 1, PageSize: 20},
+			wantRuns:  nil,
+			wantTotal: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, total, err := repository.GetRuns(context.Background(), tt.filter)
+			if err != nil {
+				t.Errorf("ReconciliationRepository.GetRuns() error = %v", err)
+				return
+			}
+			var ids []string
+			for _, run := range runs {
+				ids = append(ids, run.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantRuns) || total != tt.wantTotal {
+				t.Errorf("ReconciliationRepository.GetRuns() = %v, %v, want %v, %v", ids, total, tt.wantRuns, tt.wantTotal)
+			}
+		})
+	}
+
+	runs, _, _ := repository.GetRuns(context.Background(), transactions.GetReconciliationRunsRequest{BankSource: "BRI", Page: 1, PageSize: 1})
+	want := transactions.ReconciliationRunSummary{
+		ID:                   "run-bri",
+		Status:               transactions.RunSucceeded,
+		StartedAt:            bri.StartedAt,
+		FinishedAt:           bri.FinishedAt,
+		PeriodStart:          "2024-01-03",
+		PeriodEnd:            "2024-01-03",
+		TransactionsProceed:  2,
+		MatchedTransaction:   1,
+		UnmatchedTransaction: 2,
+		TotalDiscrepancies:   money.MustParse("0", money.DefaultCurrency),
+		ReportingCurrency:    money.DefaultCurrency,
+	}
+	if !reflect.DeepEqual(runs, []transactions.ReconciliationRunSummary{want}) {
+		t.Errorf("ReconciliationRepository.GetRuns() = %v, want %v", runs, want)
+	}
+}
+
+func TestReconciliationRepository_GetRun(t *testing.T) {
+	repository := openTestRepository(t)
+	run := testRun("run-1")
+	// a run canceled after its items were reconciled
+	failed := testRun("run-failed")
+	failed.Status, failed.Error = transactions.RunFailed, "reconciliation was canceled"
+	for _, run := range []transactions.ReconciliationRun{run, failed} {
+		if err := repository.SaveRun(context.Background(), run); err != nil {
+			t.Fatalf("ReconciliationRepository.SaveRun() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		want    transactions.ReconciliationRun
+		wantErr error
+	}{
+		{
+			name:    "Succesful",
+			id:      "run-1",
+			want:    run,
+			wantErr: nil,
+		},
+		{
+			name:    "Succesful failed run",
+			id:      "run-failed",
+			want:    failed,
+			wantErr: nil,
+		},
+		{
+			name:    "Failed with unknown id",
+			id:      "run-2",
+			want:    transactions.ReconciliationRun{},
+			wantErr: repositories.ErrRunNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repository.GetRun(context.Background(), tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReconciliationRepository.GetRun() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReconciliationRepository.GetRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
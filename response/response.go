@@ -24,4 +24,10 @@ func WriteJSONResponse(w http.ResponseWriter, status int, data interface{}) (int
 func SetOK(w http.ResponseWriter, data interface{}) (err error) {
 	_, err = WriteJSONResponse(w, http.StatusOK, data)
 	return
-}
\ No newline at end of file
+}
+
+// SetAccepted writes the data of a request that is processed in the background
+func SetAccepted(w http.ResponseWriter, data interface{}) (err error) {
+	_, err = WriteJSONResponse(w, http.StatusAccepted, data)
+	return
+}
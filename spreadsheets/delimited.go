@@ -0,0 +1,210 @@
+package spreadsheets
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Layout describes a delimited text file that is not written like the default csv
+type Layout struct {
+	Columns    map[string]string // header of the file by the header it is renamed to, or its 1-based position when Headerless
+	Headerless bool              // the file has no header row, every renamed column is read by its position
+	Delimiter  rune              // separator of the columns
+	Quote      rune              // character that quotes values that contain the delimiter, doubled inside a quoted value
+}
+
+// RemapColumns rewrites a delimited text file to out as csv separated by comma with the columns renamed by the
+// layout, columns that are not renamed keep their header. A headerless file only keeps the columns of the layout
+// under a new header. The file is read one row at a time, rows maps every line of the csv to the line of its row
+// in the file
+func RemapColumns(file io.Reader, out io.Writer, layout Layout) (rows []int, err error) {
+	writer := csv.NewWriter(out)
+
+	// position of each column of the csv in the file
+	var positions []int
+	if layout.Headerless {
+		var header []string
+		header, positions, err = positionColumns(layout.Columns)
+		if err != nil {
+			return nil, err
+		}
+		err = writer.Write(header)
+		if err != nil {
+			return nil, err
+		}
+		// a headerless file has no line for the header of the csv
+		rows = append(rows, 0)
+	}
+
+	err = readDelimited(file, layout.Delimiter, layout.Quote, func(record []string, line int) error {
+		if positions == nil {
+			header, headerPositions, err := renameColumns(record, layout.Columns)
+			if err != nil {
+				return err
+			}
+			positions = headerPositions
+			rows = append(rows, line)
+			return writer.Write(header)
+		}
+
+		values := make([]string, len(positions))
+		for column, position := range positions {
+			if position < len(record) {
+				values[column] = record[position]
+			}
+		}
+		rows = append(rows, line)
+		return writer.Write(values)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if positions == nil {
+		return nil, errors.New("header is not found")
+	}
+
+	writer.Flush()
+	return rows, writer.Error()
+}
+
+// renameColumns renames the columns of the header of the file, every header of the layout must be in the file
+func renameColumns(fileHeader []string, columns map[string]string) (header []string, positions []int, err error) {
+	renamed := map[string]string{}
+	for name, column := range columns {
+		renamed[strings.TrimSpace(column)] = name
+	}
+
+	found := map[string]bool{}
+	for position, column := range fileHeader {
+		column = strings.TrimSpace(column)
+		if name, ok := renamed[column]; ok {
+			found[column] = true
+			column = name
+		}
+		header = append(header, column)
+		positions = append(positions, position)
+	}
+
+	for column := range renamed {
+		if !found[column] {
+			return nil, nil, fmt.Errorf("column %s is not found in the header", column)
+		}
+	}
+	return header, positions, nil
+}
+
+// positionColumns returns the columns of a headerless file ordered by their position
+func positionColumns(columns map[string]string) (header []string, positions []int, err error) {
+	if len(columns) == 0 {
+		return nil, nil, errors.New("columns of a file without header must be mapped to their position")
+	}
+	for name := range columns {
+		header = append(header, name)
+	}
+	sort.Strings(header)
+
+	for _, name := range header {
+		position, err := strconv.Atoi(strings.TrimSpace(columns[name]))
+		if err != nil || position < 1 {
+			return nil, nil, fmt.Errorf("position of column %s must be a number from 1", name)
+		}
+		positions = append(positions, position-1)
+	}
+	return header, positions, nil
+}
+
+// readDelimited reads every row of a delimited text file into add with the line it starts on, a quoted value can
+// contain the delimiter, line breaks and the quote character written twice. Empty lines are skipped
+func readDelimited(file io.Reader, delimiter, quote rune, add func(record []string, line int) error) error {
+	reader := bufio.NewReader(file)
+	line := 1
+
+	var record []string
+	var value strings.Builder
+	quoted := false
+	start := 0
+	empty := true
+
+	// endRecord adds the row that ends on the current line unless the line is empty
+	endRecord := func() error {
+		last := strings.TrimSuffix(value.String(), "\r")
+		startLine := max(start, 1)
+		isEmpty := empty && last == ""
+		fields := append(record, last)
+		record, start, empty = nil, 0, true
+		value.Reset()
+
+		if isEmpty {
+			return nil
+		}
+		return add(fields, startLine)
+	}
+
+	for {
+		char, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case quoted && char == quote:
+			// a doubled quote is a quote inside the value
+			next, _, err := reader.ReadRune()
+			if err == nil && next == quote {
+				value.WriteRune(quote)
+				continue
+			}
+			if err == nil {
+				reader.UnreadRune()
+			}
+			quoted = false
+
+		case quoted:
+			if char == '\n' {
+				line++
+			}
+			value.WriteRune(char)
+
+		case char == quote && value.Len() == 0:
+			quoted, empty = true, false
+			if start == 0 {
+				start = line
+			}
+
+		case char == delimiter:
+			record = append(record, value.String())
+			value.Reset()
+			empty = false
+			if start == 0 {
+				start = line
+			}
+
+		case char == '\n':
+			err = endRecord()
+			if err != nil {
+				return err
+			}
+			line++
+
+		default:
+			value.WriteRune(char)
+			if start == 0 {
+				start = line
+			}
+		}
+	}
+
+	if quoted {
+		return fmt.Errorf("quoted value starting on line %d is not closed", start)
+	}
+	return endRecord()
+}
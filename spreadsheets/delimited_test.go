@@ -0,0 +1,101 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRemapColumns(t *testing.T) {
+	type args struct {
+		content string
+		layout  Layout
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantContent string
+		wantRows    []int
+		wantErr     bool
+	}{
+		{
+			name: "Succesful renamed headers",
+			args: args{
+				content: "ID Transaksi;Nominal;Tanggal\n\nBCA_12345;'Rp1.500.000;00';01/01/2024\nBCA_12346;'it''s ;\nmultiline';02/01/2024\n",
+				layout: Layout{
+					Columns:   map[string]string{"unique_identifier": "ID Transaksi", "amount": "Nominal", "date": "Tanggal"},
+					Delimiter: ';',
+					Quote:     '\'',
+				},
+			},
+			wantContent: "unique_identifier,amount,date\nBCA_12345,Rp1.500.000;00,01/01/2024\nBCA_12346,\"it's ;\nmultiline\",02/01/2024\n",
+			wantRows:    []int{1, 3, 4},
+			wantErr:     false,
+		},
+		{
+			name: "Succesful headerless",
+			args: args{
+				content: "x\t01/01/2024\tBCA_12345\t1500000\r\nx\t02/01/2024\tBCA_12346\r\n",
+				layout: Layout{
+					Columns:    map[string]string{"unique_identifier": "3", "amount": "4", "date": "2"},
+					Headerless: true,
+					Delimiter:  '\t',
+					Quote:      '"',
+				},
+			},
+			wantContent: "amount,date,unique_identifier\n1500000,01/01/2024,BCA_12345\n,02/01/2024,BCA_12346\n",
+			wantRows:    []int{0, 1, 2},
+			wantErr:     false,
+		},
+		{
+			name: "Header is not found",
+			args: args{
+				content: "ID Transaksi;Nominal\nBCA_12345;1500000",
+				layout: Layout{
+					Columns:   map[string]string{"date": "Tanggal"},
+					Delimiter: ';',
+					Quote:     '"',
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Position is invalid",
+			args: args{
+				content: "BCA_12345,1500000",
+				layout: Layout{
+					Columns:    map[string]string{"amount": "0"},
+					Headerless: true,
+					Delimiter:  ',',
+					Quote:      '"',
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Quote is not closed",
+			args: args{
+				content: "amount\n\"1500000",
+				layout:  Layout{Delimiter: ',', Quote: '"'},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var content bytes.Buffer
+			gotRows, err := RemapColumns(strings.NewReader(tt.args.content), &content, tt.args.layout)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RemapColumns() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if content.String() != tt.wantContent {
+				t.Errorf("RemapColumns() content = %q, want %q", content.String(), tt.wantContent)
+			}
+			if !reflect.DeepEqual(gotRows, tt.wantRows) {
+				t.Errorf("RemapColumns() rows = %v, want %v", gotRows, tt.wantRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxSignature starts every xlsx workbook, a workbook is a zip archive
+var xlsxSignature = []byte("PK\x03\x04")
+
+// IsXLSX reports whether the beginning of a file is the beginning of an xlsx workbook
+func IsXLSX(head []byte) bool {
+	return bytes.HasPrefix(head, xlsxSignature)
+}
+
+// ToCSV writes the rows of a sheet of an xlsx workbook as csv, from the header row to the last row. The first
+// sheet is read when sheet is empty and the header is the first row when headerRow is 0. Empty rows are left
+// out, rows maps every line of the csv to its row number in the sheet so errors can point to the sheet. Cells
+// formatted as dates are written with formatDate, or as formatted in the sheet when formatDate is nil
+func ToCSV(file io.Reader, sheet string, headerRow int, formatDate func(time.Time) string) (content []byte, rows []int, err error) {
+	workbook, err := excelize.OpenReader(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("workbook can't be opened, %s", err)
+	}
+	defer workbook.Close()
+
+	if sheet == "" {
+		sheet = workbook.GetSheetName(0)
+	}
+	if index, _ := workbook.GetSheetIndex(sheet); index < 0 {
+		return nil, nil, fmt.Errorf("sheet %s is not found", sheet)
+	}
+
+	// cells are read as formatted in the sheet, for example 2,000,000.00 or 13/01/2024
+	sheetRows, err := workbook.GetRows(sheet)
+	if err != nil {
+		return nil, nil, err
+	}
+	if formatDate != nil {
+		err = formatDates(workbook, sheet, sheetRows, formatDate)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if headerRow <= 0 {
+		headerRow = 1
+	}
+	if headerRow > len(sheetRows) || isEmptyRow(sheetRows[headerRow-1]) {
+		return nil, nil, fmt.Errorf("header row %d of sheet %s is empty", headerRow, sheet)
+	}
+
+	// trailing empty cells are not returned, every row is padded to the widest row so it has every column
+	width := 0
+	for _, row := range sheetRows[headerRow-1:] {
+		width = max(width, len(row))
+	}
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	for index := headerRow - 1; index < len(sheetRows); index++ {
+		row := sheetRows[index]
+		if isEmptyRow(row) {
+			continue
+		}
+
+		record := make([]string, width)
+		copy(record, row)
+		err = writer.Write(record)
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, index+1)
+	}
+	writer.Flush()
+
+	return buffer.Bytes(), rows, writer.Error()
+}
+
+// formatDates replaces the cells of the rows formatted as dates with their date written by formatDate. A date cell
+// is a number formatted with the display format of the sheet, like 1/13/24 08:45 for the US locale
+func formatDates(workbook *excelize.File, sheet string, sheetRows [][]string, formatDate func(time.Time) string) error {
+	rawRows, err := workbook.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return err
+	}
+	properties, err := workbook.GetWorkbookProps()
+	if err != nil {
+		return err
+	}
+	date1904 := properties.Date1904 != nil && *properties.Date1904
+
+	dateStyles := map[int]bool{}
+	for rowIndex, row := range sheetRows {
+		for columnIndex := range row {
+			if rowIndex >= len(rawRows) || columnIndex >= len(rawRows[rowIndex]) {
+				continue
+			}
+			raw := rawRows[rowIndex][columnIndex]
+			serial, err := strconv.ParseFloat(raw, 64)
+			if err != nil || raw == row[columnIndex] {
+				continue
+			}
+
+			cell, _ := excelize.CoordinatesToCellName(columnIndex+1, rowIndex+1)
+			style, err := workbook.GetCellStyle(sheet, cell)
+			if err != nil {
+				return err
+			}
+			isDate, ok := dateStyles[style]
+			if !ok {
+				isDate, err = isDateStyle(workbook, style)
+				if err != nil {
+					return err
+				}
+				dateStyles[style] = isDate
+			}
+			if !isDate {
+				continue
+			}
+
+			date, err := excelize.ExcelDateToTime(serial, date1904)
+			if err != nil {
+				return fmt.Errorf("cell %s is not a date, %s", cell, err)
+			}
+			row[columnIndex] = formatDate(date)
+		}
+	}
+	return nil
+}
+
+// isDateStyle reports whether the number format of the style writes dates, either a built in date format or a
+// custom format with days, years, hours or seconds
+func isDateStyle(workbook *excelize.File, index int) (bool, error) {
+	style, err := workbook.GetStyle(index)
+	if err != nil {
+		return false, err
+	}
+	if style.CustomNumFmt != nil {
+		return isDateFormatCode(*style.CustomNumFmt), nil
+	}
+	switch {
+	case style.NumFmt >= 14 && style.NumFmt <= 22, style.NumFmt >= 27 && style.NumFmt <= 36,
+		style.NumFmt >= 45 && style.NumFmt <= 47, style.NumFmt >= 50 && style.NumFmt <= 58:
+		return true, nil
+	}
+	return false, nil
+}
+
+// isDateFormatCode reports whether the number format code has date or time tokens outside its quoted text and
+// bracketed sections like [Red] or [$-409]
+func isDateFormatCode(code string) bool {
+	inQuote, inBracket, escaped := false, false, false
+	for _, char := range strings.ToLower(code) {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuote:
+			inQuote = char != '"'
+		case inBracket:
+			inBracket = char != ']'
+		case char == '\\':
+			escaped = true
+		case char == '"':
+			inQuote = true
+		case char == '[':
+			inBracket = true
+		case char == 'y' || char == 'd' || char == 'h' || char == 's':
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// newWorkbook writes a workbook with the rows in the sheet, starting from cell A1
+func newWorkbook(t *testing.T, sheet string, rows [][]interface{}) []byte {
+	workbook := excelize.NewFile()
+	defer workbook.Close()
+
+	if sheet != "Sheet1" {
+		_, err := workbook.NewSheet(sheet)
+		if err != nil {
+			t.Fatalf("Failed to create sheet: %v", err)
+		}
+	}
+	for index, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, index+1)
+		err := workbook.SetSheetRow(sheet, cell, &row)
+		if err != nil {
+			t.Fatalf("Failed to write row: %v", err)
+		}
+	}
+
+	buffer, err := workbook.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("Failed to write workbook: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func TestToCSV(t *testing.T) {
+	statements := newWorkbook(t, "Mutasi", [][]interface{}{
+		{"MUTASI REKENING"},
+		{},
+		{"unique_identifier", "amount", "date"},
+		{"BCA_12345", "Rp1,500,000", "01/01/2024"},
+		{},
+		{"BCA_12346", 2500000},
+	})
+
+	type args struct {
+		content    []byte
+		sheet      string
+		headerRow  int
+		formatDate func(time.Time) string
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantContent string
+		wantRows    []int
+		wantErr     bool
+	}{
+		{
+			name: "Succesful",
+			args: args{
+				content:   statements,
+				sheet:     "Mutasi",
+				headerRow: 3,
+			},
+			wantContent: "unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024\nBCA_12346,2500000,\n",
+			wantRows:    []int{3, 4, 6},
+			wantErr:     false,
+		},
+		{
+			name: "Succesful first sheet",
+			args: args{
+				content: newWorkbook(t, "Sheet1", [][]interface{}{
+					{"trxID", "amount", "type", "transactionTime"},
+					{"1", "Rp8,500,000", 2, "01/01/2024 8:45:00"},
+				}),
+			},
+			wantContent: "trxID,amount,type,transactionTime\n1,\"Rp8,500,000\",2,01/01/2024 8:45:00\n",
+			wantRows:    []int{1, 2},
+			wantErr:     false,
+		},
+		{
+			name: "Succesful with date cells",
+			args: args{
+				content: newWorkbook(t, "Sheet1", [][]interface{}{
+					{"trxID", "amount", "type", "transactionTime"},
+					{"1", 8500000, 2, time.Date(2024, time.Month(1), 13, 8, 45, 0, 0, time.UTC)},
+				}),
+				formatDate: func(date time.Time) string {
+					return date.Format("02/01/2006 15:04:05")
+				},
+			},
+			wantContent: "trxID,amount,type,transactionTime\n1,8500000,2,13/01/2024 08:45:00\n",
+			wantRows:    []int{1, 2},
+			wantErr:     false,
+		},
+		{
+			name: "Succesful with date cells as formatted",
+			args: args{
+				content: newWorkbook(t, "Sheet1", [][]interface{}{
+					{"trxID", "transactionTime"},
+					{"1", time.Date(2024, time.Month(1), 13, 8, 45, 0, 0, time.UTC)},
+				}),
+			},
+			wantContent: "trxID,transactionTime\n1,1/13/24 08:45\n",
+			wantRows:    []int{1, 2},
+			wantErr:     false,
+		},
+		{
+			name: "Sheet is not found",
+			args: args{
+				content: statements,
+				sheet:   "Sheet2",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Header row is empty",
+			args: args{
+				content:   statements,
+				sheet:     "Mutasi",
+				headerRow: 2,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Not a workbook",
+			args: args{
+				content: []byte("unique_identifier,amount,date"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotContent, gotRows, err := ToCSV(bytes.NewReader(tt.args.content), tt.args.sheet, tt.args.headerRow, tt.args.formatDate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ToCSV() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(gotContent) != tt.wantContent {
+				t.Errorf("ToCSV() content = %q, want %q", gotContent, tt.wantContent)
+			}
+			if !reflect.DeepEqual(gotRows, tt.wantRows) {
+				t.Errorf("ToCSV() rows = %v, want %v", gotRows, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestIsXLSX(t *testing.T) {
+	if !IsXLSX(newWorkbook(t, "Sheet1", nil)) {
+		t.Errorf("IsXLSX() = false, want true")
+	}
+	if IsXLSX([]byte("unique_identifier,amount,date")) {
+		t.Errorf("IsXLSX() = true, want false")
+	}
+}
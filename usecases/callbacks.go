@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"amartha-test/entities/transactions"
+	libError "amartha-test/errors"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// defaults of the callbacks of a JobUsecase
+const (
+	defaultCallbackAttempts = 5
+	defaultCallbackBackoff  = time.Second
+	defaultCallbackTimeout  = 10 * time.Second
+)
+
+// CallbackSignatureHeader is the header with the HMAC-SHA256 of the body of a callback signed with the callback
+// secret, hex encoded after sha256=
+const CallbackSignatureHeader = "X-Reconciliation-Signature"
+
+// validateCallbackURL checks the callback url of a job can be notified
+func (usecase JobUsecase) validateCallbackURL(callbackURL string) error {
+	if usecase.CallbackSecret == "" {
+		return libError.NewBadRequestError("callback_url is not available, the callback secret is not set")
+	}
+
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return libError.NewBadRequestError("callback_url must be an http or https URL")
+	}
+	return nil
+}
+
+// SignCallback returns the value of the CallbackSignatureHeader of the body
+func SignCallback(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// notify sends the callback of the finished job in the background, attempts that fail are retried with an
+// exponential backoff until ctx is done
+func (usecase JobUsecase) notify(ctx context.Context, j *job) {
+	usecase.jobs.mutex.Lock()
+	if j.Callback == nil {
+		usecase.jobs.mutex.Unlock()
+		return
+	}
+	callbackURL := j.Callback.URL
+	payload := transactions.NewJobCallbackPayload(j.ReconciliationJob)
+	usecase.jobs.mutex.Unlock()
+
+	usecase.jobs.callbacks.Add(1)
+	go func() {
+		defer usecase.jobs.callbacks.Done()
+
+		body, err := json.Marshal(payload)
+		if err != nil {
+			usecase.updateCallback(j, 0, err, true)
+			return
+		}
+
+		backoff := usecase.CallbackBackoff
+		for attempt := 1; ; attempt++ {
+			err = usecase.sendCallback(ctx, callbackURL, body)
+			last := err == nil || attempt == usecase.CallbackAttempts || ctx.Err() != nil
+			usecase.updateCallback(j, attempt, err, last)
+			if last {
+				return
+			}
+
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				usecase.updateCallback(j, attempt, err, true)
+				return
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+	}()
+}
+
+// sendCallback posts the signed body to the callback url, a response that is not 2xx is an error. An attempt that
+// started is not canceled with ctx so a job canceled on shutdown is still notified once
+func (usecase JobUsecase) sendCallback(ctx context.Context, callbackURL string, body []byte) error {
+	request, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(CallbackSignatureHeader, SignCallback(usecase.CallbackSecret, body))
+
+	response, err := usecase.CallbackClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("callback responded with %s", response.Status)
+	}
+	return nil
+}
+
+// updateCallback records an attempt of the callback of the job, the delivery is finished with the last attempt
+func (usecase JobUsecase) updateCallback(j *job, attempts int, err error, last bool) {
+	usecase.update(j, func(current *transactions.ReconciliationJob) {
+		current.Callback.Attempts, current.Callback.Error = attempts, ""
+		if err != nil {
+			current.Callback.Error = err.Error()
+		}
+		switch {
+		case err == nil:
+			current.Callback.Status = transactions.CallbackDelivered
+		case last:
+			current.Callback.Status = transactions.CallbackFailed
+		}
+	})
+}
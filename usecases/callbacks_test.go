@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"amartha-test/entities/transactions"
+	libError "amartha-test/errors"
+	"amartha-test/money"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJobUsecase_validateCallbackURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		usecase     JobUsecase
+		callbackURL string
+		wantErr     error
+	}{
+		{
+			name:        "Succesful",
+			usecase:     JobUsecase{CallbackSecret: "secret"},
+			callbackURL: "https://ledger.example.com/reconciliations",
+			wantErr:     nil,
+		},
+		{
+			name:        "Failed without secret",
+			usecase:     JobUsecase{},
+			callbackURL: "https://ledger.example.com/reconciliations",
+			wantErr:     libError.NewBadRequestError("callback_url is not available, the callback secret is not set"),
+		},
+		{
+			name:        "Failed with another scheme",
+			usecase:     JobUsecase{CallbackSecret: "secret"},
+			callbackURL: "ftp://ledger.example.com/reconciliations",
+			wantErr:     libError.NewBadRequestError("callback_url must be an http or https URL"),
+		},
+		{
+			name:        "Failed without host",
+			usecase:     JobUsecase{CallbackSecret: "secret"},
+			callbackURL: "/reconciliations",
+			wantErr:     libError.NewBadRequestError("callback_url must be an http or https URL"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.usecase.validateCallbackURL(tt.callbackURL); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("JobUsecase.validateCallbackURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJobUsecase_notify(t *testing.T) {
+	finishedAt := time.Date(2024, time.Month(1), 4, 9, 0, 0, 0, time.UTC)
+	summary := transactions.ReconciliationSummary{
+		MatchedTransaction:   2,
+		UnmatchedTransaction: 1,
+		TotalDiscrepancies:   money.MustParse("500", money.DefaultCurrency),
+	}
+
+	tests := []struct {
+		name         string
+		statusCodes  []int // responses of the receiver, one for every attempt
+		want         transactions.JobCallback
+		wantAttempts int
+	}{
+		{
+			name:        "Succesful after a failed attempt",
+			statusCodes: []int{http.StatusInternalServerError, http.StatusOK},
+			want: transactions.JobCallback{
+				Status:   transactions.CallbackDelivered,
+				Attempts: 2,
+			},
+		},
+		{
+			name:        "Failed after every attempt",
+			statusCodes: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			want: transactions.JobCallback{
+				Status:   transactions.CallbackFailed,
+				Attempts: 3,
+				Error:    "callback responded with 502 Bad Gateway",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mutex sync.Mutex
+			var payloads []transactions.JobCallbackPayload
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if got := r.Header.Get(CallbackSignatureHeader); got != SignCallback("secret", body) {
+					t.Errorf("callback signature = %v, want %v", got, SignCallback("secret", body))
+				}
+
+				var payload transactions.JobCallbackPayload
+				err := json.Unmarshal(body, &payload)
+				if err != nil {
+					t.Errorf("callback payload is invalid, %v", err)
+				}
+
+				mutex.Lock()
+				defer mutex.Unlock()
+				payloads = append(payloads, payload)
+				w.WriteHeader(tt.statusCodes[len(payloads)-1])
+			}))
+			defer receiver.Close()
+
+			usecase := NewJobUsecase(JobUsecase{CallbackSecret: "secret", CallbackAttempts: 3, CallbackBackoff: time.Millisecond})
+			j := &job{ReconciliationJob: transactions.ReconciliationJob{
+				ID:         "job-1",
+				Status:     transactions.JobSucceeded,
+				RunID:      "run-1",
+				Summary:    &summary,
+				FinishedAt: &finishedAt,
+				Callback:   &transactions.JobCallback{URL: receiver.URL, Status: transactions.CallbackPending},
+			}}
+			usecase.jobs.jobs[j.ID] = j
+
+			usecase.notify(context.Background(), j)
+			usecase.Wait()
+
+			tt.want.URL = receiver.URL
+			job, _ := usecase.GetJob(context.Background(), j.ID)
+			if !reflect.DeepEqual(*job.Callback, tt.want) {
+				t.Errorf("JobUsecase.notify() callback = %v, want %v", *job.Callback, tt.want)
+			}
+
+			wantPayload := transactions.JobCallbackPayload{
+				JobID:                 "job-1",
+				Status:                transactions.JobSucceeded,
+				RunID:                 "run-1",
+				ReconciliationSummary: summary,
+				FinishedAt:            finishedAt,
+			}
+			if len(payloads) != len(tt.statusCodes) {
+				t.Fatalf("JobUsecase.notify() sent %d callbacks, want %d", len(payloads), len(tt.statusCodes))
+			}
+			for _, payload := range payloads {
+				if !reflect.DeepEqual(payload, wantPayload) {
+					t.Errorf("JobUsecase.notify() payload = %v, want %v", payload, wantPayload)
+				}
+			}
+		})
+	}
+}
+
+func TestJobUsecase_notify_canceled(t *testing.T) {
+	attempts := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	usecase := NewJobUsecase(JobUsecase{CallbackSecret: "secret", CallbackBackoff: time.Hour})
+	finishedAt := time.Now()
+	j := &job{ReconciliationJob: transactions.ReconciliationJob{
+		ID:         "job-1",
+		Status:     transactions.JobFailed,
+		Error:      "reconciliation job was canceled",
+		FinishedAt: &finishedAt,
+		Callback:   &transactions.JobCallback{URL: receiver.URL, Status: transactions.CallbackPending},
+	}}
+	usecase.jobs.jobs[j.ID] = j
+
+	// the job is notified once when the workers are already stopped
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	usecase.notify(ctx, j)
+	usecase.Wait()
+
+	job, _ := usecase.GetJob(context.Background(), j.ID)
+	if attempts != 1 || job.Callback.Status != transactions.CallbackFailed || job.Callback.Attempts != 1 {
+		t.Errorf("JobUsecase.notify() attempts = %d, callback = %v, want 1 failed attempt", attempts, *job.Callback)
+	}
+}
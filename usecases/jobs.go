@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"amartha-test/entities/transactions"
+	"amartha-test/entities/usecases"
+	libError "amartha-test/errors"
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaults of the jobs of a JobUsecase
+const (
+	defaultJobWorkers   = 2
+	defaultJobQueueSize = 100
+	defaultJobRetention = 24 * time.Hour
+)
+
+// JobUsecase runs reconciliations in the background with a pool of workers, jobs are kept in memory and their
+// result is stored with their run
+type JobUsecase struct {
+	TransactionUsecase usecases.TransactionUsecase
+	Workers            int           // reconciliations run at the same time, defaultJobWorkers when 0
+	QueueSize          int           // jobs waiting for a worker, defaultJobQueueSize when 0
+	Retention          time.Duration // finished jobs are forgotten after Retention, defaultJobRetention when 0
+	CallbackSecret     string        // key of the signature of the callbacks, jobs can't have a callback when it is empty
+	CallbackClient     *http.Client  // client of the callbacks, one with defaultCallbackTimeout when nil
+	CallbackAttempts   int           // attempts to deliver a callback, defaultCallbackAttempts when 0
+	CallbackBackoff    time.Duration // wait after the first failed attempt, doubled after every attempt, defaultCallbackBackoff when 0
+
+	jobs *jobQueue
+}
+
+// jobQueue is the state of the jobs shared by every copy of a JobUsecase
+type jobQueue struct {
+	mutex     sync.Mutex
+	jobs      map[string]*job
+	pending   chan *job
+	workers   sync.WaitGroup
+	callbacks sync.WaitGroup
+}
+
+// job is a submitted reconciliation with its uploaded files
+type job struct {
+	transactions.ReconciliationJob // guarded by the mutex of the jobQueue
+	param                          transactions.DoReconciliationRequest
+	files                          []multipart.File // closed when the job finishes
+}
+
+func NewJobUsecase(usecase JobUsecase) JobUsecase {
+	if usecase.Workers <= 0 {
+		usecase.Workers = defaultJobWorkers
+	}
+	if usecase.QueueSize <= 0 {
+		usecase.QueueSize = defaultJobQueueSize
+	}
+	if usecase.Retention <= 0 {
+		usecase.Retention = defaultJobRetention
+	}
+	if usecase.CallbackClient == nil {
+		usecase.CallbackClient = &http.Client{Timeout: defaultCallbackTimeout}
+	}
+	if usecase.CallbackAttempts <= 0 {
+		usecase.CallbackAttempts = defaultCallbackAttempts
+	}
+	if usecase.CallbackBackoff <= 0 {
+		usecase.CallbackBackoff = defaultCallbackBackoff
+	}
+	usecase.jobs = &jobQueue{
+		jobs:    map[string]*job{},
+		pending: make(chan *job, usecase.QueueSize),
+	}
+	return usecase
+}
+
+// Start runs the workers until ctx is done, the running jobs are canceled with ctx and the queued jobs fail. The
+// callbacks of the jobs are retried until ctx is done
+func (usecase JobUsecase) Start(ctx context.Context) {
+	for i := 0; i < usecase.Workers; i++ {
+		usecase.jobs.workers.Add(1)
+		go func() {
+			defer usecase.jobs.workers.Done()
+			for {
+				// a stopped worker takes no more jobs even when some are queued
+				if ctx.Err() != nil {
+					usecase.failQueued(ctx)
+					return
+				}
+				select {
+				case <-ctx.Done():
+				case j := <-usecase.jobs.pending:
+					usecase.run(ctx, j)
+				}
+			}
+		}()
+	}
+}
+
+// Wait returns when every worker has stopped and every callback is finished
+func (usecase JobUsecase) Wait() {
+	usecase.jobs.workers.Wait()
+	usecase.jobs.callbacks.Wait()
+}
+
+// SubmitReconciliation queues the reconciliation and returns its job. The queued job takes the uploaded files, they
+// must be readable after the request that sent them and are closed when the job finishes, a job that is not queued
+// leaves them to the caller. The callback url is notified when the job finishes when it is not empty
+func (usecase JobUsecase) SubmitReconciliation(ctx context.Context, param transactions.DoReconciliationRequest, callbackURL string) (transactions.ReconciliationJob, error) {
+	if callbackURL != "" {
+		err := usecase.validateCallbackURL(callbackURL)
+		if err != nil {
+			return transactions.ReconciliationJob{}, err
+		}
+	}
+	usecase.forgetFinished()
+
+	param.Progress = &transactions.Progress{}
+	j := &job{
+		ReconciliationJob: transactions.ReconciliationJob{
+			ID:        newID(),
+			Status:    transactions.JobQueued,
+			CreatedAt: timeNow().UTC(),
+		},
+		param: param,
+		files: uploadedFiles(param),
+	}
+	if callbackURL != "" {
+		j.Callback = &transactions.JobCallback{URL: callbackURL, Status: transactions.CallbackPending}
+	}
+
+	usecase.jobs.mutex.Lock()
+	defer usecase.jobs.mutex.Unlock()
+	select {
+	case usecase.jobs.pending <- j:
+		usecase.jobs.jobs[j.ID] = j
+		return j.current(), nil
+	default:
+		return transactions.ReconciliationJob{}, libError.NewServiceUnavailableError("too many reconciliation jobs are queued, try again later")
+	}
+}
+
+// GetJob returns the job with its progress so far
+func (usecase JobUsecase) GetJob(ctx context.Context, id string) (transactions.ReconciliationJob, error) {
+	usecase.jobs.mutex.Lock()
+	defer usecase.jobs.mutex.Unlock()
+
+	j, ok := usecase.jobs.jobs[id]
+	if !ok {
+		return transactions.ReconciliationJob{}, libError.NewNotFoundError(fmt.Sprintf("reconciliation job %s is not found", id))
+	}
+	return j.current(), nil
+}
+
+// current returns the job with the progress of its reconciliation, the mutex of the jobQueue must be held
+func (j *job) current() transactions.ReconciliationJob {
+	current := j.ReconciliationJob
+	current.Progress = j.param.Progress.Counts()
+	if current.Callback != nil {
+		callback := *current.Callback
+		current.Callback = &callback
+	}
+	return current
+}
+
+// run reconciles the job and removes its files before it finishes, its callback is sent once it is finished
+func (usecase JobUsecase) run(ctx context.Context, j *job) {
+	usecase.update(j, func(current *transactions.ReconciliationJob) {
+		startedAt := timeNow().UTC()
+		current.Status, current.StartedAt = transactions.JobRunning, &startedAt
+	})
+
+	result, err := usecase.TransactionUsecase.DoReconciliation(ctx, j.param)
+	closeFiles(j.files)
+	if err != nil {
+		usecase.fail(ctx, j, err)
+		return
+	}
+
+	usecase.update(j, func(current *transactions.ReconciliationJob) {
+		finishedAt := timeNow().UTC()
+		summary := transactions.NewReconciliationSummary(result)
+		current.Status, current.FinishedAt = transactions.JobSucceeded, &finishedAt
+		current.RunID, current.Summary = result.RunID, &summary
+	})
+	usecase.notify(ctx, j)
+}
+
+// fail finishes the job with the error and sends its callback
+func (usecase JobUsecase) fail(ctx context.Context, j *job, err error) {
+	usecase.update(j, func(current *transactions.ReconciliationJob) {
+		finishedAt := timeNow().UTC()
+		current.Status, current.FinishedAt, current.Error = transactions.JobFailed, &finishedAt, err.Error()
+		if errors.Is(err, context.Canceled) {
+			current.Error = "reconciliation job was canceled"
+		}
+		var validationError *libError.ValidationError
+		if errors.As(err, &validationError) {
+			current.Errors = validationError.Errors
+		}
+	})
+	usecase.notify(ctx, j)
+}
+
+// failQueued fails the jobs that are still waiting for a worker with the error of ctx
+func (usecase JobUsecase) failQueued(ctx context.Context) {
+	for {
+		select {
+		case j := <-usecase.jobs.pending:
+			closeFiles(j.files)
+			usecase.fail(ctx, j, ctx.Err())
+		default:
+			return
+		}
+	}
+}
+
+func (usecase JobUsecase) update(j *job, update func(current *transactions.ReconciliationJob)) {
+	usecase.jobs.mutex.Lock()
+	defer usecase.jobs.mutex.Unlock()
+	update(&j.ReconciliationJob)
+}
+
+// forgetFinished removes the jobs that finished longer than the retention ago
+func (usecase JobUsecase) forgetFinished() {
+	usecase.jobs.mutex.Lock()
+	defer usecase.jobs.mutex.Unlock()
+
+	before := timeNow().Add(-usecase.Retention)
+	for id, j := range usecase.jobs.jobs {
+		if j.FinishedAt != nil && j.FinishedAt.Before(before) {
+			delete(usecase.jobs.jobs, id)
+		}
+	}
+}
+
+// uploadedFiles returns every uploaded file of the request
+func uploadedFiles(param transactions.DoReconciliationRequest) (files []multipart.File) {
+	for _, file := range []multipart.File{param.SystemTransactions, param.ExchangeRates} {
+		if file != nil {
+			files = append(files, file)
+		}
+	}
+	for _, upload := range param.BankStatements {
+		if upload.File != nil {
+			files = append(files, upload.File)
+		}
+	}
+	return files
+}
+
+// closeFiles closes the files, temporary files are removed
+func closeFiles(files []multipart.File) {
+	for _, file := range files {
+		file.Close()
+	}
+}
@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"amartha-test/entities/transactions"
+	usecaseMock "amartha-test/entities/usecases/mock"
+	libError "amartha-test/errors"
+	"amartha-test/money"
+	"context"
+	"io"
+	"mime/multipart"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+)
+
+// waitForJob returns the job once it is finished
+func waitForJob(t *testing.T, usecase JobUsecase, id string) transactions.ReconciliationJob {
+	t.Helper()
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(time.Millisecond) {
+		job, err := usecase.GetJob(context.Background(), id)
+		if err != nil {
+			t.Fatalf("JobUsecase.GetJob() error = %v", err)
+		}
+		if job.Status == transactions.JobSucceeded || job.Status == transactions.JobFailed {
+			return job
+		}
+	}
+	t.Fatalf("job %s is not finished", id)
+	return transactions.ReconciliationJob{}
+}
+
+// jobParam is a request with every uploaded file
+func jobParam(t *testing.T, dir string) transactions.DoReconciliationRequest {
+	// the files are uploaded to dir, they are removed when they are closed
+	upload := func(content string) multipart.File {
+		file, err := os.CreateTemp(dir, "upload-*")
+		if err != nil {
+			t.Fatalf("Failed to create upload: %v", err)
+		}
+		file.WriteString(content)
+		file.Seek(0, io.SeekStart)
+		return tempFile{file}
+	}
+	return transactions.DoReconciliationRequest{
+		SystemTransactions: upload("system"),
+		BankStatements: []transactions.BankStatementsUpload{
+			{File: upload("bank"), Name: "bri.csv", Bank: "BRI"},
+		},
+		ExchangeRates:     upload("rates"),
+		DateToleranceDays: 1,
+	}
+}
+
+func TestJobUsecase_SubmitReconciliation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Mock TransactionUsecase
+	mockUsecase := usecaseMock.NewMockTransactionUsecase(ctrl)
+	createdAt := time.Date(2024, time.Month(1), 4, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    transactions.ReconciliationJob
+		wantErr bool
+	}{
+		{
+			name: "Succesful",
+			mock: func() {
+				mockUsecase.EXPECT().DoReconciliation(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, param transactions.DoReconciliationRequest) (transactions.DoReconciliationResponse, error) {
+						// the job reads the uploaded files after the request
+						for file, want := range map[io.Reader]string{
+							param.SystemTransactions:     "system",
+							param.BankStatements[0].File: "bank",
+							param.ExchangeRates:          "rates",
+						} {
+							content, _ := io.ReadAll(file)
+							if string(content) != want {
+								t.Errorf("uploaded file = %q, want %q", content, want)
+							}
+						}
+						param.Progress.AddSystemTransactionsRead(3)
+						return transactions.DoReconciliationResponse{
+							RunID:                "run-1",
+							MatchedTransaction:   2,
+							UnmatchedTransaction: 1,
+							TotalDiscrepancies:   money.MustParse("500", money.DefaultCurrency),
+						}, nil
+					})
+			},
+			want: transactions.ReconciliationJob{
+				ID:         "job-1",
+				Status:     transactions.JobSucceeded,
+				RunID:      "run-1",
+				Progress:   transactions.ReconciliationProgress{SystemTransactionsRead: 3},
+				Summary:    &transactions.ReconciliationSummary{MatchedTransaction: 2, UnmatchedTransaction: 1, TotalDiscrepancies: money.MustParse("500", money.DefaultCurrency)},
+				CreatedAt:  createdAt,
+				StartedAt:  &createdAt,
+				FinishedAt: &createdAt,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Failed with invalid data",
+			mock: func() {
+				mockUsecase.EXPECT().DoReconciliation(gomock.Any(), gomock.Any()).
+					Return(transactions.DoReconciliationResponse{}, libError.NewValidationError("uploaded data has 1 invalid values", []transactions.RowError{{Line: 2}}))
+			},
+			want: transactions.ReconciliationJob{
+				ID:         "job-1",
+				Status:     transactions.JobFailed,
+				Error:      "uploaded data has 1 invalid values",
+				Errors:     []transactions.RowError{{Line: 2}},
+				CreatedAt:  createdAt,
+				StartedAt:  &createdAt,
+				FinishedAt: &createdAt,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			newID = func() string { return "job-1" }
+			timeNow = func() time.Time { return createdAt }
+			defer func() {
+				newID, timeNow = uuidID, time.Now
+			}()
+
+			tempDir := t.TempDir()
+			usecase := NewJobUsecase(JobUsecase{TransactionUsecase: mockUsecase})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer func() {
+				cancel()
+				usecase.Wait()
+			}()
+
+			job, err := usecase.SubmitReconciliation(context.Background(), jobParam(t, tempDir), "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("JobUsecase.SubmitReconciliation() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if job.Status != transactions.JobQueued {
+				t.Errorf("JobUsecase.SubmitReconciliation() status = %v, want %v", job.Status, transactions.JobQueued)
+			}
+
+			usecase.Start(ctx)
+			if got := waitForJob(t, usecase, job.ID); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("JobUsecase.SubmitReconciliation() = %v, want %v", got, tt.want)
+			}
+
+			// the uploaded files are removed when the job finishes
+			files, _ := os.ReadDir(tempDir)
+			if len(files) != 0 {
+				t.Errorf("JobUsecase.SubmitReconciliation() left %d files", len(files))
+			}
+		})
+	}
+}
+
+func TestJobUsecase_SubmitReconciliation_queueFull(t *testing.T) {
+	tempDir := t.TempDir()
+	usecase := NewJobUsecase(JobUsecase{QueueSize: 1})
+
+	_, err := usecase.SubmitReconciliation(context.Background(), jobParam(t, tempDir), "")
+	if err != nil {
+		t.Fatalf("JobUsecase.SubmitReconciliation() error = %v", err)
+	}
+	rejected := jobParam(t, tempDir)
+	_, err = usecase.SubmitReconciliation(context.Background(), rejected, "")
+	if _, ok := err.(*libError.ServiceUnavailableError); !ok {
+		t.Errorf("JobUsecase.SubmitReconciliation() error = %v, want ServiceUnavailableError", err)
+	}
+
+	// the files of the rejected job are left to the caller, the ones of the queued job are removed when it fails
+	files, _ := os.ReadDir(tempDir)
+	if len(files) != 6 {
+		t.Errorf("JobUsecase.SubmitReconciliation() kept %d files, want 6", len(files))
+	}
+	closeFiles(uploadedFiles(rejected))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	usecase.Start(ctx)
+	usecase.Wait()
+	files, _ = os.ReadDir(tempDir)
+	if len(files) != 0 {
+		t.Errorf("JobUsecase.Start() left %d files", len(files))
+	}
+}
+
+func TestJobUsecase_Start(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Mock TransactionUsecase
+	mockUsecase := usecaseMock.NewMockTransactionUsecase(ctrl)
+	running := make(chan struct{})
+	mockUsecase.EXPECT().DoReconciliation(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, param transactions.DoReconciliationRequest) (transactions.DoReconciliationResponse, error) {
+			close(running)
+			<-ctx.Done()
+			return transactions.DoReconciliationResponse{}, ctx.Err()
+		})
+
+	tempDir := t.TempDir()
+	usecase := NewJobUsecase(JobUsecase{TransactionUsecase: mockUsecase, Workers: 1})
+	running1, err := usecase.SubmitReconciliation(context.Background(), jobParam(t, tempDir), "")
+	if err != nil {
+		t.Fatalf("JobUsecase.SubmitReconciliation() error = %v", err)
+	}
+	queued, err := usecase.SubmitReconciliation(context.Background(), jobParam(t, tempDir), "")
+	if err != nil {
+		t.Fatalf("JobUsecase.SubmitReconciliation() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	usecase.Start(ctx)
+	<-running
+	cancel()
+	usecase.Wait()
+
+	// both the running and the queued job fail when the workers are stopped
+	for _, id := range []string{running1.ID, queued.ID} {
+		job, _ := usecase.GetJob(context.Background(), id)
+		if job.Status != transactions.JobFailed || job.Error != "reconciliation job was canceled" {
+			t.Errorf("JobUsecase.Start() job = %v %v, want failed and canceled", job.Status, job.Error)
+		}
+	}
+}
+
+func TestJobUsecase_GetJob(t *testing.T) {
+	usecase := NewJobUsecase(JobUsecase{})
+	job, err := usecase.SubmitReconciliation(context.Background(), jobParam(t, t.TempDir()), "")
+	if err != nil {
+		t.Fatalf("JobUsecase.SubmitReconciliation() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		want    transactions.ReconciliationJob
+		wantErr error
+	}{
+		{
+			name:    "Succesful",
+			id:      job.ID,
+			want:    job,
+			wantErr: nil,
+		},
+		{
+			name:    "Failed with unknown id",
+			id:      "job-2",
+			want:    transactions.ReconciliationJob{},
+			wantErr: libError.NewNotFoundError("reconciliation job job-2 is not found"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := usecase.GetJob(context.Background(), tt.id)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("JobUsecase.GetJob() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("JobUsecase.GetJob() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobUsecase_forgetFinished(t *testing.T) {
+	usecase := NewJobUsecase(JobUsecase{Retention: time.Hour})
+	finishedAt := time.Now().Add(-2 * time.Hour)
+	usecase.jobs.jobs["finished"] = &job{ReconciliationJob: transactions.ReconciliationJob{ID: "finished", FinishedAt: &finishedAt}}
+	usecase.jobs.jobs["running"] = &job{ReconciliationJob: transactions.ReconciliationJob{ID: "running"}}
+
+	usecase.forgetFinished()
+	if _, ok := usecase.jobs.jobs["finished"]; ok {
+		t.Errorf("JobUsecase.forgetFinished() kept the finished job")
+	}
+	if _, ok := usecase.jobs.jobs["running"]; !ok {
+		t.Errorf("JobUsecase.forgetFinished() removed the running job")
+	}
+}
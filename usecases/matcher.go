@@ -0,0 +1,257 @@
+package usecase
+
+import (
+	"amartha-test/entities/transactions"
+	"amartha-test/money"
+	"math"
+	"time"
+)
+
+// tolerance defines how far apart a bank statement and a system transaction can be and still be matched
+type tolerance struct {
+	days          int         // maximum difference in days
+	amount        money.Money // maximum absolute difference in amount
+	amountPercent float64     // maximum difference in amount as percentage of the system transaction amount
+}
+
+// amountFor returns the maximum amount difference allowed for the given amount in its minor unit,
+// the bigger of the absolute and percentage tolerance is used
+func (t tolerance) amountFor(amount money.Money) int64 {
+	// the percentage is rounded down so the tolerance never exceeds what was asked
+	percentage := int64(float64(amount.Abs().Amount) * t.amountPercent / 100)
+	if percentage > t.amount.Amount {
+		return percentage
+	}
+	return t.amount.Amount
+}
+
+// dayKey is the day and type a bank statement is booked on
+type dayKey struct {
+	day             int64 // unix time of the start of the day
+	transactionType int
+}
+
+// span is the first and last position of bank statements next to each other, positions count the statements
+// evicted before
+type span struct {
+	first, last int
+}
+
+// dayIndex finds the bank statements of a day and type by their amount bucket, see matcher.bucketOf
+type dayIndex struct {
+	span
+	buckets    map[int64]int // index in spans of every amount bucket
+	spans      []span        // statements of every amount bucket in the order they were added
+	lastBucket int64
+}
+
+// matcher pairs system transactions with bank statements one-to-one. The bank statements are indexed by their
+// day, type and amount bucket so the candidates of a transaction are found with map lookups instead of a
+// search, and every bank statement can only be consumed once. Bank statements must be added sorted with
+// transactions.SortByRealDateBankStatement, the statements of a bucket are next to each other and ties are
+// broken in that order
+type matcher struct {
+	statements []*transactions.BankStatements
+	consumed   []bool
+	evicted    int // number of statements removed before statements[0], positions count them
+
+	days       map[dayKey]*dayIndex
+	lastDay    *dayIndex // day of the statement added last
+	lastDayKey dayKey
+	tolerance  tolerance
+}
+
+func newMatcher(statements []*transactions.BankStatements, tolerance tolerance) *matcher {
+	m := &matcher{
+		days:      map[dayKey]*dayIndex{},
+		tolerance: tolerance,
+	}
+	m.add(statements)
+	return m
+}
+
+// keyOf returns the day and type of the bank statement
+func keyOf(date time.Time, transactionType int) dayKey {
+	return dayKey{day: date.Unix(), transactionType: transactionType}
+}
+
+// bucketOf returns the amount bucket of an amount in reporting currency. Buckets hold as many amounts as the
+// absolute tolerance so an amount inside it is at most one bucket away, and an exact amount is its own bucket
+// without tolerance. With a percentage tolerance buckets grow with the amount by the percentage, so an amount
+// inside the tolerance is also a few buckets away
+func (m *matcher) bucketOf(amount int64) int64 {
+	if m.tolerance.amountPercent > 0 {
+		return int64(math.Log1p(float64(amount)) / math.Log1p(m.tolerance.amountPercent/100))
+	}
+	return amount / (m.tolerance.amount.Amount + 1)
+}
+
+// add adds bank statements sorted with transactions.SortByRealDateBankStatement, they must not be dated before the
+// bank statements added before
+func (m *matcher) add(statements []*transactions.BankStatements) {
+	for _, statement := range statements {
+		position := m.evicted + len(m.statements)
+		m.statements = append(m.statements, statement)
+		m.consumed = append(m.consumed, false)
+
+		key := keyOf(statement.RealDate, statement.Type)
+		bucket := m.bucketOf(statement.ReportingAmount.Abs().Amount)
+		day := m.lastDay
+		if day == nil || m.lastDayKey != key {
+			day = &dayIndex{span: span{first: position}, buckets: map[int64]int{}}
+			m.days[key], m.lastDay, m.lastDayKey = day, day, key
+		}
+		day.last = position
+
+		if len(day.spans) > 0 && day.lastBucket == bucket {
+			day.spans[len(day.spans)-1].last = position
+			continue
+		}
+		day.buckets[bucket] = len(day.spans)
+		day.spans = append(day.spans, span{first: position, last: position})
+		day.lastBucket = bucket
+	}
+}
+
+// evictBefore removes the bank statements dated before the date and returns the ones that have not been consumed,
+// so only the bank statements a transaction can still be matched with are kept
+func (m *matcher) evictBefore(date time.Time) (result []*transactions.BankStatements) {
+	index := 0
+	for ; index < len(m.statements) && m.statements[index].RealDate.Before(date); index++ {
+		if !m.consumed[index] {
+			result = append(result, m.statements[index])
+		}
+	}
+	if index == 0 {
+		return
+	}
+
+	// the statements left are copied so the evicted ones can be freed
+	m.statements = append([]*transactions.BankStatements(nil), m.statements[index:]...)
+	m.consumed = append([]bool(nil), m.consumed[index:]...)
+	m.evicted += index
+	for key := range m.days {
+		if key.day < date.Unix() {
+			delete(m.days, key)
+		}
+	}
+	if _, ok := m.days[m.lastDayKey]; !ok {
+		m.lastDay = nil
+	}
+	return
+}
+
+// matchDay matches the transactions of a day and returns the bank statement of every transaction, nil when it has
+// none. Transactions are first matched with the bank statements of their day and amount, the tolerances are only
+// used for the transactions left, so a transaction inside the tolerance can't take the exact match of another one
+func (m *matcher) matchDay(systemTransactions []*transactions.SystemTransactions) []*transactions.BankStatements {
+	result := make([]*transactions.BankStatements, len(systemTransactions))
+	for index, transaction := range systemTransactions {
+		result[index] = m.matchOnDate(transaction, transaction.RealTransactionTime, 0)
+	}
+	for index, transaction := range systemTransactions {
+		if result[index] == nil {
+			result[index] = m.match(transaction)
+		}
+	}
+	return result
+}
+
+// match consumes and returns an unconsumed bank statement with the same type as the transaction, dated at
+// most tolerance.days away from it and with an amount in reporting currency inside the amount tolerance,
+// or nil when there is none left. The closest date wins, and on a tie the statement posted after the
+// transaction is preferred because banks settle after the transaction is recorded
+func (m *matcher) match(transaction *transactions.SystemTransactions) *transactions.BankStatements {
+	date := transaction.RealTransactionTime
+	amountTolerance := m.tolerance.amountFor(transaction.ReportingAmount)
+
+	for distance := 0; distance <= m.tolerance.days; distance++ {
+		if statement := m.matchOnDate(transaction, date.AddDate(0, 0, distance), amountTolerance); statement != nil {
+			return statement
+		}
+		if distance == 0 {
+			continue
+		}
+		if statement := m.matchOnDate(transaction, date.AddDate(0, 0, -distance), amountTolerance); statement != nil {
+			return statement
+		}
+	}
+
+	return nil
+}
+
+// matchOnDate consumes and returns the unconsumed bank statement on the given date with the same type as the
+// transaction and the closest amount in reporting currency at most amountTolerance away, the statement added
+// first wins a tie
+func (m *matcher) matchOnDate(transaction *transactions.SystemTransactions, date time.Time, amountTolerance int64) *transactions.BankStatements {
+	key := keyOf(date, transaction.Type)
+	amount := transaction.ReportingAmount.Abs().Amount
+	low, high := max(amount-amountTolerance, 0), amount+amountTolerance
+
+	day, ok := m.days[key]
+	if !ok {
+		return nil
+	}
+
+	closest, closestDifference := -1, int64(0)
+	var closestBucket *span
+	consider := func(bucket *span, first, last int) {
+		for index := first - m.evicted; index <= last-m.evicted; index++ {
+			statementAmount := m.statements[index].ReportingAmount.Abs().Amount
+			if m.consumed[index] || statementAmount < low || statementAmount > high {
+				continue
+			}
+			difference := amountDifference(m.statements[index], amount)
+			if closest < 0 || difference < closestDifference {
+				closest, closestDifference, closestBucket = index, difference, bucket
+			}
+		}
+	}
+
+	firstBucket, lastBucket := m.bucketOf(low), m.bucketOf(high)
+	if lastBucket-firstBucket > int64(len(day.spans)) {
+		// a wide tolerance can cover more buckets than the day has, the day is scanned instead
+		consider(nil, day.first, day.last)
+	} else {
+		for bucket := firstBucket; bucket <= lastBucket; bucket++ {
+			if index, ok := day.buckets[bucket]; ok {
+				consider(&day.spans[index], day.spans[index].first, day.spans[index].last)
+			}
+		}
+	}
+
+	if closest < 0 {
+		return nil
+	}
+	m.consumed[closest] = true
+
+	// consumed statements at the start of the bucket are skipped, so statements with the same amount are found
+	// without going over the ones consumed before
+	statement := m.statements[closest]
+	if closestBucket == nil {
+		closestBucket = &day.spans[day.buckets[m.bucketOf(statement.ReportingAmount.Abs().Amount)]]
+	}
+	for closestBucket.first <= closestBucket.last && m.consumed[closestBucket.first-m.evicted] {
+		closestBucket.first++
+	}
+	return statement
+}
+
+// amountDifference returns how far the amount of the statement in reporting currency is from the given amount
+func amountDifference(statement *transactions.BankStatements, amount int64) int64 {
+	difference := statement.ReportingAmount.Abs().Amount - amount
+	if difference < 0 {
+		return -difference
+	}
+	return difference
+}
+
+// unmatched returns the bank statements that have not been consumed by any transaction
+func (m *matcher) unmatched() (result []*transactions.BankStatements) {
+	for index, statement := range m.statements {
+		if !m.consumed[index] {
+			result = append(result, statement)
+		}
+	}
+	return
+}
@@ -0,0 +1,598 @@
+package usecase
+
+import (
+	"amartha-test/entities/transactions"
+	"amartha-test/money"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_matcher_match(t *testing.T) {
+	statements := []*transactions.BankStatements{
+		{
+			ID:              "BCA_1",
+			ReportingAmount: money.MustParse("2000000", money.DefaultCurrency),
+			Type:            transactions.CREDIT,
+			RealDate:        time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
+		},
+		{
+			ID:              "BCA_2",
+			ReportingAmount: money.MustParse("-1500000", money.DefaultCurrency),
+			Type:            transactions.DEBIT,
+			RealDate:        time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+		},
+		{
+			ID:              "BCA_3",
+			ReportingAmount: money.MustParse("1000000", money.DefaultCurrency),
+			Type:            transactions.CREDIT,
+			RealDate:        time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+		},
+		{
+			ID:              "BCA_4",
+			ReportingAmount: money.MustParse("1000000", money.DefaultCurrency),
+			Type:            transactions.CREDIT,
+			RealDate:        time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+		},
+		{
+			ID:              "BCA_6",
+			ReportingAmount: money.MustParse("1100000", money.DefaultCurrency),
+			Type:            transactions.CREDIT,
+			RealDate:        time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+		},
+		{
+			ID:              "BCA_5",
+			ReportingAmount: money.MustParse("2000000", money.DefaultCurrency),
+			Type:            transactions.CREDIT,
+			RealDate:        time.Date(2024, time.Month(1), 15, 0, 0, 0, 0, time.Local),
+		},
+	}
+
+	tests := []struct {
+		name         string
+		tolerance    tolerance
+		transactions []*transactions.SystemTransactions
+		want         []string
+		wantLeft     []string
+	}{
+		{
+			name: "Succesful",
+			transactions: []*transactions.SystemTransactions{
+				{
+					TransactionID:       "1",
+					ReportingAmount:     money.MustParse("2000000", money.DefaultCurrency),
+					Type:                transactions.CREDIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
+				},
+			},
+			want:     []string{"BCA_1"},
+			wantLeft: []string{"BCA_2", "BCA_3", "BCA_4", "BCA_6", "BCA_5"},
+		},
+		{
+			name: "DEBIT compared by absolute amount",
+			transactions: []*transactions.SystemTransactions{
+				{
+					TransactionID:       "1",
+					ReportingAmount:     money.MustParse("1500000", money.DefaultCurrency),
+					Type:                transactions.DEBIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+				},
+			},
+			want:     []string{"BCA_2"},
+			wantLeft: []string{"BCA_1", "BCA_3", "BCA_4", "BCA_6", "BCA_5"},
+		},
+		{
+			name: "Same key is consumed once",
+			transactions: []*transactions.SystemTransactions{
+				{
+					TransactionID:       "1",
+					ReportingAmount:     money.MustParse("1000000", money.DefaultCurrency),
+					Type:                transactions.CREDIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+				},
+				{
+					TransactionID:       "2",
+					ReportingAmount:     money.MustParse("1000000", money.DefaultCurrency),
+					Type:                transactions.CREDIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+				},
+				{
+					TransactionID:       "3",
+					ReportingAmount:     money.MustParse("1000000", money.DefaultCurrency),
+					Type:                transactions.CREDIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+				},
+			},
+			want:     []string{"BCA_3", "BCA_4", ""},
+			wantLeft: []string{"BCA_1", "BCA_2", "BCA_6", "BCA_5"},
+		},
+		{
+			name: "Different amount is not matched",
+			transactions: []*transactions.SystemTransactions{
+				{
+					TransactionID:       "1",
+					ReportingAmount:     money.MustParse("2500000", money.DefaultCurrency),
+					Type:                transactions.CREDIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
+				},
+			},
+			want:     []string{""},
+			wantLeft: []string{"BCA_1", "BCA_2", "BCA_3", "BCA_4", "BCA_6", "BCA_5"},
+		},
+		{
+			name: "Different date is not matched without tolerance",
+			transactions: []*transactions.SystemTransactions{
+				{
+					TransactionID:       "1",
+					ReportingAmount:     money.MustParse("2000000", money.DefaultCurrency),
+					Type:                transactions.CREDIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 12, 0, 0, 0, 0, time.Local),
+				},
+			},
+			want:     []string{""},
+			wantLeft: []string{"BCA_1", "BCA_2", "BCA_3", "BCA_4", "BCA_6", "BCA_5"},
+		},
+		{
+			name:      "Different date is matched within tolerance",
+			tolerance: tolerance{days: 1},
+			transactions: []*transactions.SystemTransactions{
+				{
+					TransactionID:       "1",
+					ReportingAmount:     money.MustParse("2000000", money.DefaultCurrency),
+					Type:                transactions.CREDIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 12, 0, 0, 0, 0, time.Local),
+				},
+				{
+					TransactionID:       "2",
+					ReportingAmount:     money.MustParse("1500000", money.DefaultCurrency),
+					Type:                transactions.DEBIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 15, 0, 0, 0, 0, time.Local),
+				},
+			},
+			want:     []string{"BCA_1", "BCA_2"},
+			wantLeft: []string{"BCA_3", "BCA_4", "BCA_6", "BCA_5"},
+		},
+		{
+			name:      "Closest date is preferred",
+			tolerance: tolerance{days: 3},
+			transactions: []*transactions.SystemTransactions{
+				{
+					TransactionID:       "1",
+					ReportingAmount:     money.MustParse("2000000", money.DefaultCurrency),
+					Type:                transactions.CREDIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 16, 0, 0, 0, 0, time.Local),
+				},
+			},
+			want:     []string{"BCA_5"},
+			wantLeft: []string{"BCA_1", "BCA_2", "BCA_3", "BCA_4", "BCA_6"},
+		},
+		{
+			name:      "Later date is preferred on a tie",
+			tolerance: tolerance{days: 1},
+			transactions: []*transactions.SystemTransactions{
+				{
+					TransactionID:       "1",
+					ReportingAmount:     money.MustParse("2000000", money.DefaultCurrency),
+					Type:                transactions.CREDIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+				},
+			},
+			want:     []string{"BCA_5"},
+			wantLeft: []string{"BCA_1", "BCA_2", "BCA_3", "BCA_4", "BCA_6"},
+		},
+		{
+			name:      "Amount within absolute tolerance is matched",
+			tolerance: tolerance{amount: money.MustParse("6500", money.DefaultCurrency)},
+			transactions: []*transactions.SystemTransactions{
+				{
+					TransactionID:       "1",
+					ReportingAmount:     money.MustParse("2006500", money.DefaultCurrency),
+					Type:                transactions.CREDIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
+				},
+				{
+					TransactionID:       "2",
+					ReportingAmount:     money.MustParse("1493000", money.DefaultCurrency),
+					Type:                transactions.DEBIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+				},
+			},
+			want:     []string{"BCA_1", ""},
+			wantLeft: []string{"BCA_2", "BCA_3", "BCA_4", "BCA_6", "BCA_5"},
+		},
+		{
+			name:      "Amount within percentage tolerance is matched",
+			tolerance: tolerance{amountPercent: 1},
+			transactions: []*transactions.SystemTransactions{
+				{
+					TransactionID:       "1",
+					ReportingAmount:     money.MustParse("1510000", money.DefaultCurrency),
+					Type:                transactions.DEBIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+				},
+			},
+			want:     []string{"BCA_2"},
+			wantLeft: []string{"BCA_1", "BCA_3", "BCA_4", "BCA_6", "BCA_5"},
+		},
+		{
+			name:      "Closest amount is preferred",
+			tolerance: tolerance{amount: money.MustParse("1000000", money.DefaultCurrency)},
+			transactions: []*transactions.SystemTransactions{
+				{
+					TransactionID:       "1",
+					ReportingAmount:     money.MustParse("1200000", money.DefaultCurrency),
+					Type:                transactions.CREDIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+				},
+			},
+			want:     []string{"BCA_6"},
+			wantLeft: []string{"BCA_1", "BCA_2", "BCA_3", "BCA_4", "BCA_5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMatcher(statements, tt.tolerance)
+
+			var got []string
+			for _, transaction := range tt.transactions {
+				statement := m.match(transaction)
+				if statement == nil {
+					got = append(got, "")
+					continue
+				}
+				got = append(got, statement.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matcher.match() = %v, want %v", got, tt.want)
+			}
+
+			var gotLeft []string
+			for _, statement := range m.unmatched() {
+				gotLeft = append(gotLeft, statement.ID)
+			}
+			if !reflect.DeepEqual(gotLeft, tt.wantLeft) {
+				t.Errorf("matcher.unmatched() = %v, want %v", gotLeft, tt.wantLeft)
+			}
+		})
+	}
+}
+
+func Test_matcher_matchDay(t *testing.T) {
+	day := time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, time.Local)
+	statements := []*transactions.BankStatements{
+		{
+			ID:              "BCA_1",
+			ReportingAmount: money.MustParse("100000", money.DefaultCurrency),
+			Type:            transactions.CREDIT,
+			RealDate:        day,
+		},
+		{
+			ID:              "BCA_2",
+			ReportingAmount: money.MustParse("99000", money.DefaultCurrency),
+			Type:            transactions.CREDIT,
+			RealDate:        day.AddDate(0, 0, 1),
+		},
+	}
+	systemTransactions := []*transactions.SystemTransactions{
+		{
+			TransactionID:       "trx1",
+			ReportingAmount:     money.MustParse("99500", money.DefaultCurrency),
+			Type:                transactions.CREDIT,
+			RealTransactionTime: day,
+		},
+		{
+			TransactionID:       "trx2",
+			ReportingAmount:     money.MustParse("100000", money.DefaultCurrency),
+			Type:                transactions.CREDIT,
+			RealTransactionTime: day,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		statements []*transactions.BankStatements
+		tolerance  tolerance
+		want       []string
+		wantLeft   []string
+	}{
+		{
+			name:       "Exact match is not taken by a transaction inside the amount tolerance",
+			statements: statements[:1],
+			tolerance:  tolerance{amount: money.MustParse("1000", money.DefaultCurrency)},
+			want:       []string{"", "BCA_1"},
+		},
+		{
+			name:       "Transaction inside the tolerance is matched with the bank statements left",
+			statements: statements,
+			tolerance:  tolerance{days: 1, amount: money.MustParse("1000", money.DefaultCurrency)},
+			want:       []string{"BCA_2", "BCA_1"},
+		},
+		{
+			name:       "Without tolerance",
+			statements: statements,
+			want:       []string{"", "BCA_1"},
+			wantLeft:   []string{"BCA_2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMatcher(tt.statements, tt.tolerance)
+
+			var got []string
+			for _, statement := range m.matchDay(systemTransactions) {
+				if statement == nil {
+					got = append(got, "")
+					continue
+				}
+				got = append(got, statement.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matcher.matchDay() = %v, want %v", got, tt.want)
+			}
+
+			var gotLeft []string
+			for _, statement := range m.unmatched() {
+				gotLeft = append(gotLeft, statement.ID)
+			}
+			if !reflect.DeepEqual(gotLeft, tt.wantLeft) {
+				t.Errorf("matcher.unmatched() = %v, want %v", gotLeft, tt.wantLeft)
+			}
+		})
+	}
+}
+
+func Test_matcher_evictBefore(t *testing.T) {
+	statement := func(id string, day int) *transactions.BankStatements {
+		return &transactions.BankStatements{
+			ID:              id,
+			ReportingAmount: money.MustParse("1000000", money.DefaultCurrency),
+			Type:            transactions.CREDIT,
+			RealDate:        time.Date(2024, time.Month(1), day, 0, 0, 0, 0, time.Local),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		matched     []*transactions.SystemTransactions
+		date        time.Time
+		wantEvicted []string
+		wantLeft    []string
+	}{
+		{
+			name:        "Succesful",
+			date:        time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+			wantEvicted: []string{"BCA_1", "BCA_2"},
+			wantLeft:    []string{"BCA_3", "BCA_4"},
+		},
+		{
+			name: "Consumed statements are not returned",
+			matched: []*transactions.SystemTransactions{
+				{
+					ReportingAmount:     money.MustParse("1000000", money.DefaultCurrency),
+					Type:                transactions.CREDIT,
+					RealTransactionTime: time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
+				},
+			},
+			date:        time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+			wantEvicted: []string{"BCA_2"},
+			wantLeft:    []string{"BCA_3", "BCA_4"},
+		},
+		{
+			name:     "Nothing to evict",
+			date:     time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
+			wantLeft: []string{"BCA_1", "BCA_2", "BCA_3", "BCA_4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMatcher(nil, tolerance{})
+			m.add([]*transactions.BankStatements{statement("BCA_1", 13), statement("BCA_2", 13)})
+			m.add([]*transactions.BankStatements{statement("BCA_3", 14), statement("BCA_4", 15)})
+			for _, transaction := range tt.matched {
+				m.match(transaction)
+			}
+
+			var gotEvicted []string
+			for _, statement := range m.evictBefore(tt.date) {
+				gotEvicted = append(gotEvicted, statement.ID)
+			}
+			if !reflect.DeepEqual(gotEvicted, tt.wantEvicted) {
+				t.Errorf("matcher.evictBefore() = %v, want %v", gotEvicted, tt.wantEvicted)
+			}
+
+			var gotLeft []string
+			for _, statement := range m.unmatched() {
+				gotLeft = append(gotLeft, statement.ID)
+			}
+			if !reflect.DeepEqual(gotLeft, tt.wantLeft) {
+				t.Errorf("matcher.unmatched() = %v, want %v", gotLeft, tt.wantLeft)
+			}
+		})
+	}
+}
+
+// generateRecords returns count bank statements sorted like the matcher needs them and the system transactions
+// they were booked from, a tenth of the transactions are missing from the bank and the others are booked up to
+// two days later with a small difference in amount on every fifth statement
+func generateRecords(count int, seed int64) (statements []*transactions.BankStatements, systemTransactions []*transactions.SystemTransactions) {
+	random := rand.New(rand.NewSource(seed))
+	start := time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, time.Local)
+
+	for index := 0; index < count; index++ {
+		transactionType := transactions.CREDIT
+		if random.Intn(2) == 0 {
+			transactionType = transactions.DEBIT
+		}
+		amount := int64(random.Intn(100000)+1) * 100
+		day := start.AddDate(0, 0, random.Intn(31))
+
+		systemTransactions = append(systemTransactions, &transactions.SystemTransactions{
+			TransactionID:       strconv.Itoa(index),
+			ReportingAmount:     money.New(amount, money.DefaultCurrency),
+			Type:                transactionType,
+			RealTransactionTime: day,
+		})
+		if index%10 == 0 {
+			continue
+		}
+
+		if index%5 == 1 {
+			amount += int64(random.Intn(1000))
+		}
+		statements = append(statements, &transactions.BankStatements{
+			ID:              "BCA_" + strconv.Itoa(index),
+			ReportingAmount: money.New(amount, money.DefaultCurrency),
+			Type:            transactionType,
+			RealDate:        day.AddDate(0, 0, random.Intn(3)),
+		})
+	}
+
+	sort.Stable(transactions.SortByRealDateBankStatement(statements))
+	sort.Stable(transactions.SortByRealDateSystemTransaction(systemTransactions))
+	return statements, systemTransactions
+}
+
+// referenceMatch is the matching rule of the matcher written as a scan over every statement
+func referenceMatch(statements []*transactions.BankStatements, consumed []bool, transaction *transactions.SystemTransactions, tolerance tolerance) *transactions.BankStatements {
+	amount := transaction.ReportingAmount.Abs().Amount
+	amountTolerance := tolerance.amountFor(transaction.ReportingAmount)
+
+	matchOnDate := func(date time.Time) *transactions.BankStatements {
+		closest := -1
+		for index, statement := range statements {
+			if consumed[index] || !statement.RealDate.Equal(date) || statement.Type != transaction.Type || amountDifference(statement, amount) > amountTolerance {
+				continue
+			}
+			if closest < 0 || amountDifference(statement, amount) < amountDifference(statements[closest], amount) {
+				closest = index
+			}
+		}
+		if closest < 0 {
+			return nil
+		}
+		consumed[closest] = true
+		return statements[closest]
+	}
+
+	for distance := 0; distance <= tolerance.days; distance++ {
+		if statement := matchOnDate(transaction.RealTransactionTime.AddDate(0, 0, distance)); statement != nil {
+			return statement
+		}
+		if distance == 0 {
+			continue
+		}
+		if statement := matchOnDate(transaction.RealTransactionTime.AddDate(0, 0, -distance)); statement != nil {
+			return statement
+		}
+	}
+	return nil
+}
+
+func Test_matcher_matchesReference(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance tolerance
+	}{
+		{
+			name: "Without tolerance",
+		},
+		{
+			name:      "With date tolerance",
+			tolerance: tolerance{days: 2},
+		},
+		{
+			name:      "With absolute tolerance",
+			tolerance: tolerance{days: 2, amount: money.New(500, money.DefaultCurrency)},
+		},
+		{
+			name:      "With percentage tolerance",
+			tolerance: tolerance{days: 2, amountPercent: 0.5},
+		},
+		{
+			name:      "With absolute and percentage tolerance",
+			tolerance: tolerance{days: 1, amount: money.New(20000, money.DefaultCurrency), amountPercent: 0.1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 5; seed++ {
+				statements, systemTransactions := generateRecords(1000, seed)
+				m := newMatcher(statements, tt.tolerance)
+				consumed := make([]bool, len(statements))
+
+				for _, transaction := range systemTransactions {
+					got, want := m.match(transaction), referenceMatch(statements, consumed, transaction, tt.tolerance)
+					if got != want {
+						t.Fatalf("matcher.match() of transaction %s with seed %d = %v, want %v", transaction.TransactionID, seed, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+// matchByDay matches the transactions one day at a time like TransactionUsecase.DoReconciliation, the bank
+// statements are added when they are within the date tolerance of the day and evicted after
+func matchByDay(statements []*transactions.BankStatements, systemTransactions []*transactions.SystemTransactions, tolerance tolerance) (matched int) {
+	m := newMatcher(nil, tolerance)
+	added := 0
+	for start := 0; start < len(systemTransactions); {
+		day := systemTransactions[start].RealTransactionTime
+		end := start
+		for end < len(systemTransactions) && systemTransactions[end].RealTransactionTime.Equal(day) {
+			end++
+		}
+
+		until := day.AddDate(0, 0, tolerance.days)
+		next := added
+		for next < len(statements) && !statements[next].RealDate.After(until) {
+			next++
+		}
+		m.add(statements[added:next])
+		added = next
+		m.evictBefore(day.AddDate(0, 0, -tolerance.days))
+
+		for _, statement := range m.matchDay(systemTransactions[start:end]) {
+			if statement != nil {
+				matched++
+			}
+		}
+		start = end
+	}
+	return matched
+}
+
+func Benchmark_matcher_match(b *testing.B) {
+	statements, systemTransactions := generateRecords(1000000, 1)
+
+	benchmarks := []struct {
+		name      string
+		tolerance tolerance
+	}{
+		{
+			name: "Without tolerance",
+		},
+		{
+			name:      "With date tolerance",
+			tolerance: tolerance{days: 2},
+		},
+		{
+			name:      "With absolute tolerance",
+			tolerance: tolerance{days: 2, amount: money.New(1000, money.DefaultCurrency)},
+		},
+		{
+			name:      "With percentage tolerance",
+			tolerance: tolerance{days: 2, amountPercent: 1},
+		},
+	}
+	for _, bb := range benchmarks {
+		b.Run(bb.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				matchByDay(statements, systemTransactions, bb.tolerance)
+			}
+		})
+	}
+}
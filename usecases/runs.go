@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"amartha-test/entities/repositories"
+	"amartha-test/entities/transactions"
+	libError "amartha-test/errors"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"time"
+)
+
+var (
+	timeNow = time.Now
+
+	// newID returns a random version 4 UUID, the id of runs and jobs
+	newID = func() string {
+		id := make([]byte, 16)
+		rand.Read(id)
+		id[6] = id[6]&0x0f | 0x40
+		id[8] = id[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+	}
+)
+
+// reconciledDays is the first and last booking day of the records of a reconciliation
+type reconciledDays struct {
+	first, last time.Time
+}
+
+func (d *reconciledDays) add(day time.Time) {
+	if d.first.IsZero() || day.Before(d.first) {
+		d.first = day
+	}
+	if d.last.IsZero() || day.After(d.last) {
+		d.last = day
+	}
+}
+
+// period returns the first and last day of a run, the days of the date range are used for the sides of the range
+// that are set
+func (d *reconciledDays) period(startDate, endDate time.Time) (start, end string) {
+	if startDate.IsZero() {
+		startDate = d.first
+	}
+	if endDate.IsZero() {
+		endDate = d.last
+	}
+	if !startDate.IsZero() {
+		start = startDate.Format(transactions.DateRangeFormat)
+	}
+	if !endDate.IsZero() {
+		end = endDate.Format(transactions.DateRangeFormat)
+	}
+	return
+}
+
+// checksumFile returns the SHA-256 checksum of the file and reads it from the start again
+func checksumFile(file multipart.File) (string, error) {
+	hash := sha256.New()
+	_, err := io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), rewind(file)
+}
+
+// checksumUploads returns the checksum of every uploaded file of the request
+func checksumUploads(param transactions.DoReconciliationRequest) (checksums []transactions.FileChecksum, err error) {
+	add := func(file multipart.File, field, name string) error {
+		if file == nil {
+			return nil
+		}
+		checksum, err := checksumFile(file)
+		if err != nil {
+			return err
+		}
+		checksums = append(checksums, transactions.FileChecksum{File: field, Name: name, SHA256: checksum})
+		return nil
+	}
+
+	err = add(param.SystemTransactions, "system_transactions", "")
+	if err != nil {
+		return nil, err
+	}
+	for _, upload := range param.BankStatements {
+		err = add(upload.File, "bank_statements", upload.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = add(param.ExchangeRates, "exchange_rates", "")
+	if err != nil {
+		return nil, err
+	}
+	return checksums, nil
+}
+
+// runFileOptions returns the options of an uploaded file that are stored with its run
+func runFileOptions(options transactions.FileOptions) transactions.RunFileOptions {
+	options = options.WithDefaults()
+	return transactions.RunFileOptions{
+		NumberFormat: options.NumberFormat,
+		Currency:     options.Currency,
+		DateFormat:   options.DateFormat,
+		TimeZone:     options.Location.String(),
+		Sheet:        options.Sheet,
+		HeaderRow:    options.HeaderRow,
+		Layout:       options.Layout,
+	}
+}
+
+// runParameters returns the parameters of the request that are stored with its run
+func runParameters(param transactions.DoReconciliationRequest) transactions.RunParameters {
+	bankStatementsOptions := param.BankStatementsOptions.WithDefaults()
+	location := bankStatementsOptions.Location
+	parameters := transactions.RunParameters{
+		DateToleranceDays:         param.DateToleranceDays,
+		AmountTolerance:           param.AmountTolerance.String(),
+		AmountTolerancePercent:    param.AmountTolerancePercent,
+		ReportingCurrency:         param.ReportingCurrency,
+		ValidationMode:            param.ValidationMode,
+		BankStatementsFormat:      bankStatementsOptions.Format,
+		BankSourcePattern:         bankStatementsOptions.BankSourcePattern.String(),
+		TimeZone:                  location.String(),
+		BankStatementsOptions:     runFileOptions(param.BankStatementsOptions),
+		SystemTransactionsOptions: runFileOptions(param.SystemTransactionsOptions),
+	}
+	if !param.StartDate.IsZero() {
+		parameters.StartDate = calendarDay(param.StartDate, location).Format(transactions.DateRangeFormat)
+	}
+	if !param.EndDate.IsZero() {
+		parameters.EndDate = calendarDay(param.EndDate, location).Format(transactions.DateRangeFormat)
+	}
+	return parameters
+}
+
+// page sizes of the stored runs
+const (
+	defaultRunsPageSize = 20
+	maxRunsPageSize     = 100
+)
+
+// GetReconciliationRuns returns a page of the stored runs of the filter, the most recent first
+func (usecase TransactionUsecase) GetReconciliationRuns(ctx context.Context, param transactions.GetReconciliationRunsRequest) (result transactions.GetReconciliationRunsResponse, err error) {
+	if usecase.ReconciliationRepository == nil {
+		return result, libError.NewBadRequestError("reconciliation runs are not stored")
+	}
+	if param.Page < 0 || param.PageSize < 0 {
+		return result, libError.NewBadRequestError("page and page size can not be negative")
+	}
+	if param.PageSize > maxRunsPageSize {
+		return result, libError.NewBadRequestError(fmt.Sprintf("page size can not be bigger than %d", maxRunsPageSize))
+	}
+	if !param.StartDate.IsZero() && !param.EndDate.IsZero() && param.StartDate.After(param.EndDate) {
+		return result, libError.NewBadRequestError("start date can not be after end date")
+	}
+	if param.Status != "" && param.Status != transactions.RunSucceeded && param.Status != transactions.RunFailed {
+		return result, libError.NewBadRequestError(fmt.Sprintf("status must be %s or %s", transactions.RunSucceeded, transactions.RunFailed))
+	}
+	if param.Page == 0 {
+		param.Page = 1
+	}
+	if param.PageSize == 0 {
+		param.PageSize = defaultRunsPageSize
+	}
+
+	runs, total, err := usecase.ReconciliationRepository.GetRuns(ctx, param)
+	if err != nil {
+		return result, err
+	}
+	return transactions.GetReconciliationRunsResponse{
+		Runs:     runs,
+		Page:     param.Page,
+		PageSize: param.PageSize,
+		Total:    total,
+	}, nil
+}
+
+// GetReconciliationRun returns the stored run with its result
+func (usecase TransactionUsecase) GetReconciliationRun(ctx context.Context, id string) (transactions.ReconciliationRun, error) {
+	if usecase.ReconciliationRepository == nil {
+		return transactions.ReconciliationRun{}, libError.NewBadRequestError("reconciliation runs are not stored")
+	}
+
+	run, err := usecase.ReconciliationRepository.GetRun(ctx, id)
+	if errors.Is(err, repositories.ErrRunNotFound) {
+		return run, libError.NewNotFoundError(fmt.Sprintf("reconciliation run %s is not found", id))
+	}
+	return run, err
+}
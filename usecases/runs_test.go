@@ -0,0 +1,386 @@
+package usecase
+
+import (
+	"amartha-test/dates"
+	"amartha-test/entities/repositories"
+	repositoryMock "amartha-test/entities/repositories/mock"
+	"amartha-test/entities/transactions"
+	libError "amartha-test/errors"
+	"amartha-test/money"
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+)
+
+func Test_reconciledDays_period(t *testing.T) {
+	type args struct {
+		days      []time.Time
+		startDate time.Time
+		endDate   time.Time
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantStart string
+		wantEnd   string
+	}{
+		{
+			name: "Succesful with days of the records",
+			args: args{
+				days: []time.Time{
+					time.Date(2024, time.Month(1), 3, 0, 0, 0, 0, transactions.DefaultLocation),
+					time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, transactions.DefaultLocation),
+					time.Date(2024, time.Month(1), 2, 0, 0, 0, 0, transactions.DefaultLocation),
+				},
+			},
+			wantStart: "2024-01-01",
+			wantEnd:   "2024-01-03",
+		},
+		{
+			name: "Succesful with date range",
+			args: args{
+				days: []time.Time{
+					time.Date(2024, time.Month(1), 3, 0, 0, 0, 0, transactions.DefaultLocation),
+				},
+				startDate: time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, transactions.DefaultLocation),
+			},
+			wantStart: "2024-01-01",
+			wantEnd:   "2024-01-03",
+		},
+		{
+			name:      "Succesful without records",
+			args:      args{},
+			wantStart: "",
+			wantEnd:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days := &reconciledDays{}
+			for _, day := range tt.args.days {
+				days.add(day)
+			}
+			gotStart, gotEnd := days.period(tt.args.startDate, tt.args.endDate)
+			if gotStart != tt.wantStart || gotEnd != tt.wantEnd {
+				t.Errorf("reconciledDays.period() = %v, %v, want %v, %v", gotStart, gotEnd, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func Test_checksumUploads(t *testing.T) {
+	bankStatements := nopMultipartFile{bytes.NewReader([]byte("abc"))}
+	tests := []struct {
+		name    string
+		param   transactions.DoReconciliationRequest
+		want    []transactions.FileChecksum
+		wantErr bool
+	}{
+		{
+			name: "Succesful",
+			param: transactions.DoReconciliationRequest{
+				SystemTransactions: nopMultipartFile{bytes.NewReader([]byte(""))},
+				BankStatements: []transactions.BankStatementsUpload{
+					{File: bankStatements, Name: "bca.csv"},
+				},
+			},
+			want: []transactions.FileChecksum{
+				{File: "system_transactions", SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+				{File: "bank_statements", Name: "bca.csv", SHA256: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checksumUploads(tt.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checksumUploads() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checksumUploads() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// the files are read again by the reconciliation
+	content, _ := io.ReadAll(bankStatements)
+	if string(content) != "abc" {
+		t.Errorf("checksumUploads() left the file at %q", content)
+	}
+}
+
+func Test_runParameters(t *testing.T) {
+	tests := []struct {
+		name  string
+		param transactions.DoReconciliationRequest
+		want  transactions.RunParameters
+	}{
+		{
+			name: "Succesful",
+			param: transactions.DoReconciliationRequest{
+				DateToleranceDays:      1,
+				AmountTolerance:        money.MustParse("500", money.DefaultCurrency),
+				AmountTolerancePercent: 0.5,
+				StartDate:              time.Date(2024, time.Month(1), 3, 0, 0, 0, 0, time.UTC),
+				EndDate:                time.Date(2024, time.Month(1), 4, 0, 0, 0, 0, time.UTC),
+				BankStatementsOptions:  transactions.FileOptions{Format: "bca"},
+				ReportingCurrency:      "IDR",
+				ValidationMode:         transactions.ValidationLenient,
+			},
+			want: transactions.RunParameters{
+				StartDate:              "2024-01-03",
+				EndDate:                "2024-01-04",
+				DateToleranceDays:      1,
+				AmountTolerance:        "500.00",
+				AmountTolerancePercent: 0.5,
+				ReportingCurrency:      "IDR",
+				ValidationMode:         transactions.ValidationLenient,
+				BankStatementsFormat:   "bca",
+				BankSourcePattern:      `^(?P<bank>[^_]*)_[^_]*$`,
+				TimeZone:               "Asia/Jakarta",
+				BankStatementsOptions: transactions.RunFileOptions{
+					NumberFormat: money.FormatEnglish,
+					Currency:     "IDR",
+					TimeZone:     "Asia/Jakarta",
+				},
+				SystemTransactionsOptions: transactions.RunFileOptions{
+					NumberFormat: money.FormatEnglish,
+					Currency:     "IDR",
+					TimeZone:     "Asia/Jakarta",
+				},
+			},
+		},
+		{
+			name: "Succesful with file options",
+			param: transactions.DoReconciliationRequest{
+				AmountTolerance: money.MustParse("0", money.DefaultCurrency),
+				BankStatementsOptions: transactions.FileOptions{
+					NumberFormat:      money.FormatIndonesian,
+					Currency:          "USD",
+					Format:            "auto",
+					Sheet:             "Mutasi",
+					HeaderRow:         3,
+					DateFormat:        dates.FormatISO8601,
+					Location:          time.UTC,
+					BankSourcePattern: regexp.MustCompile(`^(?P<bank>[A-Z]+)-`),
+				},
+				SystemTransactionsOptions: transactions.FileOptions{
+					NumberFormat: money.FormatAuto,
+					DateFormat:   dates.Format("02/01/2006 15:04"),
+					Location:     time.FixedZone("WITA", 8*60*60),
+					Layout: transactions.ColumnLayout{
+						Columns:    map[string]string{"amount": "2"},
+						Headerless: true,
+						Delimiter:  ";",
+						Quote:      "'",
+					},
+				},
+			},
+			want: transactions.RunParameters{
+				AmountTolerance:      "0.00",
+				BankStatementsFormat: "auto",
+				BankSourcePattern:    `^(?P<bank>[A-Z]+)-`,
+				TimeZone:             "UTC",
+				BankStatementsOptions: transactions.RunFileOptions{
+					NumberFormat: money.FormatIndonesian,
+					Currency:     "USD",
+					DateFormat:   dates.FormatISO8601,
+					TimeZone:     "UTC",
+					Sheet:        "Mutasi",
+					HeaderRow:    3,
+				},
+				SystemTransactionsOptions: transactions.RunFileOptions{
+					NumberFormat: money.FormatAuto,
+					Currency:     "IDR",
+					DateFormat:   dates.Format("02/01/2006 15:04"),
+					TimeZone:     "WITA",
+					Layout: transactions.ColumnLayout{
+						Columns:    map[string]string{"amount": "2"},
+						Headerless: true,
+						Delimiter:  ";",
+						Quote:      "'",
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runParameters(tt.param); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runParameters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransactionUsecase_GetReconciliationRuns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Mock ReconciliationRepository
+	mockRepository := repositoryMock.NewMockReconciliationRepository(ctrl)
+	runs := []transactions.ReconciliationRunSummary{{ID: "run-1", Status: transactions.RunSucceeded}}
+
+	tests := []struct {
+		name       string
+		usecase    TransactionUsecase
+		param      transactions.GetReconciliationRunsRequest
+		wantResult transactions.GetReconciliationRunsResponse
+		wantErr    bool
+		mock       func()
+	}{
+		{
+			name:    "Succesful with default page",
+			usecase: TransactionUsecase{ReconciliationRepository: mockRepository},
+			param:   transactions.GetReconciliationRunsRequest{BankSource: "BRI"},
+			wantResult: transactions.GetReconciliationRunsResponse{
+				Runs:     runs,
+				Page:     1,
+				PageSize: defaultRunsPageSize,
+				Total:    21,
+			},
+			wantErr: false,
+			mock: func() {
+				mockRepository.EXPECT().GetRuns(gomock.Any(), transactions.GetReconciliationRunsRequest{
+					BankSource: "BRI",
+					Page:       1,
+					PageSize:   defaultRunsPageSize,
+				}).Return(runs, 21, nil)
+			},
+		},
+		{
+			name:       "Failed without repository",
+			usecase:    TransactionUsecase{},
+			param:      transactions.GetReconciliationRunsRequest{},
+			wantResult: transactions.GetReconciliationRunsResponse{},
+			wantErr:    true,
+			mock:       func() {},
+		},
+		{
+			name:       "Failed with page size too big",
+			usecase:    TransactionUsecase{ReconciliationRepository: mockRepository},
+			param:      transactions.GetReconciliationRunsRequest{PageSize: maxRunsPageSize + 1},
+			wantResult: transactions.GetReconciliationRunsResponse{},
+			wantErr:    true,
+			mock:       func() {},
+		},
+		{
+			name:       "Failed with negative page",
+			usecase:    TransactionUsecase{ReconciliationRepository: mockRepository},
+			param:      transactions.GetReconciliationRunsRequest{Page: -1},
+			wantResult: transactions.GetReconciliationRunsResponse{},
+			wantErr:    true,
+			mock:       func() {},
+		},
+		{
+			name:    "Failed with start date after end date",
+			usecase: TransactionUsecase{ReconciliationRepository: mockRepository},
+			param: transactions.GetReconciliationRunsRequest{
+				StartDate: time.Date(2024, time.Month(1), 4, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2024, time.Month(1), 3, 0, 0, 0, 0, time.UTC),
+			},
+			wantResult: transactions.GetReconciliationRunsResponse{},
+			wantErr:    true,
+			mock:       func() {},
+		},
+		{
+			name:       "Failed with unknown status",
+			usecase:    TransactionUsecase{ReconciliationRepository: mockRepository},
+			param:      transactions.GetReconciliationRunsRequest{Status: "running"},
+			wantResult: transactions.GetReconciliationRunsResponse{},
+			wantErr:    true,
+			mock:       func() {},
+		},
+		{
+			name:       "Failed reading runs",
+			usecase:    TransactionUsecase{ReconciliationRepository: mockRepository},
+			param:      transactions.GetReconciliationRunsRequest{},
+			wantResult: transactions.GetReconciliationRunsResponse{},
+			wantErr:    true,
+			mock: func() {
+				mockRepository.EXPECT().GetRuns(gomock.Any(), gomock.Any()).Return(nil, 0, errMock)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			gotResult, err := tt.usecase.GetReconciliationRuns(context.Background(), tt.param)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TransactionUsecase.GetReconciliationRuns() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResult, tt.wantResult) {
+				t.Errorf("TransactionUsecase.GetReconciliationRuns() = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestTransactionUsecase_GetReconciliationRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Mock ReconciliationRepository
+	mockRepository := repositoryMock.NewMockReconciliationRepository(ctrl)
+	run := transactions.ReconciliationRun{ID: "run-1", Status: transactions.RunSucceeded}
+
+	tests := []struct {
+		name    string
+		usecase TransactionUsecase
+		id      string
+		want    transactions.ReconciliationRun
+		wantErr error
+		mock    func()
+	}{
+		{
+			name:    "Succesful",
+			usecase: TransactionUsecase{ReconciliationRepository: mockRepository},
+			id:      "run-1",
+			want:    run,
+			wantErr: nil,
+			mock: func() {
+				mockRepository.EXPECT().GetRun(gomock.Any(), "run-1").Return(run, nil)
+			},
+		},
+		{
+			name:    "Failed with unknown id",
+			usecase: TransactionUsecase{ReconciliationRepository: mockRepository},
+			id:      "run-2",
+			want:    transactions.ReconciliationRun{},
+			wantErr: libError.NewNotFoundError("reconciliation run run-2 is not found"),
+			mock: func() {
+				mockRepository.EXPECT().GetRun(gomock.Any(), "run-2").Return(transactions.ReconciliationRun{}, repositories.ErrRunNotFound)
+			},
+		},
+		{
+			name:    "Failed without repository",
+			usecase: TransactionUsecase{},
+			id:      "run-1",
+			want:    transactions.ReconciliationRun{},
+			wantErr: libError.NewBadRequestError("reconciliation runs are not stored"),
+			mock:    func() {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := tt.usecase.GetReconciliationRun(context.Background(), tt.id)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("TransactionUsecase.GetReconciliationRun() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TransactionUsecase.GetReconciliationRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
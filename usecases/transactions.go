@@ -1,11 +1,22 @@
 package usecase
 
 import (
+	"amartha-test/dates"
+	"amartha-test/entities/parsers"
+	"amartha-test/entities/repositories"
 	"amartha-test/entities/transactions"
 	libError "amartha-test/errors"
+	"amartha-test/extsort"
+	"amartha-test/money"
+	"amartha-test/spreadsheets"
+	"bytes"
 	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"os"
 	"regexp"
 	"sort"
 	"strconv"
@@ -21,249 +32,1177 @@ const (
 )
 
 var (
-	gocsvUnmarshalMultipartFile = gocsv.UnmarshalMultipartFile
+	gocsvUnmarshalMultipartFile           = gocsv.UnmarshalMultipartFile
+	gocsvNewMultipartFileUnmarshaller = func(in *multipart.File, out interface{}) (*gocsv.Unmarshaller, *csv.Reader, error) {
+		reader := csv.NewReader(*in)
+		unmarshaller, err := gocsv.NewUnmarshaller(reader, out)
+		return unmarshaller, reader, err
+	}
+	spreadsheetToCsv = spreadsheets.ToCSV
+	remapCsvColumns  = spreadsheets.RemapColumns
 )
 
+// defaultSortBufferSize is the number of records of a file sorted in memory before they are written to disk
+const defaultSortBufferSize = 100000
+
 type TransactionUsecase struct {
+	BankStatementsParsers map[string]parsers.BankStatementsParser // parsers of bank statements exports by their format name
+
+	// records of a file are sorted in memory up to SortBufferSize records, defaultSortBufferSize when 0, the
+	// sorted runs of bigger files are written to TempDir, the default directory for temporary files when empty
+	SortBufferSize int
+	TempDir        string
+
+	ReconciliationRepository repositories.ReconciliationRepository // stores every run, runs are not stored when nil
 }
 
 func NewTransactionUsecase(usecase TransactionUsecase) TransactionUsecase {
 	return usecase
 }
 
-// ConvertCurrencyToFloat converts a currency string like "-Rp1,000,000", "Rp1,000,000", "$1,000,000" or "€1,000,000" to float64
-func convertCurrencyToFloat(currency string) (float64, error) {
-	// Define a regex pattern to match any currency symbols and whitespace
-	regexPattern := regexp.MustCompile(`[^\d.-]`)
+// convertCurrencyToMoney converts a currency string like "-Rp1,000,000", "Rp1.000.000,00", "$1,000,000" or "€1,000,000"
+// to exact money, reading the separators with the number format of the file
+func convertCurrencyToMoney(currency string, options transactions.FileOptions) (money.Money, error) {
+	options = options.WithDefaults()
+	return money.ParseAmount(currency, options.NumberFormat, options.Currency)
+}
+
+// unmarshalCsvToCallback reads every row of a csv file into add with the line of the row in the file, blank lines
+// and quoted line breaks included. Rows are unmarshalled one at a time so the reader is still on the row of add
+func unmarshalCsvToCallback[T any](file *multipart.File, add func(d *T, line int) error) error {
+	unmarshaller, reader, err := gocsvNewMultipartFileUnmarshaller(file, new(T))
+	if err != nil {
+		return err
+	}
+
+	for {
+		row, err := unmarshaller.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+		err = add(row.(*T), line)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// unmarshalCsvToStructForBankStatements reads every row of a bank statements csv into add, one row at a time
+var unmarshalCsvToStructForBankStatements = func(file *multipart.File, add func(*transactions.BankStatements) error) error {
+	return unmarshalCsvToCallback(file, func(d *transactions.BankStatements, line int) error {
+		d.Line = line
+		return add(d)
+	})
+}
+
+// unmarshalCsvToStructForSystemTransactions reads every row of a system transactions csv into add, one row at a time
+var unmarshalCsvToStructForSystemTransactions = func(file *multipart.File, add func(*transactions.SystemTransactions) error) error {
+	return unmarshalCsvToCallback(file, func(d *transactions.SystemTransactions, line int) error {
+		d.Line = line
+		return add(d)
+	})
+}
+
+// memoryFile is an uploaded file converted in memory
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
+
+// tempFile is an uploaded file converted to a temporary file, the file is removed when it is closed
+type tempFile struct {
+	*os.File
+}
+
+func (f tempFile) Close() error {
+	return errors.Join(f.File.Close(), os.Remove(f.File.Name()))
+}
+
+// closeConverted closes a file converted from an upload, the upload itself is closed by its owner
+func closeConverted(converted, upload multipart.File) {
+	if converted != nil && converted != upload {
+		converted.Close()
+	}
+}
+
+// rewind moves an uploaded file back to its start so it can be read again
+func rewind(file multipart.File) error {
+	if file == nil {
+		return nil
+	}
+	_, err := file.Seek(0, io.SeekStart)
+	return err
+}
+
+// readSpreadsheet converts an uploaded xlsx workbook to csv so it is read like a csv upload, other files are
+// returned as uploaded. Date cells are written with formatDate, or as formatted in the sheet when it is nil. rows
+// maps the lines of the csv to the rows of the sheet, it is nil for other files
+func readSpreadsheet(file multipart.File, options transactions.FileOptions, formatDate func(time.Time) string) (multipart.File, []int, error) {
+	if file == nil {
+		return file, nil, nil
+	}
+
+	head := make([]byte, 4)
+	length, err := file.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	if !spreadsheets.IsXLSX(head[:length]) {
+		return file, nil, nil
+	}
+
+	content, rows, err := spreadsheetToCsv(file, options.Sheet, options.HeaderRow, formatDate)
+	if err != nil {
+		return nil, nil, libError.NewBadRequestError(fmt.Sprintf("xlsx file is invalid, %s", err))
+	}
+	return memoryFile{bytes.NewReader(content)}, rows, nil
+}
+
+// readColumnLayout rewrites a csv upload written with the column layout of the options to the default csv in a
+// temporary file in dir, the default csv is returned as uploaded. rows maps the lines of the rewritten csv to the
+// lines of the upload, it is nil when the upload is not rewritten
+func readColumnLayout(file multipart.File, layout transactions.ColumnLayout, dir string) (multipart.File, []int, error) {
+	if file == nil || layout.IsDefault() {
+		return file, nil, nil
+	}
 
-	// Remove all matches of the pattern
-	cleanedCurrency := regexPattern.ReplaceAllString(currency, "")
+	converted, err := os.CreateTemp(dir, "layout-*.csv")
+	if err != nil {
+		return nil, nil, err
+	}
+	result := tempFile{converted}
 
-	// Convert the resulting string to float64
-	value, err := strconv.ParseFloat(cleanedCurrency, 64)
+	delimiter, quote := layout.Separators()
+	rows, err := remapCsvColumns(file, converted, spreadsheets.Layout{
+		Columns:    layout.Columns,
+		Headerless: layout.Headerless,
+		Delimiter:  delimiter,
+		Quote:      quote,
+	})
 	if err != nil {
-		return 0, err
+		result.Close()
+		return nil, nil, libError.NewBadRequestError(fmt.Sprintf("csv file does not match the column layout, %s", err))
 	}
 
-	return value, nil
+	err = rewind(result)
+	if err != nil {
+		result.Close()
+		return nil, nil, err
+	}
+	return result, rows, nil
 }
 
-var unmarshalCsvToStructForBankStatements = func (file *multipart.File) (result []*transactions.BankStatements, err error) {
-	err = gocsvUnmarshalMultipartFile(file, &result)
+// sourceLine returns the line or sheet row of the upload of a line of the csv converted from the upload, lines of
+// uploads that are not converted are returned as they are
+func sourceLine(rows []int, line int) int {
+	if line < 1 || line > len(rows) {
+		return line
+	}
+	return rows[line-1]
+}
+
+// withoutSeparators keeps only the columns of the layout of a workbook, a workbook is converted to csv
+// separated by comma
+func withoutSeparators(options transactions.FileOptions) transactions.FileOptions {
+	options.Layout.Delimiter, options.Layout.Quote = "", ""
+	return options
+}
+
+// readBankStatements reads every bank statement of an uploaded csv, xlsx or bank export file into add, with the
+// options that describe how the values of the bank statement are written. csv files are read one row at a time
+func (usecase TransactionUsecase) readBankStatements(file *multipart.File, options transactions.FileOptions, add func(*transactions.BankStatements, transactions.FileOptions) error) error {
+	// the parsers of the bank exports read the dates as formatted in their sheets
+	var formatDate func(time.Time) string
+	if options.Format == "" || options.Format == parsers.FormatCSV {
+		formatDate = spreadsheetDate(options, dateFormat)
+	}
+	converted, rows, err := readSpreadsheet(*file, options, formatDate)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return
+	if rows != nil {
+		options = withoutSeparators(options)
+	}
+
+	return usecase.parseBankStatements(&converted, options, func(d *transactions.BankStatements, options transactions.FileOptions) error {
+		d.Line = sourceLine(rows, d.Line)
+		return add(d, options)
+	})
 }
 
+// readSystemTransactions reads every system transaction of an uploaded csv or xlsx file into add, csv files are
+// read one row at a time
+func (usecase TransactionUsecase) readSystemTransactions(file *multipart.File, options transactions.FileOptions, add func(*transactions.SystemTransactions) error) error {
+	converted, rows, err := readSpreadsheet(*file, options, spreadsheetDate(options, dateTimeFormat))
+	if err != nil {
+		return err
+	}
+	if rows != nil {
+		options = withoutSeparators(options)
+	}
 
-var unmarshalCsvToStructForSystemTransactions = func (file *multipart.File) (result []*transactions.SystemTransactions, err error) {
-	err = gocsvUnmarshalMultipartFile(file, &result)
+	converted, layoutRows, err := readColumnLayout(converted, options.Layout, usecase.TempDir)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return
+	defer closeConverted(converted, *file)
+
+	return unmarshalCsvToStructForSystemTransactions(&converted, func(d *transactions.SystemTransactions) error {
+		d.Line = sourceLine(rows, sourceLine(layoutRows, d.Line))
+		return add(d)
+	})
 }
 
-// function to validate bank statement data
-var validateBankStatementsData = func(data []*transactions.BankStatements) (err error) {
-	for index, d := range data {
-		// convert string with currency to real amount
-		data[index].RealAmount, err = convertCurrencyToFloat(d.Amount)
+// formatDetectionSize is the length of the beginning of a bank statements file read to detect its format
+const formatDetectionSize = 64 << 10
+
+// parseBankStatements reads a bank statements file with the parser of the format of the options into add, with
+// the options that describe how the values of the bank statement are written
+func (usecase TransactionUsecase) parseBankStatements(file *multipart.File, options transactions.FileOptions, add func(*transactions.BankStatements, transactions.FileOptions) error) error {
+	format := options.Format
+	if format == parsers.FormatAuto {
+		// the markers of every format are at the beginning of the file
+		head := make([]byte, formatDetectionSize)
+		length := 0
+		if *file != nil {
+			var err error
+			length, err = (*file).ReadAt(head, 0)
+			if err != nil && err != io.EOF {
+				return err
+			}
+		}
+		format = usecase.detectBankStatementsFormat(head[:length])
+	}
+
+	if format == "" || format == parsers.FormatCSV {
+		return usecase.readBankStatementsCsv(*file, options, add)
+	}
+
+	parser, ok := usecase.BankStatementsParsers[format]
+	if !ok {
+		return libError.NewBadRequestError(fmt.Sprintf("bank statements format %s is not supported", format))
+	}
 
+	data, err := parser.Parse(*file)
+	if err != nil {
+		return libError.NewBadRequestError(fmt.Sprintf("bank statements file is invalid, %s", err))
+	}
+
+	// every parser writes the dates with the csv date layout
+	options.NumberFormat = parser.NumberFormat()
+	options.DateFormat = dateFormat
+	for _, d := range data {
+		err = add(d, options)
 		if err != nil {
-			return libError.NewBadRequestError("amount format in bank statements data is invalid")
+			return err
+		}
+	}
+	return nil
+}
+
+// readBankStatementsCsv reads a bank statements csv written with the column layout of the options into add
+func (usecase TransactionUsecase) readBankStatementsCsv(file multipart.File, options transactions.FileOptions, add func(*transactions.BankStatements, transactions.FileOptions) error) error {
+	converted, rows, err := readColumnLayout(file, options.Layout, usecase.TempDir)
+	if err != nil {
+		return err
+	}
+	defer closeConverted(converted, file)
+
+	return unmarshalCsvToStructForBankStatements(&converted, func(d *transactions.BankStatements) error {
+		d.Line = sourceLine(rows, d.Line)
+		return add(d, options)
+	})
+}
+
+// detectBankStatementsFormat returns the format of the first parser that recognizes the content, in order of
+// their name, or the csv format when no parser does
+func (usecase TransactionUsecase) detectBankStatementsFormat(content []byte) string {
+	formats := make([]string, 0, len(usecase.BankStatementsParsers))
+	for format := range usecase.BankStatementsParsers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	for _, format := range formats {
+		if usecase.BankStatementsParsers[format].Detect(content) {
+			return format
 		}
+	}
+	return parsers.FormatCSV
+}
+
+// function to validate bank statement data, it returns the valid bank statements and an error for every invalid value
+var validateBankStatementsData = func(data []*transactions.BankStatements, options transactions.FileOptions) (result []*transactions.BankStatements, rowErrors []transactions.RowError) {
+	options = options.WithDefaults()
+	for _, d := range data {
+		file := d.SourceFile
+		if file == "" {
+			file = transactions.BankStatementsFile
+		}
+		rowError := func(column, value, reason string) {
+			rowErrors = append(rowErrors, transactions.RowError{
+				File:   file,
+				Line:   d.Line,
+				Column: column,
+				Value:  value,
+				Reason: reason,
+			})
+		}
+		valid := true
 
-		var timeParsed time.Time
+		// convert string with currency to real amount
+		realAmount, err := convertCurrencyToMoney(d.Amount, options)
+		if err != nil {
+			rowError("amount", d.Amount, fmt.Sprintf("amount format is invalid, %s", err))
+			valid = false
+		}
+		d.RealAmount = realAmount
+		d.Currency = realAmount.Currency
 
 		// convert time in string to time format
-		timeParsed, err = time.Parse(dateFormat, d.Date)
+		format := withDefaultDateFormat(options.DateFormat, dateFormat)
+		location := options.WithDefaults().Location
+		timeParsed, err := dates.Parse(d.Date, format, location)
 		if err != nil {
-			return libError.NewBadRequestError(fmt.Sprintf("date format in bank statements data is invalid, use this format %s", dateFormat))
+			rowError("date", d.Date, fmt.Sprintf("date format is invalid, use this format %s", format))
+			valid = false
 		}
-		data[index].RealDate = time.Date(timeParsed.Year(), timeParsed.Month(), timeParsed.Day(), 0, 0, 0, 0, time.Local)
+		d.RealDate = startOfDay(timeParsed, location)
 
 		// get transaction type
-		if data[index].RealAmount < 0 {
-			data[index].Type = transactions.DEBIT
+		if d.RealAmount.IsNegative() {
+			d.Type = transactions.DEBIT
 		} else {
-			data[index].Type = transactions.CREDIT
+			d.Type = transactions.CREDIT
 		}
 
-		dataIDData := strings.Split(d.ID, "_")
-		if len(dataIDData) == 2 {
-			data[index].BankSource = dataIDData[0]
-		} else {
-			return libError.NewBadRequestError("unique_identifier data in bank statements is invalid")
+		d.BankSource, err = extractBankSource(d, options.BankSourcePattern)
+		if err != nil {
+			rowError("unique_identifier", d.ID, err.Error())
+			valid = false
 		}
 
+		if valid {
+			result = append(result, d)
+		}
 	}
 
 	return
 }
 
-// function to validate system transaction data
-var validateSystemTransactionsData = func (data []*transactions.SystemTransactions) (err error) {
-	for index, d := range data {
+// function to validate system transaction data, it returns the valid system transactions and an error for every invalid value
+var validateSystemTransactionsData = func (data []*transactions.SystemTransactions, options transactions.FileOptions) (result []*transactions.SystemTransactions, rowErrors []transactions.RowError) {
+	for _, d := range data {
+		rowError := func(column, value, reason string) {
+			rowErrors = append(rowErrors, transactions.RowError{
+				File:   transactions.SystemTransactionsFile,
+				Line:   d.Line,
+				Column: column,
+				Value:  value,
+				Reason: reason,
+			})
+		}
+		valid := true
+
 		// convert string with currency to real amount
-		data[index].RealAmount, err = convertCurrencyToFloat(d.Amount)
+		realAmount, err := convertCurrencyToMoney(d.Amount, options)
 		if err != nil {
-			return libError.NewBadRequestError("amount format in system transaction data is invalid")
+			rowError("amount", d.Amount, fmt.Sprintf("amount format is invalid, %s", err))
+			valid = false
 		}
-
-		var timeParsed time.Time
+		d.RealAmount = realAmount
+		d.Currency = realAmount.Currency
 
 		// convert time in string to time format
-		timeParsed, err = time.Parse(dateTimeFormat, d.TransactionTime)
+		// the time is kept so it can be moved to the booking day of the bank
+		format := withDefaultDateFormat(options.DateFormat, dateTimeFormat)
+		timeParsed, err := dates.Parse(d.TransactionTime, format, options.WithDefaults().Location)
+		if err != nil {
+			rowError("transactionTime", d.TransactionTime, fmt.Sprintf("date format is invalid, use this format %s", format))
+			valid = false
+		}
+		d.RealTransactionTime = timeParsed
+
+		// get transaction type
+		d.Type, err = parseTransactionType(d.RawType, d.RealAmount)
 		if err != nil {
-			return libError.NewBadRequestError(fmt.Sprintf("date format in system transaction data is invalid, use this format %s", dateTimeFormat))
+			rowError("type", d.RawType, err.Error())
+			valid = false
 		}
-		data[index].RealTransactionTime = time.Date(timeParsed.Year(), timeParsed.Month(), timeParsed.Day(), 0, 0, 0, 0, time.Local)
 
+		if valid {
+			result = append(result, d)
+		}
 	}
 
 	return
 }
 
-// findBankStatementData performs a binary search on the sorted bank statements array with the same date and type
-func findBankStatementData(statements []*transactions.BankStatements, targetDate time.Time, targetType int) *transactions.BankStatements {
-	left, right := 0, len(statements)-1
+// startOfDay returns the start of the day of the time in the location
+func startOfDay(date time.Time, location *time.Location) time.Time {
+	date = date.In(location)
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location)
+}
+
+// calendarDay returns the start of the same calendar date in the location, a zero date stays zero
+func calendarDay(date time.Time, location *time.Location) time.Time {
+	if date.IsZero() {
+		return date
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location)
+}
 
-	for left <= right {
-		mid := (left + right) / 2
-		midStatement := statements[mid]
+// toBookingDay moves the transaction time of every system transaction to the start of the day it falls on in the
+// time zone the bank books its statements in, a transaction at 23:30 UTC is booked the next day in Asia/Jakarta
+func toBookingDay(data []*transactions.SystemTransactions, location *time.Location) {
+	for _, d := range data {
+		d.RealTransactionTime = startOfDay(d.RealTransactionTime, location)
+	}
+}
 
-		if midStatement.RealDate.Equal(targetDate) && midStatement.Type == targetType {
-			return midStatement
+// spreadsheetDate returns how the date cells of a workbook are written so they are read with the date format of the
+// options, or with defaultLayout when it is not set. Date cells have no time zone, they are in the time zone of the
+// options like the dates of a csv upload
+func spreadsheetDate(options transactions.FileOptions, defaultLayout string) func(time.Time) string {
+	format := withDefaultDateFormat(options.DateFormat, defaultLayout)
+	location := options.WithDefaults().Location
+	return func(date time.Time) string {
+		switch format {
+		case dates.FormatUnix, dates.FormatUnixMilli:
+			local := time.Date(date.Year(), date.Month(), date.Day(), date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), location)
+			if format == dates.FormatUnixMilli {
+				return strconv.FormatInt(local.UnixMilli(), 10)
+			}
+			return strconv.FormatInt(local.Unix(), 10)
+		case dates.FormatISO8601, dates.FormatAuto:
+			return date.Format("2006-01-02T15:04:05")
 		}
+		return date.Format(string(format))
+	}
+}
 
-		if midStatement.RealDate.Before(targetDate) || (midStatement.RealDate.Equal(targetDate) && midStatement.Type < targetType) {
-			left = mid + 1
-		} else {
-			right = mid - 1
+// withDefaultDateFormat returns the date format of the options, or the default layout of the column when it is not set
+func withDefaultDateFormat(format dates.Format, defaultLayout string) dates.Format {
+	if format == "" {
+		return dates.Format(defaultLayout)
+	}
+	return format
+}
+
+// detectedDateFormat returns the date format detected from the values of a column of an uploaded file
+func detectedDateFormat(detector *dates.Detector, file, column string) (dates.Format, error) {
+	detected, err := detector.Format()
+	if err != nil {
+		return "", libError.NewBadRequestError(fmt.Sprintf("date format of column %s in %s can not be detected, %s, set the date format of the file", column, file, err))
+	}
+	return detected, nil
+}
+
+// extractBankSource returns the bank column of the bank statement, or the bank group of the pattern matched
+// against its unique_identifier when the bank column is empty
+func extractBankSource(bankStatement *transactions.BankStatements, pattern *regexp.Regexp) (string, error) {
+	if bank := strings.TrimSpace(bankStatement.Bank); bank != "" {
+		return bank, nil
+	}
+
+	match := pattern.FindStringSubmatch(bankStatement.ID)
+	if match == nil {
+		if pattern == transactions.DefaultBankSourcePattern {
+			return "", errors.New("unique_identifier must contain the bank source, for example BCA_123")
 		}
+		return "", fmt.Errorf("unique_identifier does not match the bank source pattern %s", pattern)
 	}
 
-	return nil
+	return match[pattern.SubexpIndex(transactions.BankSourceGroup)], nil
 }
 
-// findSystemTransactionData performs a binary search on the sorted system transaction array with the same date and type
-func findSystemTransactionData(transaction []*transactions.SystemTransactions, targetDate time.Time, targetType int) *transactions.SystemTransactions {
-	left, right := 0, len(transaction)-1
+// parseTransactionType reads the type column of a system transaction written as 1, 2, DEBIT or CREDIT. When the column
+// is empty the type follows the sign of the amount like bank statements, negative amounts are DEBIT. A negative amount
+// with CREDIT type is rejected because both directions contradict each other
+func parseTransactionType(rawType string, amount money.Money) (int, error) {
+	transactionType := 0
+	switch strings.ToUpper(strings.TrimSpace(rawType)) {
+	case "":
+		if amount.IsNegative() {
+			return transactions.DEBIT, nil
+		}
+		return transactions.CREDIT, nil
+	case "1", "DEBIT":
+		transactionType = transactions.DEBIT
+	case "2", "CREDIT":
+		transactionType = transactions.CREDIT
+	default:
+		return 0, fmt.Errorf("type must be %d or DEBIT, %d or CREDIT, or empty with a signed amount", transactions.DEBIT, transactions.CREDIT)
+	}
 
-	for left <= right {
-		mid := (left + right) / 2
-		midStatement := transaction[mid]
+	if transactionType == transactions.CREDIT && amount.IsNegative() {
+		return 0, errors.New("CREDIT transaction can not have a negative amount")
+	}
+	return transactionType, nil
+}
+
+// inDateRange checks whether the date is inside the range, a zero start or end date leaves that side of the range open
+func inDateRange(date, startDate, endDate time.Time) bool {
+	if !startDate.IsZero() && date.Before(startDate) {
+		return false
+	}
+	if !endDate.IsZero() && date.After(endDate) {
+		return false
+	}
+	return true
+}
+
+// widenDateRange returns the range with the days a tolerance away from each side that is set, the records of those
+// days can still be the counterpart of a record inside the range
+func widenDateRange(startDate, endDate time.Time, toleranceDays int) (time.Time, time.Time) {
+	if !startDate.IsZero() {
+		startDate = startDate.AddDate(0, 0, -toleranceDays)
+	}
+	if !endDate.IsZero() {
+		endDate = endDate.AddDate(0, 0, toleranceDays)
+	}
+	return startDate, endDate
+}
 
-		if midStatement.RealTransactionTime.Equal(targetDate) && midStatement.Type == targetType {
-			return midStatement
+// filterBankStatementsByDate keeps the bank statements dated inside the range
+func filterBankStatementsByDate(data []*transactions.BankStatements, startDate, endDate time.Time) (result []*transactions.BankStatements) {
+	for _, d := range data {
+		if inDateRange(d.RealDate, startDate, endDate) {
+			result = append(result, d)
 		}
+	}
+	return
+}
 
-		if midStatement.RealTransactionTime.Before(targetDate) || (midStatement.RealTransactionTime.Equal(targetDate) && midStatement.Type < targetType) {
-			left = mid + 1
-		} else {
-			right = mid - 1
+// filterSystemTransactionsByDate keeps the system transactions dated inside the range
+func filterSystemTransactionsByDate(data []*transactions.SystemTransactions, startDate, endDate time.Time) (result []*transactions.SystemTransactions) {
+	for _, d := range data {
+		if inDateRange(d.RealTransactionTime, startDate, endDate) {
+			result = append(result, d)
 		}
 	}
+	return
+}
+
+// validationBatchSize is the number of rows validated at once while an uploaded file is read
+const validationBatchSize = 1000
+
+// streamBankStatements reads, tags and validates the bank statements of an uploaded file into add in batches, so
+// the file doesn't have to be kept in memory. It returns the number of rows of the file
+func (usecase TransactionUsecase) streamBankStatements(upload transactions.BankStatementsUpload, options transactions.FileOptions, add func(valid []*transactions.BankStatements, rowErrors []transactions.RowError) error) (rows int, err error) {
+	file := transactions.BankStatementsFile
+	if upload.Name != "" {
+		file = upload.Name
+	}
 
-	return nil
+	// every file can be written with its own date layout, it is detected before any row is validated
+	if options.DateFormat == dates.FormatAuto {
+		detector := dates.NewDetector()
+		detected := false
+		err = usecase.readBankStatements(&upload.File, options, func(d *transactions.BankStatements, options transactions.FileOptions) error {
+			// parsers write the dates with their own layout
+			if options.DateFormat == dates.FormatAuto {
+				detector.Add(d.Date)
+				detected = true
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		if detected {
+			options.DateFormat, err = detectedDateFormat(detector, file, "date")
+			if err != nil {
+				return 0, err
+			}
+		}
+		err = rewind(upload.File)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	batch := make([]*transactions.BankStatements, 0, validationBatchSize)
+	var batchOptions transactions.FileOptions
+	validate := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		valid, rowErrors := validateBankStatementsData(batch, batchOptions)
+		batch = batch[:0]
+		return add(valid, rowErrors)
+	}
+
+	err = usecase.readBankStatements(&upload.File, options, func(d *transactions.BankStatements, options transactions.FileOptions) error {
+		rows++
+		d.SourceFile = upload.Name
+		if d.Bank == "" {
+			d.Bank = upload.Bank
+		}
+
+		batch = append(batch, d)
+		batchOptions = options
+		if len(batch) < validationBatchSize {
+			return nil
+		}
+		return validate()
+	})
+	if err != nil {
+		return rows, err
+	}
+	return rows, validate()
+}
+
+// streamSystemTransactions reads and validates the system transactions of the uploaded file into add in batches,
+// so the file doesn't have to be kept in memory. It returns the number of rows of the file
+func (usecase TransactionUsecase) streamSystemTransactions(file multipart.File, options transactions.FileOptions, add func(valid []*transactions.SystemTransactions, rowErrors []transactions.RowError) error) (rows int, err error) {
+	if options.DateFormat == dates.FormatAuto {
+		detector := dates.NewDetector()
+		err = usecase.readSystemTransactions(&file, options, func(d *transactions.SystemTransactions) error {
+			detector.Add(d.TransactionTime)
+			rows++
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		// an empty file is reported by the caller
+		if rows == 0 {
+			return 0, nil
+		}
+		options.DateFormat, err = detectedDateFormat(detector, transactions.SystemTransactionsFile, "transactionTime")
+		if err != nil {
+			return 0, err
+		}
+		err = rewind(file)
+		if err != nil {
+			return 0, err
+		}
+		rows = 0
+	}
+
+	batch := make([]*transactions.SystemTransactions, 0, validationBatchSize)
+	validate := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		valid, rowErrors := validateSystemTransactionsData(batch, options)
+		batch = batch[:0]
+		return add(valid, rowErrors)
+	}
+
+	err = usecase.readSystemTransactions(&file, options, func(d *transactions.SystemTransactions) error {
+		rows++
+		batch = append(batch, d)
+		if len(batch) < validationBatchSize {
+			return nil
+		}
+		return validate()
+	})
+	if err != nil {
+		return rows, err
+	}
+	return rows, validate()
+}
+
+// sortedDays reads the records of a sorter one day at a time
+type sortedDays[T any] struct {
+	iterator *extsort.Iterator[T]
+	day      func(T) time.Time
+	next     T
+	ok       bool
+}
+
+// readSortedDays sorts the records of the sorter to read them one day at a time, day returns the start of the day
+// of a record
+func readSortedDays[T any](sorter *extsort.Sorter[T], day func(T) time.Time) (*sortedDays[T], error) {
+	iterator, err := sorter.Sorted()
+	if err != nil {
+		return nil, err
+	}
+
+	days := &sortedDays[T]{iterator: iterator, day: day}
+	days.next, days.ok, err = iterator.Next()
+	if err != nil {
+		iterator.Close()
+		return nil, err
+	}
+	return days, nil
+}
+
+// Day returns the day of the records returned by the next call of Next, ok is false when every record is read
+func (d *sortedDays[T]) Day() (day time.Time, ok bool) {
+	if !d.ok {
+		return time.Time{}, false
+	}
+	return d.day(d.next), true
+}
+
+// Next returns the records of the next day in the order of the sorter
+func (d *sortedDays[T]) Next() (records []T, err error) {
+	day, ok := d.Day()
+	for ok {
+		records = append(records, d.next)
+		d.next, d.ok, err = d.iterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var next time.Time
+		next, ok = d.Day()
+		ok = ok && next.Equal(day)
+	}
+	return records, nil
+}
+
+// Close closes the files of the sorted records
+func (d *sortedDays[T]) Close() error {
+	return d.iterator.Close()
+}
+
+var unmarshalCsvToStructForExchangeRates = func(file *multipart.File) (result []*transactions.ExchangeRate, err error) {
+	err = gocsvUnmarshalMultipartFile(file, &result)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// loadExchangeRates reads the exchange rates file with its dates in the location, it returns nil when no file is uploaded
+func loadExchangeRates(file multipart.File, location *time.Location) (*money.ExchangeRates, error) {
+	if file == nil {
+		return nil, nil
+	}
+
+	data, err := unmarshalCsvToStructForExchangeRates(&file)
+	if err != nil {
+		return nil, err
+	}
+
+	exchangeRates := money.NewExchangeRates()
+	for _, d := range data {
+		date, err := time.ParseInLocation(dateFormat, d.Date, location)
+		if err != nil {
+			return nil, libError.NewBadRequestError(fmt.Sprintf("date format in exchange rates data is invalid, use this format %s", dateFormat))
+		}
+
+		err = exchangeRates.Add(date, strings.ToUpper(d.From), strings.ToUpper(d.To), d.Rate)
+		if err != nil {
+			return nil, libError.NewBadRequestError(fmt.Sprintf("exchange rates data is invalid, %s", err))
+		}
+	}
+
+	return exchangeRates, nil
+}
+
+// findReportingCurrency returns the requested reporting currency, when none is requested the only currency
+// used by the records is reported, or money.DefaultCurrency when the records use more than one currency
+func findReportingCurrency(reportingCurrency string, currencies map[string]bool) string {
+	if reportingCurrency != "" {
+		return reportingCurrency
+	}
+
+	if len(currencies) == 1 {
+		for currency := range currencies {
+			return currency
+		}
+	}
+	return money.DefaultCurrency
+}
+
+// exchangeToReportingCurrency exchanges an amount to the reporting currency, amounts in another currency than the
+// reporting currency can only be compared when exchange rates are given
+func exchangeToReportingCurrency(amount money.Money, date time.Time, reportingCurrency string, exchangeRates *money.ExchangeRates) (money.Money, error) {
+	if amount.Currency == reportingCurrency {
+		return amount, nil
+	}
+	if exchangeRates == nil {
+		return money.Money{}, libError.NewBadRequestError(fmt.Sprintf("amounts in %s can not be compared with %s without exchange rates", amount.Currency, reportingCurrency))
+	}
+	return exchangeRates.Convert(amount, reportingCurrency, date)
+}
+
+// exchangeBankStatements fills the reporting amount of the bank statements, the ones without exchange rate for
+// their date are left out and returned as row errors
+func exchangeBankStatements(data []*transactions.BankStatements, reportingCurrency string, exchangeRates *money.ExchangeRates) (result []*transactions.BankStatements, rowErrors []transactions.RowError, err error) {
+	for _, d := range data {
+		d.ReportingAmount, err = exchangeToReportingCurrency(d.RealAmount, d.RealDate, reportingCurrency, exchangeRates)
+		if errors.Is(err, money.ErrNoExchangeRate) {
+			file := d.SourceFile
+			if file == "" {
+				file = transactions.BankStatementsFile
+			}
+			rowErrors = append(rowErrors, transactions.RowError{
+				File:   file,
+				Line:   d.Line,
+				Column: "amount",
+				Value:  d.Amount,
+				Reason: err.Error(),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, d)
+	}
+	return result, rowErrors, nil
 }
 
-// usecase function to do reconciliation
+// exchangeSystemTransactions fills the reporting amount of the system transactions, the ones without exchange rate
+// for their date are left out and returned as row errors
+func exchangeSystemTransactions(data []*transactions.SystemTransactions, reportingCurrency string, exchangeRates *money.ExchangeRates) (result []*transactions.SystemTransactions, rowErrors []transactions.RowError, err error) {
+	for _, d := range data {
+		d.ReportingAmount, err = exchangeToReportingCurrency(d.RealAmount, d.RealTransactionTime, reportingCurrency, exchangeRates)
+		if errors.Is(err, money.ErrNoExchangeRate) {
+			rowErrors = append(rowErrors, transactions.RowError{
+				File:   transactions.SystemTransactionsFile,
+				Line:   d.Line,
+				Column: "amount",
+				Value:  d.Amount,
+				Reason: err.Error(),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, d)
+	}
+	return result, rowErrors, nil
+}
+
+// usecase function to do reconciliation, every run is stored with its result when the usecase has a repository
 func (usecase TransactionUsecase) DoReconciliation(ctx context.Context, param transactions.DoReconciliationRequest) (result transactions.DoReconciliationResponse, err error) {
+	if usecase.ReconciliationRepository == nil {
+		return usecase.reconcile(ctx, param, &reconciledDays{})
+	}
 
-	// bank statements
-	bankStatementsData, err := unmarshalCsvToStructForBankStatements(&param.BankStatements)
+	run := transactions.ReconciliationRun{
+		ID:         newID(),
+		StartedAt:  timeNow().UTC(),
+		Parameters: runParameters(param),
+	}
+	run.Checksums, err = checksumUploads(param)
 	if err != nil {
 		return result, err
 	}
-	if len(bankStatementsData) <= 0 {
-		return result, libError.NewBadRequestError("bank statements data is empty")
+
+	days := &reconciledDays{}
+	result, err = usecase.reconcile(ctx, param, days)
+	run.FinishedAt = timeNow().UTC()
+	location := param.BankStatementsOptions.WithDefaults().Location
+	run.PeriodStart, run.PeriodEnd = days.period(calendarDay(param.StartDate, location), calendarDay(param.EndDate, location))
+
+	run.Status = transactions.RunSucceeded
+	if err != nil {
+		run.Status, run.Error = transactions.RunFailed, err.Error()
+	} else {
+		result.RunID = run.ID
 	}
-	err = validateBankStatementsData(bankStatementsData)
+	run.Result = result
+
+	// the error of a failed run is returned even when the run can't be stored, a canceled run is stored too
+	saveErr := usecase.ReconciliationRepository.SaveRun(context.WithoutCancel(ctx), run)
 	if err != nil {
 		return result, err
 	}
+	if saveErr != nil {
+		return transactions.DoReconciliationResponse{}, fmt.Errorf("reconciliation run can't be stored, %w", saveErr)
+	}
+	return result, nil
+}
 
-	sort.Sort(transactions.SortByRealDateBankStatement(bankStatementsData))
+// reconcile does the reconciliation of the request, the booking days of the reconciled records are added to days
+func (usecase TransactionUsecase) reconcile(ctx context.Context, param transactions.DoReconciliationRequest, days *reconciledDays) (result transactions.DoReconciliationResponse, err error) {
+
+	if param.DateToleranceDays < 0 {
+		return result, libError.NewBadRequestError("date tolerance days can not be negative")
+	}
+	if param.AmountTolerance.IsNegative() || param.AmountTolerancePercent < 0 {
+		return result, libError.NewBadRequestError("amount tolerance can not be negative")
+	}
+	if !param.StartDate.IsZero() && !param.EndDate.IsZero() && param.StartDate.After(param.EndDate) {
+		return result, libError.NewBadRequestError("start date can not be after end date")
+	}
+
+	// records are matched and filtered on the days the bank books them
+	location := param.BankStatementsOptions.WithDefaults().Location
+	startDate, endDate := calendarDay(param.StartDate, location), calendarDay(param.EndDate, location)
+	// records outside the range are only kept as counterparts of the records inside it, they are never reported
+	// as missing
+	candidatesStartDate, candidatesEndDate := widenDateRange(startDate, endDate, param.DateToleranceDays)
+	currencies := map[string]bool{}
+
+	// valid records are sorted by their day on disk, so files bigger than the memory can be reconciled
+	sortBufferSize := usecase.SortBufferSize
+	if sortBufferSize <= 0 {
+		sortBufferSize = defaultSortBufferSize
+	}
+	bankStatementsSorter := extsort.New(func(a, b *transactions.BankStatements) bool {
+		return a.RealDate.Before(b.RealDate)
+	}, sortBufferSize, usecase.TempDir)
+	defer bankStatementsSorter.Close()
+	systemTransactionsSorter := extsort.New(func(a, b *transactions.SystemTransactions) bool {
+		return a.RealTransactionTime.Before(b.RealTransactionTime)
+	}, sortBufferSize, usecase.TempDir)
+	defer systemTransactionsSorter.Close()
+
+	// bank statements of every uploaded file, each row is tagged with its file and bank
+	var bankStatementsRowErrors []transactions.RowError
+	bankStatementsRows := 0
+	for _, bankStatementsUpload := range param.BankStatements {
+		rows, err := usecase.streamBankStatements(bankStatementsUpload, param.BankStatementsOptions, func(valid []*transactions.BankStatements, rowErrors []transactions.RowError) error {
+			// the reconciliation stops when it is canceled
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			param.Progress.AddBankStatementsRead(len(valid) + len(rowErrors))
+			bankStatementsRowErrors = append(bankStatementsRowErrors, rowErrors...)
+			for _, d := range filterBankStatementsByDate(valid, candidatesStartDate, candidatesEndDate) {
+				currencies[d.Currency] = true
+				err := bankStatementsSorter.Add(d)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+		bankStatementsRows += rows
+	}
+	if bankStatementsRows <= 0 {
+		return result, libError.NewBadRequestError("bank statements data is empty")
+	}
 
 	// system transaction
-	systemTransactionsData, err := unmarshalCsvToStructForSystemTransactions(&param.SystemTransactions)
+	var systemTransactionsRowErrors []transactions.RowError
+	systemTransactionsRows, err := usecase.streamSystemTransactions(param.SystemTransactions, param.SystemTransactionsOptions, func(valid []*transactions.SystemTransactions, rowErrors []transactions.RowError) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		param.Progress.AddSystemTransactionsRead(len(valid) + len(rowErrors))
+		systemTransactionsRowErrors = append(systemTransactionsRowErrors, rowErrors...)
+		toBookingDay(valid, location)
+		for _, d := range filterSystemTransactionsByDate(valid, candidatesStartDate, candidatesEndDate) {
+			currencies[d.Currency] = true
+			err := systemTransactionsSorter.Add(d)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return result, err
 	}
-	if len(systemTransactionsData) <= 0 {
+	if systemTransactionsRows <= 0 {
 		return result, libError.NewBadRequestError("system transactions data is empty")
 	}
-	err = validateSystemTransactionsData(systemTransactionsData)
+
+	// both files are validated before failing so every invalid row is reported at once
+	rowErrors := append(bankStatementsRowErrors, systemTransactionsRowErrors...)
+	if len(rowErrors) > 0 && param.ValidationMode != transactions.ValidationLenient {
+		return result, libError.NewValidationError(fmt.Sprintf("uploaded data has %d invalid values", len(rowErrors)), rowErrors)
+	}
+	result.RejectedRows = rowErrors
+
+	// exchange every amount to the reporting currency before they are compared
+	exchangeRates, err := loadExchangeRates(param.ExchangeRates, location)
 	if err != nil {
 		return result, err
 	}
+	reportingCurrency := findReportingCurrency(strings.ToUpper(param.ReportingCurrency), currencies)
 
-	sort.Sort(transactions.SortByRealDateSystemTransaction(systemTransactionsData))
+	bankStatementsDays, err := readSortedDays(bankStatementsSorter, func(d *transactions.BankStatements) time.Time {
+		// the location of the time is not kept on disk
+		d.RealDate = d.RealDate.In(location)
+		return d.RealDate
+	})
+	if err != nil {
+		return result, err
+	}
+	defer bankStatementsDays.Close()
+	systemTransactionsDays, err := readSortedDays(systemTransactionsSorter, func(d *transactions.SystemTransactions) time.Time {
+		d.RealTransactionTime = d.RealTransactionTime.In(location)
+		return d.RealTransactionTime
+	})
+	if err != nil {
+		return result, err
+	}
+	defer systemTransactionsDays.Close()
+	result.ReportingCurrency = reportingCurrency
+	result.TimeZone = location.String()
+	result.TotalDiscrepancies = money.New(0, reportingCurrency)
 
-	var totalMatchedBankStatements float64 = 0
-	var totalMatchedSystemTransactions float64 = 0
+	// subtotals of the amounts in their own currency
+	currencySubtotals := make(map[string]transactions.CurrencySubtotal)
+	addToSubtotal := func(currency string, add func(subtotal *transactions.CurrencySubtotal)) {
+		subtotal, ok := currencySubtotals[currency]
+		if !ok {
+			subtotal = transactions.CurrencySubtotal{
+				MatchedAmount:                   money.New(0, currency),
+				MissingBankStatementsAmount:     money.New(0, currency),
+				MissingSystemTransactionsAmount: money.New(0, currency),
+			}
+		}
+		add(&subtotal)
+		currencySubtotals[currency] = subtotal
+	}
 
 	// map for grouping missing bank statements data to each bank group
 	missingBankStatements := make(map[string][]transactions.BankStatements)
 
-	matchedBankStatements := map[string]bool{}
-	unmatchedBankStatements := 0
-	unmatchedSystemTransactions := 0
-
-	// compare bank statements to system transaction
-	for _, bankStatement := range bankStatementsData {
-		systemTransactionData := findSystemTransactionData(systemTransactionsData, bankStatement.RealDate, bankStatement.Type)
-		if systemTransactionData == nil {
-			unmatchedBankStatements += 1
-			if _, ok := missingBankStatements[bankStatement.BankSource]; !ok {
-				missingBankStatements[bankStatement.BankSource] = []transactions.BankStatements{
-					*bankStatement,
-				}
-			} else {
-				missingBankStatements[bankStatement.BankSource] = append(missingBankStatements[bankStatement.BankSource], 
-					*bankStatement,
-				)
+	// bank statements that can no longer be matched have no counterpart in system transactions
+	addMissingBankStatements := func(bankStatements []*transactions.BankStatements) {
+		for _, bankStatement := range bankStatements {
+			if !inDateRange(bankStatement.RealDate, startDate, endDate) {
+				continue
 			}
+			result.UnmatchedTransaction += 1
+			missingBankStatements[bankStatement.BankSource] = append(missingBankStatements[bankStatement.BankSource], *bankStatement)
+			addToSubtotal(bankStatement.Currency, func(subtotal *transactions.CurrencySubtotal) {
+				subtotal.MissingBankStatementsAmount = subtotal.MissingBankStatementsAmount.Add(bankStatement.AbsoluteAmount())
+			})
+		}
+	}
 
-		} else {
-			totalMatchedBankStatements += bankStatement.RealAmount
-			result.MatchedTransaction += 1
+	// records that can not be exchanged to the reporting currency
+	var exchangeRowErrors []transactions.RowError
 
-			if _, ok := matchedBankStatements[bankStatement.ID]; !ok {
-				matchedBankStatements[bankStatement.ID] = true
+	// pair every system transaction with one bank statement of the same type within the tolerance
+	bankStatementMatcher := newMatcher(nil, tolerance{
+		days:          param.DateToleranceDays,
+		amount:        param.AmountTolerance.WithCurrency(reportingCurrency),
+		amountPercent: param.AmountTolerancePercent,
+	})
+
+	// the bank statements of a day are loaded when the system transactions a tolerance away from it are matched
+	loadBankStatements := func(until time.Time) error {
+		for {
+			day, ok := bankStatementsDays.Day()
+			if !ok || (!until.IsZero() && day.After(until)) {
+				return nil
+			}
+			bankStatements, err := bankStatementsDays.Next()
+			if err != nil {
+				return err
 			}
+			if inDateRange(day, startDate, endDate) {
+				days.add(day)
+			}
+			bankStatements, rowErrors, err := exchangeBankStatements(bankStatements, reportingCurrency, exchangeRates)
+			if err != nil {
+				return err
+			}
+			exchangeRowErrors = append(exchangeRowErrors, rowErrors...)
+			sort.Stable(transactions.SortByRealDateBankStatement(bankStatements))
+			bankStatementMatcher.add(bankStatements)
 		}
 	}
 
-	// compare system transactions to bank statements
-	for _, systemTransaction := range systemTransactionsData {
-		result.TransactionsProceed += 1
+	// system transactions are matched one day at a time
+	for {
+		day, ok := systemTransactionsDays.Day()
+		if !ok {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return transactions.DoReconciliationResponse{}, err
+		}
+		systemTransactionsData, err := systemTransactionsDays.Next()
+		if err != nil {
+			return transactions.DoReconciliationResponse{}, err
+		}
+		inRange := inDateRange(day, startDate, endDate)
+		if inRange {
+			days.add(day)
+		}
 
-		bankStatementData := findBankStatementData(bankStatementsData, systemTransaction.RealTransactionTime, systemTransaction.Type)
-		if bankStatementData == nil {
-			unmatchedSystemTransactions += 1
-			result.MissingSystemTransactions = append(result.MissingSystemTransactions, *systemTransaction)
-		} else {
-			if _, ok := matchedBankStatements[bankStatementData.ID]; ok {
+		err = loadBankStatements(day.AddDate(0, 0, param.DateToleranceDays))
+		if err != nil {
+			return transactions.DoReconciliationResponse{}, err
+		}
+		addMissingBankStatements(bankStatementMatcher.evictBefore(day.AddDate(0, 0, -param.DateToleranceDays)))
+
+		systemTransactionsData, rowErrors, err := exchangeSystemTransactions(systemTransactionsData, reportingCurrency, exchangeRates)
+		if err != nil {
+			return transactions.DoReconciliationResponse{}, err
+		}
+		exchangeRowErrors = append(exchangeRowErrors, rowErrors...)
+		sort.Stable(transactions.SortByRealDateSystemTransaction(systemTransactionsData))
+
+		matchedBankStatements := bankStatementMatcher.matchDay(systemTransactionsData)
+		for index, systemTransaction := range systemTransactionsData {
+			bankStatement := matchedBankStatements[index]
+			// a transaction outside the range is only reported when it is matched with a bank statement inside it
+			if !inRange && (bankStatement == nil || !inDateRange(bankStatement.RealDate, startDate, endDate)) {
+				continue
+			}
+			result.TransactionsProceed += 1
+			param.Progress.AddTransactionsProceed(1)
+
+			if bankStatement == nil {
+				result.UnmatchedTransaction += 1
+				result.MissingSystemTransactions = append(result.MissingSystemTransactions, *systemTransaction)
+				addToSubtotal(systemTransaction.Currency, func(subtotal *transactions.CurrencySubtotal) {
+					subtotal.MissingSystemTransactionsAmount = subtotal.MissingSystemTransactionsAmount.Add(systemTransaction.AbsoluteAmount())
+				})
 				continue
 			}
-			totalMatchedSystemTransactions += systemTransaction.RealAmount
+
+			matchedTransaction := transactions.MatchedTransaction{
+				TransactionID:    systemTransaction.TransactionID,
+				UniqueIdentifier: bankStatement.ID,
+				BankSource:       bankStatement.BankSource,
+				Type:             systemTransaction.Type,
+				Amount:           systemTransaction.AbsoluteAmount(),
+				BankAmount:       bankStatement.AbsoluteAmount(),
+				Difference:       bankStatement.ReportingAmount.Abs().Sub(systemTransaction.ReportingAmount.Abs()),
+				TransactionTime:  systemTransaction.TransactionTime,
+				Date:             bankStatement.Date,
+			}
+
 			result.MatchedTransaction += 1
+			addToSubtotal(systemTransaction.Currency, func(subtotal *transactions.CurrencySubtotal) {
+				subtotal.MatchedAmount = subtotal.MatchedAmount.Add(systemTransaction.AbsoluteAmount())
+			})
+			if matchedTransaction.Difference.IsZero() {
+				result.MatchedTransactions = append(result.MatchedTransactions, matchedTransaction)
+				continue
+			}
+
+			// matched within the amount tolerance, the difference counts as discrepancy
+			result.MatchedWithDifference = append(result.MatchedWithDifference, matchedTransaction)
+			result.TotalDiscrepancies = result.TotalDiscrepancies.Add(matchedTransaction.Difference.Abs())
 		}
 	}
 
-	// combine unmatched from bank statements and system transactions
-	result.UnmatchedTransaction = unmatchedBankStatements+unmatchedSystemTransactions
+	// bank statements left over have no counterpart in system transactions
+	err = loadBankStatements(time.Time{})
+	if err != nil {
+		return transactions.DoReconciliationResponse{}, err
+	}
+	addMissingBankStatements(bankStatementMatcher.unmatched())
 
-	result.TotalDiscrepancies = totalMatchedBankStatements - totalMatchedSystemTransactions
-	if result.TotalDiscrepancies < 0 {
-		result.TotalDiscrepancies *= -1
+	// records without exchange rate are found while they are matched, they are reported like the invalid values
+	if len(exchangeRowErrors) > 0 && param.ValidationMode != transactions.ValidationLenient {
+		return transactions.DoReconciliationResponse{}, libError.NewValidationError(fmt.Sprintf("uploaded data has %d amounts without exchange rate", len(exchangeRowErrors)), exchangeRowErrors)
 	}
+	result.RejectedRows = append(result.RejectedRows, exchangeRowErrors...)
 
 	result.MissingBankStatements = missingBankStatements
+	result.CurrencySubtotals = currencySubtotals
 
 	return
 }
@@ -1,22 +1,47 @@
 package usecase
 
 import (
+	"amartha-test/dates"
+	"amartha-test/entities/parsers"
+	repositoryMock "amartha-test/entities/repositories/mock"
 	"amartha-test/entities/transactions"
+	"amartha-test/money"
+	bankStatementsParsers "amartha-test/parsers"
+	"amartha-test/spreadsheets"
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/csv"
 	"errors"
+	"fmt"
+	"io"
+	"math/rand"
 	"mime/multipart"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/gocarina/gocsv"
+	"github.com/golang/mock/gomock"
+	"github.com/xuri/excelize/v2"
 )
 
 var (
 	errMock = errors.New("err")
 )
 
+// nopMultipartFile is an uploaded file for tests that mock the csv unmarshalling
+type nopMultipartFile struct {
+	*bytes.Reader
+}
+
+func (nopMultipartFile) Close() error {
+	return nil
+}
+
 func TestNewTransactionUsecase(t *testing.T) {
 	type args struct {
 		usecase TransactionUsecase
@@ -43,14 +68,15 @@ func TestNewTransactionUsecase(t *testing.T) {
 	}
 }
 
-func Test_convertCurrencyToFloat(t *testing.T) {
+func Test_convertCurrencyToMoney(t *testing.T) {
 	type args struct {
 		currency string
+		options  transactions.FileOptions
 	}
 	tests := []struct {
 		name    string
 		args    args
-		want    float64
+		want    money.Money
 		wantErr bool
 	}{
 		{
@@ -58,7 +84,26 @@ func Test_convertCurrencyToFloat(t *testing.T) {
 			args: args{
 				currency: "Rp100,000",
 			},
-			want:    100000,
+			want:    money.New(10000000, money.DefaultCurrency),
+			wantErr: false,
+		},
+		{
+			name: "Succesful with minor unit",
+			args: args{
+				currency: "-Rp1,500,000.05",
+			},
+			want:    money.New(-150000005, money.DefaultCurrency),
+			wantErr: false,
+		},
+		{
+			name: "Succesful with number format",
+			args: args{
+				currency: "$1.500,05",
+				options: transactions.FileOptions{
+					NumberFormat: money.FormatIndonesian,
+				},
+			},
+			want:    money.New(150005, "USD"),
 			wantErr: false,
 		},
 		{
@@ -66,19 +111,19 @@ func Test_convertCurrencyToFloat(t *testing.T) {
 			args: args{
 				currency: "abc",
 			},
-			want:    0,
+			want:    money.Money{},
 			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := convertCurrencyToFloat(tt.args.currency)
+			got, err := convertCurrencyToMoney(tt.args.currency, tt.args.options)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("convertCurrencyToFloat() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("convertCurrencyToMoney() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if got != tt.want {
-				t.Errorf("convertCurrencyToFloat() = %v, want %v", got, tt.want)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convertCurrencyToMoney() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -119,6 +164,8 @@ BCA_12345,"Rp1,500,000",01/01/2024`
 	defer openedFile.Close()
 
 	multipartFile := multipart.File(openedFile)
+	// the line of a record counts the blank lines before it
+	blankLinesFile := multipart.File(memoryFile{bytes.NewReader([]byte("unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024\n\n\nBCA_2,abc,02/01/2024\n"))})
 
 	type args struct {
 		file *multipart.File
@@ -141,6 +188,30 @@ BCA_12345,"Rp1,500,000",01/01/2024`
 					ID:     "BCA_12345",
 					Amount: "Rp1,500,000",
 					Date:   "01/01/2024",
+					Line:   2,
+				},
+			},
+			wantErr: false,
+			mock:    func() {},
+			unmock:  func() {},
+		},
+		{
+			name: "Succesful with blank lines",
+			args: args{
+				file: &blankLinesFile,
+			},
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "BCA_12345",
+					Amount: "Rp1,500,000",
+					Date:   "01/01/2024",
+					Line:   2,
+				},
+				{
+					ID:     "BCA_2",
+					Amount: "abc",
+					Date:   "02/01/2024",
+					Line:   5,
 				},
 			},
 			wantErr: false,
@@ -155,19 +226,27 @@ BCA_12345,"Rp1,500,000",01/01/2024`
 			wantResult: nil,
 			wantErr:    true,
 			mock: func() {
-				gocsvUnmarshalMultipartFile = func(in *multipart.File, out interface{}) error {
-					return errMock
+				gocsvNewMultipartFileUnmarshaller = func(in *multipart.File, out interface{}) (*gocsv.Unmarshaller, *csv.Reader, error) {
+					return nil, nil, errMock
 				}
 			},
 			unmock: func() {
-				gocsvUnmarshalMultipartFile = gocsv.UnmarshalMultipartFile
+				gocsvNewMultipartFileUnmarshaller = func(in *multipart.File, out interface{}) (*gocsv.Unmarshaller, *csv.Reader, error) {
+					reader := csv.NewReader(*in)
+					unmarshaller, err := gocsv.NewUnmarshaller(reader, out)
+					return unmarshaller, reader, err
+				}
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
-			gotResult, err := unmarshalCsvToStructForBankStatements(tt.args.file)
+			var gotResult []*transactions.BankStatements
+			err := unmarshalCsvToStructForBankStatements(tt.args.file, func(d *transactions.BankStatements) error {
+				gotResult = append(gotResult, d)
+				return nil
+			})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("unmarshalCsvToStructForBankStatements() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -215,6 +294,8 @@ func Test_unmarshalCsvToStructForSystemTransactions(t *testing.T) {
 	defer openedFile.Close()
 
 	multipartFile := multipart.File(openedFile)
+	// the line of a record counts the line breaks of the quoted values before it
+	lineBreakFile := multipart.File(memoryFile{bytes.NewReader([]byte("trxID,amount,type,transactionTime\n1,\"Rp8,500,000\n\",2,01/01/2024 8:45:00\n\n2,Rp100,2,01/01/2024 8:46:00\n"))})
 
 	type args struct {
 		file *multipart.File
@@ -236,8 +317,34 @@ func Test_unmarshalCsvToStructForSystemTransactions(t *testing.T) {
 				{
 					TransactionID:   "1",
 					Amount:          "Rp8,500,000",
-					Type:            2,
+					RawType:         "2",
+					TransactionTime: "01/01/2024 8:45:00",
+					Line:            2,
+				},
+			},
+			wantErr: false,
+			mock:    func() {},
+			unmock:  func() {},
+		},
+		{
+			name: "Succesful with a line break in a value",
+			args: args{
+				file: &lineBreakFile,
+			},
+			wantResult: []*transactions.SystemTransactions{
+				{
+					TransactionID:   "1",
+					Amount:          "Rp8,500,000\n",
+					RawType:         "2",
 					TransactionTime: "01/01/2024 8:45:00",
+					Line:            2,
+				},
+				{
+					TransactionID:   "2",
+					Amount:          "Rp100",
+					RawType:         "2",
+					TransactionTime: "01/01/2024 8:46:00",
+					Line:            5,
 				},
 			},
 			wantErr: false,
@@ -252,19 +359,27 @@ func Test_unmarshalCsvToStructForSystemTransactions(t *testing.T) {
 			wantResult: nil,
 			wantErr:    true,
 			mock: func() {
-				gocsvUnmarshalMultipartFile = func(in *multipart.File, out interface{}) error {
-					return errMock
+				gocsvNewMultipartFileUnmarshaller = func(in *multipart.File, out interface{}) (*gocsv.Unmarshaller, *csv.Reader, error) {
+					return nil, nil, errMock
 				}
 			},
 			unmock: func() {
-				gocsvUnmarshalMultipartFile = gocsv.UnmarshalMultipartFile
+				gocsvNewMultipartFileUnmarshaller = func(in *multipart.File, out interface{}) (*gocsv.Unmarshaller, *csv.Reader, error) {
+					reader := csv.NewReader(*in)
+					unmarshaller, err := gocsv.NewUnmarshaller(reader, out)
+					return unmarshaller, reader, err
+				}
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
-			gotResult, err := unmarshalCsvToStructForSystemTransactions(tt.args.file)
+			var gotResult []*transactions.SystemTransactions
+			err := unmarshalCsvToStructForSystemTransactions(tt.args.file, func(d *transactions.SystemTransactions) error {
+				gotResult = append(gotResult, d)
+				return nil
+			})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("unmarshalCsvToStructForSystemTransactions() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -277,508 +392,2325 @@ func Test_unmarshalCsvToStructForSystemTransactions(t *testing.T) {
 	}
 }
 
-func Test_validateBankStatementsData(t *testing.T) {
+func TestTransactionUsecase_readBankStatements(t *testing.T) {
+	usecase := TransactionUsecase{
+		BankStatementsParsers: bankStatementsParsers.BankStatementsParsers(),
+	}
+	mandiriStatement := `Account No,Ccy,Post Date,Value Date,Transaction Code,Description,Reference No.,Debit,Credit,Balance
+1234567890,IDR,13/01/2024 08:20,13/01/2024,ATR,TRANSFER AMARTHA,FT24013ABC,",00","2.000.000,00","12.000.000,00"
+Opening Balance,"10.000.000,00"`
+
 	type args struct {
-		data []*transactions.BankStatements
+		content string
+		options transactions.FileOptions
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-		mock    func()
+		name             string
+		args             args
+		wantResult       []*transactions.BankStatements
+		wantNumberFormat money.NumberFormat
+		wantErr          bool
 	}{
 		{
-			name: "Succesful DEBIT",
+			name: "Succesful csv",
 			args: args{
-				data: []*transactions.BankStatements{
-					{
-						ID:     "BCA_123",
-						Amount: "-Rp100,000",
-						Date:   "13/01/2024",
-					},
+				content: "unique_identifier,amount,date\nBCA_12345,\"Rp1.500.000\",01/01/2024",
+				options: transactions.FileOptions{NumberFormat: money.FormatIndonesian},
+			},
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "BCA_12345",
+					Amount: "Rp1.500.000",
+					Date:   "01/01/2024",
+					Line:   2,
 				},
 			},
-			wantErr: false,
-			mock:    func() {},
+			wantNumberFormat: money.FormatIndonesian,
+			wantErr:          false,
 		},
 		{
-			name: "Succesful CREDIT",
+			name: "Succesful headerless csv",
 			args: args{
-				data: []*transactions.BankStatements{
-					{
-						ID:     "BCA_123",
-						Amount: "Rp100,000",
-						Date:   "13/01/2024",
+				content: "BCA_12345\t01/01/2024\t1500000\nBCA_12346\t02/01/2024\t2500000",
+				options: transactions.FileOptions{
+					Layout: transactions.ColumnLayout{
+						Columns:    map[string]string{"unique_identifier": "1", "date": "2", "amount": "3"},
+						Headerless: true,
+						Delimiter:  "\t",
 					},
 				},
 			},
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "BCA_12345",
+					Amount: "1500000",
+					Date:   "01/01/2024",
+					Line:   1,
+				},
+				{
+					ID:     "BCA_12346",
+					Amount: "2500000",
+					Date:   "02/01/2024",
+					Line:   2,
+				},
+			},
 			wantErr: false,
-			mock:    func() {},
 		},
 		{
-			name: "convertCurrencyToFloat return error",
+			name: "Succesful bank format",
 			args: args{
-				data: []*transactions.BankStatements{
-					{
-						ID:     "BCA_123",
-						Amount: "abc",
-						Date:   "13/01/2024",
-					},
+				content: mandiriStatement,
+				options: transactions.FileOptions{Format: "mandiri", NumberFormat: money.FormatEnglish},
+			},
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "MANDIRI_FT24013ABC",
+					Amount: "2.000.000,00 IDR",
+					Date:   "13/01/2024",
+					Bank:   "MANDIRI",
+					Line:   2,
 				},
 			},
-			wantErr: true,
-			mock:    func() {},
+			wantNumberFormat: money.FormatIndonesian,
+			wantErr:          false,
 		},
 		{
-			name: "Time Parsing return error",
+			name: "Succesful detected bank format",
 			args: args{
-				data: []*transactions.BankStatements{
-					{
-						ID:     "BCA_123",
-						Amount: "Rp100,000",
-						Date:   "",
-					},
+				content: mandiriStatement,
+				options: transactions.FileOptions{Format: parsers.FormatAuto},
+			},
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "MANDIRI_FT24013ABC",
+					Amount: "2.000.000,00 IDR",
+					Date:   "13/01/2024",
+					Bank:   "MANDIRI",
+					Line:   2,
 				},
 			},
-			wantErr: true,
-			mock:    func() {},
+			wantNumberFormat: money.FormatIndonesian,
+			wantErr:          false,
 		},
 		{
-			name: "ID doesn't contain bank source",
+			name: "Succesful detected csv",
 			args: args{
-				data: []*transactions.BankStatements{
-					{
-						ID:     "",
-						Amount: "Rp100,000",
-						Date:   "13/01/2024",
-					},
+				content: "unique_identifier,amount,date\nBCA_12345,\"Rp1,500,000\",01/01/2024",
+				options: transactions.FileOptions{Format: parsers.FormatAuto},
+			},
+			wantResult: []*transactions.BankStatements{
+				{
+					ID:     "BCA_12345",
+					Amount: "Rp1,500,000",
+					Date:   "01/01/2024",
+					Line:   2,
 				},
 			},
+			wantErr: false,
+		},
+		{
+			name: "Format is not supported",
+			args: args{
+				content: mandiriStatement,
+				options: transactions.FileOptions{Format: "bni"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "File doesn't have the format",
+			args: args{
+				content: mandiriStatement,
+				options: transactions.FileOptions{Format: "bca"},
+			},
 			wantErr: true,
-			mock:    func() {},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.mock()
-			if err := validateBankStatementsData(tt.args.data); (err != nil) != tt.wantErr {
-				t.Errorf("validateBankStatementsData() error = %v, wantErr %v", err, tt.wantErr)
+			file := multipart.File(nopMultipartFile{bytes.NewReader([]byte(tt.args.content))})
+			var gotResult []*transactions.BankStatements
+			var gotOptions transactions.FileOptions
+			err := usecase.readBankStatements(&file, tt.args.options, func(d *transactions.BankStatements, options transactions.FileOptions) error {
+				gotResult = append(gotResult, d)
+				gotOptions = options
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TransactionUsecase.readBankStatements() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResult, tt.wantResult) {
+				t.Errorf("TransactionUsecase.readBankStatements() = %v, want %v", gotResult, tt.wantResult)
+			}
+			if gotOptions.NumberFormat != tt.wantNumberFormat {
+				t.Errorf("TransactionUsecase.readBankStatements() number format = %v, want %v", gotOptions.NumberFormat, tt.wantNumberFormat)
 			}
 		})
 	}
 }
 
-func Test_validateSystemTransactionsData(t *testing.T) {
+func TestTransactionUsecase_readSystemTransactions(t *testing.T) {
+	workbook := excelize.NewFile()
+	defer workbook.Close()
+	for index, row := range [][]interface{}{
+		{"SYSTEM TRANSACTIONS"},
+		{"trxID", "amount", "type", "transactionTime"},
+		{"1", "Rp8,500,000", "CREDIT", "01/01/2024 8:45:00"},
+		{},
+		// a date cell is written with the date format of the file
+		{"2", "Rp7,000,000", "DEBIT", time.Date(2024, time.Month(1), 1, 8, 46, 0, 0, time.UTC)},
+	} {
+		cell, _ := excelize.CoordinatesToCellName(1, index+1)
+		workbook.SetSheetRow("Sheet1", cell, &row)
+	}
+	content, err := workbook.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("Failed to write workbook: %v", err)
+	}
+
 	type args struct {
-		data []*transactions.SystemTransactions
+		content []byte
+		options transactions.FileOptions
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
+		name       string
+		args       args
+		wantResult []*transactions.SystemTransactions
+		wantErr    bool
+		mock       func()
+		unmock     func()
 	}{
 		{
-			name: "Succesful",
+			name: "Succesful xlsx",
 			args: args{
-				data: []*transactions.SystemTransactions{
-					{
-						TransactionID:   "1",
-						Amount:          "Rp100,000",
-						TransactionTime: "15/02/2024 8:20:00",
-					},
+				content: content.Bytes(),
+				options: transactions.FileOptions{HeaderRow: 2},
+			},
+			wantResult: []*transactions.SystemTransactions{
+				{
+					TransactionID:   "1",
+					Amount:          "Rp8,500,000",
+					RawType:         "CREDIT",
+					TransactionTime: "01/01/2024 8:45:00",
+					Line:            3,
+				},
+				{
+					TransactionID:   "2",
+					Amount:          "Rp7,000,000",
+					RawType:         "DEBIT",
+					TransactionTime: "01/01/2024 08:46:00",
+					Line:            5,
 				},
 			},
 			wantErr: false,
+			mock:    func() {},
+			unmock:  func() {},
 		},
 		{
-			name: "convertCurrencyToFloat return error",
+			name: "Succesful csv",
 			args: args{
-				data: []*transactions.SystemTransactions{
-					{
-						TransactionID:   "1",
-						Amount:          "",
-						TransactionTime: "15/02/2024 8:20:00",
-					},
+				content: []byte("trxID,amount,type,transactionTime\n1,\"Rp8,500,000\",2,01/01/2024 8:45:00"),
+			},
+			wantResult: []*transactions.SystemTransactions{
+				{
+					TransactionID:   "1",
+					Amount:          "Rp8,500,000",
+					RawType:         "2",
+					TransactionTime: "01/01/2024 8:45:00",
+					Line:            2,
 				},
 			},
-			wantErr: true,
+			wantErr: false,
+			mock:    func() {},
+			unmock:  func() {},
 		},
 		{
-			name: "Time parsing return error",
+			name: "Succesful column layout",
 			args: args{
-				data: []*transactions.SystemTransactions{
-					{
-						TransactionID:   "1",
-						Amount:          "Rp100,000",
-						TransactionTime: "",
+				content: []byte("Transaction ID;Nominal;Jenis;Waktu\n\n1;Rp8.500.000;CREDIT;01/01/2024 8:45:00"),
+				options: transactions.FileOptions{
+					Layout: transactions.ColumnLayout{
+						Columns:   map[string]string{"trxID": "Transaction ID", "amount": "Nominal", "type": "Jenis", "transactionTime": "Waktu"},
+						Delimiter: ";",
 					},
 				},
 			},
+			wantResult: []*transactions.SystemTransactions{
+				{
+					TransactionID:   "1",
+					Amount:          "Rp8.500.000",
+					RawType:         "CREDIT",
+					TransactionTime: "01/01/2024 8:45:00",
+					Line:            3,
+				},
+			},
+			wantErr: false,
+			mock:    func() {},
+			unmock:  func() {},
+		},
+		{
+			name: "Column is not found",
+			args: args{
+				content: []byte("trxID,amount,type,transactionTime\n1,\"Rp8,500,000\",2,01/01/2024 8:45:00"),
+				options: transactions.FileOptions{
+					Layout: transactions.ColumnLayout{Columns: map[string]string{"amount": "Nominal"}},
+				},
+			},
 			wantErr: true,
+			mock:    func() {},
+			unmock:  func() {},
+		},
+		{
+			name: "Sheet is not found",
+			args: args{
+				content: content.Bytes(),
+				options: transactions.FileOptions{Sheet: "Mutasi"},
+			},
+			wantErr: true,
+			mock:    func() {},
+			unmock:  func() {},
+		},
+		{
+			name: "spreadsheetToCsv return error",
+			args: args{
+				content: content.Bytes(),
+			},
+			wantErr: true,
+			mock: func() {
+				spreadsheetToCsv = func(_ io.Reader, _ string, _ int, _ func(time.Time) string) ([]byte, []int, error) {
+					return nil, nil, errMock
+				}
+			},
+			unmock: func() {
+				spreadsheetToCsv = spreadsheets.ToCSV
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := validateSystemTransactionsData(tt.args.data); (err != nil) != tt.wantErr {
-				t.Errorf("validateSystemTransactionsData() error = %v, wantErr %v", err, tt.wantErr)
+			tt.mock()
+			defer tt.unmock()
+			file := multipart.File(nopMultipartFile{bytes.NewReader(tt.args.content)})
+			var gotResult []*transactions.SystemTransactions
+			err := TransactionUsecase{}.readSystemTransactions(&file, tt.args.options, func(d *transactions.SystemTransactions) error {
+				gotResult = append(gotResult, d)
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TransactionUsecase.readSystemTransactions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResult, tt.wantResult) {
+				t.Errorf("TransactionUsecase.readSystemTransactions() = %v, want %v", gotResult, tt.wantResult)
 			}
 		})
 	}
 }
 
-func Test_findBankStatementData(t *testing.T) {
+func Test_validateBankStatementsData(t *testing.T) {
 	type args struct {
-		statements []*transactions.BankStatements
-		targetDate time.Time
-		targetType int
+		data []*transactions.BankStatements
 	}
 	tests := []struct {
-		name string
-		args args
-		want *transactions.BankStatements
+		name          string
+		args          args
+		wantValid     int
+		wantRowErrors []transactions.RowError
+		mock          func()
 	}{
 		{
-			name: "Succesful",
+			name: "Succesful DEBIT",
 			args: args{
-				statements: []*transactions.BankStatements{
-					{
-						ID:       "MANDIRI_12346",
-						Amount:   "Rp2,500,000",
-						Date:     "13/01/2024",
-						Type:     transactions.CREDIT,
-						RealDate: time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
-					},
-					{
-						ID:       "MANDIRI_12347",
-						Amount:   "Rp2,500,000",
-						Date:     "15/01/2024",
-						Type:     transactions.CREDIT,
-						RealDate: time.Date(2024, time.Month(1), 15, 0, 0, 0, 0, time.Local),
-					},
+				data: []*transactions.BankStatements{
 					{
-						ID:       "MANDIRI_12348",
-						Amount:   "Rp2,500,000",
-						Date:     "16/01/2024",
-						Type:     transactions.CREDIT,
-						RealDate: time.Date(2024, time.Month(1), 16, 0, 0, 0, 0, time.Local),
+						ID:     "BCA_123",
+						Amount: "-Rp100,000",
+						Date:   "13/01/2024",
+						Line:   2,
 					},
 				},
-				targetDate: time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
-				targetType: transactions.CREDIT,
-			},
-			want: &transactions.BankStatements{
-				ID:       "MANDIRI_12346",
-				Amount:   "Rp2,500,000",
-				Date:     "13/01/2024",
-				Type:     transactions.CREDIT,
-				RealDate: time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
 			},
+			wantValid: 1,
+			mock:      func() {},
 		},
 		{
-			name: "Not Found",
+			name: "Succesful CREDIT",
 			args: args{
-				statements: []*transactions.BankStatements{
+				data: []*transactions.BankStatements{
 					{
-						ID:       "MANDIRI_12346",
-						Amount:   "Rp2,500,000",
-						Date:     "13/01/2024",
-						Type:     transactions.CREDIT,
-						RealDate: time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
+						ID:     "BCA_123",
+						Amount: "Rp100,000",
+						Date:   "13/01/2024",
+						Line:   2,
 					},
 				},
-				targetDate: time.Date(2024, time.Month(1), 19, 0, 0, 0, 0, time.Local),
-				targetType: transactions.CREDIT,
 			},
-			want: nil,
+			wantValid: 1,
+			mock:      func() {},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := findBankStatementData(tt.args.statements, tt.args.targetDate, tt.args.targetType); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("findBankStatementData() = %v, want %v", got, tt.want)
+		{
+			name: "convertCurrencyToFloat return error",
+			args: args{
+				data: []*transactions.BankStatements{
+					{
+						ID:     "BCA_123",
+						Amount: "abc",
+						Date:   "13/01/2024",
+						Line:   2,
+					},
+				},
+			},
+			wantValid: 0,
+			wantRowErrors: []transactions.RowError{
+				{
+					File:   transactions.BankStatementsFile,
+					Line:   2,
+					Column: "amount",
+					Value:  "abc",
+					Reason: `amount format is invalid, invalid amount "abc": unexpected character 'a'`,
+				},
+			},
+			mock: func() {},
+		},
+		{
+			name: "Time Parsing return error",
+			args: args{
+				data: []*transactions.BankStatements{
+					{
+						ID:     "BCA_123",
+						Amount: "Rp100,000",
+						Date:   "",
+						Line:   2,
+					},
+				},
+			},
+			wantValid: 0,
+			wantRowErrors: []transactions.RowError{
+				{
+					File:   transactions.BankStatementsFile,
+					Line:   2,
+					Column: "date",
+					Value:  "",
+					Reason: "date format is invalid, use this format 02/01/2006",
+				},
+			},
+			mock: func() {},
+		},
+		{
+			name: "ID doesn't contain bank source",
+			args: args{
+				data: []*transactions.BankStatements{
+					{
+						ID:     "",
+						Amount: "Rp100,000",
+						Date:   "13/01/2024",
+						Line:   2,
+					},
+				},
+			},
+			wantValid: 0,
+			wantRowErrors: []transactions.RowError{
+				{
+					File:   transactions.BankStatementsFile,
+					Line:   2,
+					Column: "unique_identifier",
+					Value:  "",
+					Reason: "unique_identifier must contain the bank source, for example BCA_123",
+				},
+			},
+			mock: func() {},
+		},
+		{
+			name: "Every invalid value is reported",
+			args: args{
+				data: []*transactions.BankStatements{
+					{
+						ID:     "BCA_123",
+						Amount: "Rp100,000",
+						Date:   "13/01/2024",
+						Line:   2,
+					},
+					{
+						ID:     "BCA123",
+						Amount: "Rp100,000",
+						Date:   "2024-01-13",
+						Line:   3,
+					},
+				},
+			},
+			wantValid: 1,
+			wantRowErrors: []transactions.RowError{
+				{
+					File:   transactions.BankStatementsFile,
+					Line:   3,
+					Column: "date",
+					Value:  "2024-01-13",
+					Reason: "date format is invalid, use this format 02/01/2006",
+				},
+				{
+					File:   transactions.BankStatementsFile,
+					Line:   3,
+					Column: "unique_identifier",
+					Value:  "BCA123",
+					Reason: "unique_identifier must contain the bank source, for example BCA_123",
+				},
+			},
+			mock: func() {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			gotResult, gotRowErrors := validateBankStatementsData(tt.args.data, transactions.FileOptions{})
+			if len(gotResult) != tt.wantValid {
+				t.Errorf("validateBankStatementsData() valid = %v, want %v", len(gotResult), tt.wantValid)
+			}
+			if !reflect.DeepEqual(gotRowErrors, tt.wantRowErrors) {
+				t.Errorf("validateBankStatementsData() rowErrors = %v, want %v", gotRowErrors, tt.wantRowErrors)
 			}
 		})
 	}
 }
 
-func Test_findSystemTransactionData(t *testing.T) {
+func Test_validateSystemTransactionsData(t *testing.T) {
 	type args struct {
-		transaction []*transactions.SystemTransactions
-		targetDate  time.Time
-		targetType  int
+		data []*transactions.SystemTransactions
 	}
 	tests := []struct {
-		name string
-		args args
-		want *transactions.SystemTransactions
+		name          string
+		args          args
+		wantValid     int
+		wantRowErrors []transactions.RowError
 	}{
 		{
 			name: "Succesful",
 			args: args{
-				transaction: []*transactions.SystemTransactions{
+				data: []*transactions.SystemTransactions{
 					{
-						TransactionID:       "1",
-						Amount:              "Rp2,000,000",
-						RealAmount:          2000000,
-						Type:                transactions.DEBIT,
-						TransactionTime:     "15/02/2024 8:20:00",
-						RealTransactionTime: time.Date(2024, time.Month(2), 15, 8, 20, 0, 0, time.Local),
+						TransactionID:   "1",
+						Amount:          "Rp100,000",
+						TransactionTime: "15/02/2024 8:20:00",
+						Line:            2,
 					},
+				},
+			},
+			wantValid: 1,
+		},
+		{
+			name: "convertCurrencyToFloat return error",
+			args: args{
+				data: []*transactions.SystemTransactions{
 					{
-						TransactionID:       "2",
-						Amount:              "Rp2,000,000",
-						RealAmount:          2000000,
-						Type:                transactions.DEBIT,
-						TransactionTime:     "16/02/2024 8:20:00",
-						RealTransactionTime: time.Date(2024, time.Month(2), 16, 8, 20, 0, 0, time.Local),
+						TransactionID:   "1",
+						Amount:          "",
+						TransactionTime: "15/02/2024 8:20:00",
+						Line:            2,
+					},
+				},
+			},
+			wantValid: 0,
+			wantRowErrors: []transactions.RowError{
+				{
+					File:   transactions.SystemTransactionsFile,
+					Line:   2,
+					Column: "amount",
+					Value:  "",
+					Reason: `amount format is invalid, invalid amount "": amount is empty`,
+				},
+			},
+		},
+		{
+			name: "Time parsing return error",
+			args: args{
+				data: []*transactions.SystemTransactions{
+					{
+						TransactionID:   "1",
+						Amount:          "Rp100,000",
+						TransactionTime: "",
+						Line:            2,
+					},
+				},
+			},
+			wantValid: 0,
+			wantRowErrors: []transactions.RowError{
+				{
+					File:   transactions.SystemTransactionsFile,
+					Line:   2,
+					Column: "transactionTime",
+					Value:  "",
+					Reason: "date format is invalid, use this format 02/01/2006 15:04:05",
+				},
+			},
+		},
+		{
+			name: "Type is unknown",
+			args: args{
+				data: []*transactions.SystemTransactions{
+					{
+						TransactionID:   "1",
+						Amount:          "Rp100,000",
+						RawType:         "3",
+						TransactionTime: "15/02/2024 8:20:00",
+						Line:            2,
+					},
+				},
+			},
+			wantValid: 0,
+			wantRowErrors: []transactions.RowError{
+				{
+					File:   transactions.SystemTransactionsFile,
+					Line:   2,
+					Column: "type",
+					Value:  "3",
+					Reason: "type must be 1 or DEBIT, 2 or CREDIT, or empty with a signed amount",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, gotRowErrors := validateSystemTransactionsData(tt.args.data, transactions.FileOptions{})
+			if len(gotResult) != tt.wantValid {
+				t.Errorf("validateSystemTransactionsData() valid = %v, want %v", len(gotResult), tt.wantValid)
+			}
+			if !reflect.DeepEqual(gotRowErrors, tt.wantRowErrors) {
+				t.Errorf("validateSystemTransactionsData() rowErrors = %v, want %v", gotRowErrors, tt.wantRowErrors)
+			}
+		})
+	}
+}
+
+func Test_extractBankSource(t *testing.T) {
+	type args struct {
+		bankStatement *transactions.BankStatements
+		pattern       *regexp.Regexp
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Succesful default pattern",
+			args: args{
+				bankStatement: &transactions.BankStatements{ID: "BCA_123"},
+				pattern:       transactions.DefaultBankSourcePattern,
+			},
+			want:    "BCA",
+			wantErr: false,
+		},
+		{
+			name: "Succesful custom pattern",
+			args: args{
+				bankStatement: &transactions.BankStatements{ID: "BANK_MANDIRI_123"},
+				pattern:       regexp.MustCompile(`^BANK_(?P<bank>[A-Z]+)_\d+$`),
+			},
+			want:    "MANDIRI",
+			wantErr: false,
+		},
+		{
+			name: "Succesful bank column",
+			args: args{
+				bankStatement: &transactions.BankStatements{ID: "123", Bank: "BRI"},
+				pattern:       transactions.DefaultBankSourcePattern,
+			},
+			want:    "BRI",
+			wantErr: false,
+		},
+		{
+			name: "Default pattern doesn't match",
+			args: args{
+				bankStatement: &transactions.BankStatements{ID: "BANK_MANDIRI_123"},
+				pattern:       transactions.DefaultBankSourcePattern,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Custom pattern doesn't match",
+			args: args{
+				bankStatement: &transactions.BankStatements{ID: "123"},
+				pattern:       regexp.MustCompile(`^BANK_(?P<bank>[A-Z]+)_\d+$`),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractBankSource(tt.args.bankStatement, tt.args.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("extractBankSource() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("extractBankSource() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseTransactionType(t *testing.T) {
+	type args struct {
+		rawType string
+		amount  money.Money
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "Succesful number",
+			args: args{
+				rawType: "1",
+				amount:  money.MustParse("100000", money.DefaultCurrency),
+			},
+			want:    transactions.DEBIT,
+			wantErr: false,
+		},
+		{
+			name: "Succesful name",
+			args: args{
+				rawType: " credit ",
+				amount:  money.MustParse("100000", money.DefaultCurrency),
+			},
+			want:    transactions.CREDIT,
+			wantErr: false,
+		},
+		{
+			name: "Succesful signed DEBIT",
+			args: args{
+				rawType: "DEBIT",
+				amount:  money.MustParse("-100000", money.DefaultCurrency),
+			},
+			want:    transactions.DEBIT,
+			wantErr: false,
+		},
+		{
+			name: "Succesful from negative amount",
+			args: args{
+				rawType: "",
+				amount:  money.MustParse("-100000", money.DefaultCurrency),
+			},
+			want:    transactions.DEBIT,
+			wantErr: false,
+		},
+		{
+			name: "Succesful from positive amount",
+			args: args{
+				rawType: "",
+				amount:  money.MustParse("100000", money.DefaultCurrency),
+			},
+			want:    transactions.CREDIT,
+			wantErr: false,
+		},
+		{
+			name: "Unknown type",
+			args: args{
+				rawType: "3",
+				amount:  money.MustParse("100000", money.DefaultCurrency),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative CREDIT",
+			args: args{
+				rawType: "2",
+				amount:  money.MustParse("-100000", money.DefaultCurrency),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTransactionType(tt.args.rawType, tt.args.amount)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseTransactionType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseTransactionType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_filterBankStatementsByDate(t *testing.T) {
+	data := []*transactions.BankStatements{
+		{
+			ID:       "BCA_1",
+			RealDate: time.Date(2023, time.Month(12), 31, 0, 0, 0, 0, time.Local),
+		},
+		{
+			ID:       "BCA_2",
+			RealDate: time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, time.Local),
+		},
+		{
+			ID:       "BCA_3",
+			RealDate: time.Date(2024, time.Month(1), 31, 0, 0, 0, 0, time.Local),
+		},
+		{
+			ID:       "BCA_4",
+			RealDate: time.Date(2024, time.Month(2), 1, 0, 0, 0, 0, time.Local),
+		},
+	}
+
+	type args struct {
+		startDate time.Time
+		endDate   time.Time
+	}
+	tests := []struct {
+		name string
+		args args
+		want []*transactions.BankStatements
+	}{
+		{
+			name: "Succesful",
+			args: args{
+				startDate: time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, time.Local),
+				endDate:   time.Date(2024, time.Month(1), 31, 0, 0, 0, 0, time.Local),
+			},
+			want: []*transactions.BankStatements{data[1], data[2]},
+		},
+		{
+			name: "Open start date",
+			args: args{
+				endDate: time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, time.Local),
+			},
+			want: []*transactions.BankStatements{data[0], data[1]},
+		},
+		{
+			name: "Open end date",
+			args: args{
+				startDate: time.Date(2024, time.Month(2), 1, 0, 0, 0, 0, time.Local),
+			},
+			want: []*transactions.BankStatements{data[3]},
+		},
+		{
+			name: "Open range",
+			args: args{},
+			want: data,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterBankStatementsByDate(data, tt.args.startDate, tt.args.endDate); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterBankStatementsByDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_filterSystemTransactionsByDate(t *testing.T) {
+	data := []*transactions.SystemTransactions{
+		{
+			TransactionID:       "1",
+			RealTransactionTime: time.Date(2023, time.Month(12), 31, 0, 0, 0, 0, time.Local),
+		},
+		{
+			TransactionID:       "2",
+			RealTransactionTime: time.Date(2024, time.Month(1), 15, 0, 0, 0, 0, time.Local),
+		},
+		{
+			TransactionID:       "3",
+			RealTransactionTime: time.Date(2024, time.Month(2), 1, 0, 0, 0, 0, time.Local),
+		},
+	}
+
+	type args struct {
+		startDate time.Time
+		endDate   time.Time
+	}
+	tests := []struct {
+		name string
+		args args
+		want []*transactions.SystemTransactions
+	}{
+		{
+			name: "Succesful",
+			args: args{
+				startDate: time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, time.Local),
+				endDate:   time.Date(2024, time.Month(1), 31, 0, 0, 0, 0, time.Local),
+			},
+			want: []*transactions.SystemTransactions{data[1]},
+		},
+		{
+			name: "Nothing in range",
+			args: args{
+				startDate: time.Date(2024, time.Month(3), 1, 0, 0, 0, 0, time.Local),
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterSystemTransactionsByDate(data, tt.args.startDate, tt.args.endDate); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterSystemTransactionsByDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// streamedBankStatements mocks unmarshalCsvToStructForBankStatements with the rows returned by unmarshal
+func streamedBankStatements(unmarshal func(file *multipart.File) ([]*transactions.BankStatements, error)) func(*multipart.File, func(*transactions.BankStatements) error) error {
+	return func(file *multipart.File, add func(*transactions.BankStatements) error) error {
+		data, err := unmarshal(file)
+		if err != nil {
+			return err
+		}
+		for _, d := range data {
+			err = add(d)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// streamedSystemTransactions mocks unmarshalCsvToStructForSystemTransactions with the rows returned by unmarshal
+func streamedSystemTransactions(unmarshal func(file *multipart.File) ([]*transactions.SystemTransactions, error)) func(*multipart.File, func(*transactions.SystemTransactions) error) error {
+	return func(file *multipart.File, add func(*transactions.SystemTransactions) error) error {
+		data, err := unmarshal(file)
+		if err != nil {
+			return err
+		}
+		for _, d := range data {
+			err = add(d)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestTransactionUsecase_DoReconciliation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Mock ReconciliationRepository
+	mockRepository := repositoryMock.NewMockReconciliationRepository(ctrl)
+	startedAt := time.Date(2024, time.Month(2), 1, 9, 0, 0, 0, time.UTC)
+
+	// storedRunData is one bank statement matched with one system transaction
+	storedRunData := func() {
+		unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+			return []*transactions.BankStatements{
+				{ID: "BRI_1", Amount: "Rp2,000,000", Date: "03/01/2024"},
+			}, nil
+		})
+		unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+			return []*transactions.SystemTransactions{
+				{TransactionID: "1", Amount: "Rp2,000,000", RawType: "CREDIT", TransactionTime: "03/01/2024 08:20:00"},
+			}, nil
+		})
+		newID = func() string { return "run-1" }
+		timeNow = func() time.Time { return startedAt }
+	}
+	storedRunResult := transactions.DoReconciliationResponse{
+		TransactionsProceed: 1,
+		MatchedTransaction:  1,
+		MatchedTransactions: []transactions.MatchedTransaction{
+			{
+				TransactionID:    "1",
+				UniqueIdentifier: "BRI_1",
+				BankSource:       "BRI",
+				Type:             transactions.CREDIT,
+				Amount:           money.MustParse("2000000", money.DefaultCurrency),
+				BankAmount:       money.MustParse("2000000", money.DefaultCurrency),
+				Difference:       money.MustParse("0", money.DefaultCurrency),
+				TransactionTime:  "03/01/2024 08:20:00",
+				Date:             "03/01/2024",
+			},
+		},
+		MissingBankStatements: map[string][]transactions.BankStatements{},
+		TotalDiscrepancies:    money.MustParse("0", money.DefaultCurrency),
+		ReportingCurrency:     money.DefaultCurrency,
+		TimeZone:              "Asia/Jakarta",
+		CurrencySubtotals: map[string]transactions.CurrencySubtotal{
+			money.DefaultCurrency: {
+				MatchedAmount:                   money.MustParse("2000000", money.DefaultCurrency),
+				MissingBankStatementsAmount:     money.MustParse("0", money.DefaultCurrency),
+				MissingSystemTransactionsAmount: money.MustParse("0", money.DefaultCurrency),
+			},
+		},
+	}
+	storedRunParam := func() transactions.DoReconciliationRequest {
+		return transactions.DoReconciliationRequest{
+			SystemTransactions: nopMultipartFile{bytes.NewReader([]byte(""))},
+			BankStatements: []transactions.BankStatementsUpload{
+				{File: nopMultipartFile{bytes.NewReader([]byte("abc"))}, Name: "bri.csv"},
+			},
+		}
+	}
+	storedRun := transactions.ReconciliationRun{
+		ID:          "run-1",
+		Status:      transactions.RunSucceeded,
+		StartedAt:   startedAt,
+		FinishedAt:  startedAt,
+		PeriodStart: "2024-01-03",
+		PeriodEnd:   "2024-01-03",
+		Parameters: transactions.RunParameters{
+			AmountTolerance:   "0.00",
+			BankSourcePattern: transactions.DefaultBankSourcePattern.String(),
+			TimeZone:          "Asia/Jakarta",
+			BankStatementsOptions: transactions.RunFileOptions{
+				NumberFormat: money.FormatEnglish,
+				Currency:     money.DefaultCurrency,
+				TimeZone:     "Asia/Jakarta",
+			},
+			SystemTransactionsOptions: transactions.RunFileOptions{
+				NumberFormat: money.FormatEnglish,
+				Currency:     money.DefaultCurrency,
+				TimeZone:     "Asia/Jakarta",
+			},
+		},
+		Checksums: []transactions.FileChecksum{
+			{File: "system_transactions", SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+			{File: "bank_statements", Name: "bri.csv", SHA256: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		},
+	}
+
+	type args struct {
+		ctx   context.Context
+		param transactions.DoReconciliationRequest
+	}
+	tests := []struct {
+		name       string
+		usecase    TransactionUsecase
+		args       args
+		wantResult transactions.DoReconciliationResponse
+		wantErr    bool
+		mock       func()
+		unmock     func()
+	}{
+		{
+			name:    "Succesful",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements: []transactions.BankStatementsUpload{{}},
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{
+				TransactionsProceed:  3,
+				MatchedTransaction:   2,
+				UnmatchedTransaction: 3,
+				MatchedTransactions: []transactions.MatchedTransaction{
+					{
+						TransactionID:    "10",
+						UniqueIdentifier: "MANDIRI_12348",
+						BankSource:       "MANDIRI",
+						Type:             transactions.CREDIT,
+						Amount:           money.MustParse("2000000", money.DefaultCurrency),
+						BankAmount:       money.MustParse("2000000", money.DefaultCurrency),
+						Difference:       money.MustParse("0", money.DefaultCurrency),
+						TransactionTime:  "13/01/2024 08:20:00",
+						Date:             "13/01/2024",
+					},
+					{
+						TransactionID:    "12",
+						UniqueIdentifier: "MANDIRI_12349",
+						BankSource:       "MANDIRI",
+						Type:             transactions.CREDIT,
+						Amount:           money.MustParse("2000000", money.DefaultCurrency),
+						BankAmount:       money.MustParse("2000000", money.DefaultCurrency),
+						Difference:       money.MustParse("0", money.DefaultCurrency),
+						TransactionTime:  "20/01/2024 08:20:00",
+						Date:             "20/01/2024",
+					},
+				},
+				MissingBankStatements: map[string][]transactions.BankStatements{
+					"MANDIRI": {
+						{
+							ID:              "MANDIRI_12346",
+							Amount:          "Rp2,500,000",
+							RealAmount:      money.MustParse("2500000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2500000", money.DefaultCurrency),
+							Date:            "15/01/2024",
+							RealDate:        time.Date(2024, time.Month(1), 15, 0, 0, 0, 0, transactions.DefaultLocation),
+							BankSource:      "MANDIRI",
+							Type:            transactions.CREDIT,
+						},
+						{
+							ID:              "MANDIRI_12347",
+							Amount:          "Rp2,500,000",
+							RealAmount:      money.MustParse("2500000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2500000", money.DefaultCurrency),
+							Date:            "19/01/2024",
+							RealDate:        time.Date(2024, time.Month(1), 19, 0, 0, 0, 0, transactions.DefaultLocation),
+							BankSource:      "MANDIRI",
+							Type:            transactions.CREDIT,
+						},
+					},
+				},
+				MissingSystemTransactions: []transactions.SystemTransactions{
+					{
+						TransactionID:       "11",
+						Amount:              "Rp2,000,000",
+						RealAmount:          money.MustParse("2000000", money.DefaultCurrency),
+						Currency:            money.DefaultCurrency,
+						ReportingAmount:     money.MustParse("2000000", money.DefaultCurrency),
+						Type:                transactions.CREDIT,
+						TransactionTime:     "14/01/2024 08:20:00",
+						RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, transactions.DefaultLocation),
+					},
+				},
+				TotalDiscrepancies: money.MustParse("0", money.DefaultCurrency),
+				ReportingCurrency:  money.DefaultCurrency,
+				TimeZone:           "Asia/Jakarta",
+				CurrencySubtotals: map[string]transactions.CurrencySubtotal{
+					money.DefaultCurrency: {
+						MatchedAmount:                   money.MustParse("4000000", money.DefaultCurrency),
+						MissingBankStatementsAmount:     money.MustParse("5000000", money.DefaultCurrency),
+						MissingSystemTransactionsAmount: money.MustParse("2000000", money.DefaultCurrency),
+					},
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
+						{
+							ID:              "MANDIRI_12346",
+							Amount:          "Rp2,500,000",
+							RealAmount:      money.MustParse("2500000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2500000", money.DefaultCurrency),
+							Date:            "15/01/2024",
+							Type:            transactions.CREDIT,
+							BankSource:      "MANDIRI",
+							RealDate:        time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+						{
+							ID:              "MANDIRI_12347",
+							Amount:          "Rp2,500,000",
+							RealAmount:      money.MustParse("2500000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2500000", money.DefaultCurrency),
+							Date:            "19/01/2024",
+							Type:            transactions.CREDIT,
+							BankSource:      "MANDIRI",
+							RealDate:        time.Date(2024, time.Month(1), 19, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+						{
+							ID:              "MANDIRI_12348",
+							Amount:          "Rp2,000,000",
+							RealAmount:      money.MustParse("2000000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2000000", money.DefaultCurrency),
+							Date:            "13/01/2024",
+							Type:            transactions.CREDIT,
+							BankSource:      "MANDIRI",
+							RealDate:        time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+						{
+							ID:              "MANDIRI_12349",
+							Amount:          "Rp2,000,000",
+							RealAmount:      money.MustParse("2000000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2000000", money.DefaultCurrency),
+							Date:            "20/01/2024",
+							Type:            transactions.CREDIT,
+							BankSource:      "MANDIRI",
+							RealDate:        time.Date(2024, time.Month(1), 20, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
+						{
+							TransactionID:       "10",
+							Amount:              "Rp2,000,000",
+							RealAmount:          money.MustParse("2000000", money.DefaultCurrency),
+							Currency:            money.DefaultCurrency,
+							ReportingAmount:     money.MustParse("2000000", money.DefaultCurrency),
+							Type:                transactions.CREDIT,
+							TransactionTime:     "13/01/2024 08:20:00",
+							RealTransactionTime: time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+						{
+							TransactionID:       "11",
+							Amount:              "Rp2,000,000",
+							RealAmount:          money.MustParse("2000000", money.DefaultCurrency),
+							Currency:            money.DefaultCurrency,
+							ReportingAmount:     money.MustParse("2000000", money.DefaultCurrency),
+							Type:                transactions.CREDIT,
+							TransactionTime:     "14/01/2024 08:20:00",
+							RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+						{
+							TransactionID:       "12",
+							Amount:              "Rp2,000,000",
+							RealAmount:          money.MustParse("2000000", money.DefaultCurrency),
+							Currency:            money.DefaultCurrency,
+							ReportingAmount:     money.MustParse("2000000", money.DefaultCurrency),
+							Type:                transactions.CREDIT,
+							TransactionTime:     "20/01/2024 08:20:00",
+							RealTransactionTime: time.Date(2024, time.Month(1), 20, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+					}, nil
+				})
+
+			},
+			unmock: func() {},
+		},
+		{
+			name:    "Succesful with sorted runs on disk",
+			usecase: TransactionUsecase{SortBufferSize: 1, TempDir: t.TempDir()},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements: []transactions.BankStatementsUpload{{}},
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{
+				TransactionsProceed:  3,
+				MatchedTransaction:   2,
+				UnmatchedTransaction: 3,
+				MatchedTransactions: []transactions.MatchedTransaction{
+					{
+						TransactionID:    "10",
+						UniqueIdentifier: "MANDIRI_12348",
+						BankSource:       "MANDIRI",
+						Type:             transactions.CREDIT,
+						Amount:           money.MustParse("2000000", money.DefaultCurrency),
+						BankAmount:       money.MustParse("2000000", money.DefaultCurrency),
+						Difference:       money.MustParse("0", money.DefaultCurrency),
+						TransactionTime:  "13/01/2024 08:20:00",
+						Date:             "13/01/2024",
+					},
+					{
+						TransactionID:    "12",
+						UniqueIdentifier: "MANDIRI_12349",
+						BankSource:       "MANDIRI",
+						Type:             transactions.CREDIT,
+						Amount:           money.MustParse("2000000", money.DefaultCurrency),
+						BankAmount:       money.MustParse("2000000", money.DefaultCurrency),
+						Difference:       money.MustParse("0", money.DefaultCurrency),
+						TransactionTime:  "20/01/2024 08:20:00",
+						Date:             "20/01/2024",
+					},
+				},
+				MissingBankStatements: map[string][]transactions.BankStatements{
+					"MANDIRI": {
+						{
+							ID:              "MANDIRI_12346",
+							Amount:          "Rp2,500,000",
+							RealAmount:      money.MustParse("2500000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2500000", money.DefaultCurrency),
+							Date:            "15/01/2024",
+							RealDate:        time.Date(2024, time.Month(1), 15, 0, 0, 0, 0, transactions.DefaultLocation),
+							BankSource:      "MANDIRI",
+							Type:            transactions.CREDIT,
+						},
+						{
+							ID:              "MANDIRI_12347",
+							Amount:          "Rp2,500,000",
+							RealAmount:      money.MustParse("2500000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2500000", money.DefaultCurrency),
+							Date:            "19/01/2024",
+							RealDate:        time.Date(2024, time.Month(1), 19, 0, 0, 0, 0, transactions.DefaultLocation),
+							BankSource:      "MANDIRI",
+							Type:            transactions.CREDIT,
+						},
+					},
+				},
+				MissingSystemTransactions: []transactions.SystemTransactions{
+					{
+						TransactionID:       "11",
+						Amount:              "Rp2,000,000",
+						RealAmount:          money.MustParse("2000000", money.DefaultCurrency),
+						Currency:            money.DefaultCurrency,
+						ReportingAmount:     money.MustParse("2000000", money.DefaultCurrency),
+						Type:                transactions.CREDIT,
+						TransactionTime:     "14/01/2024 08:20:00",
+						RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, transactions.DefaultLocation),
+					},
+				},
+				TotalDiscrepancies: money.MustParse("0", money.DefaultCurrency),
+				ReportingCurrency:  money.DefaultCurrency,
+				TimeZone:           "Asia/Jakarta",
+				CurrencySubtotals: map[string]transactions.CurrencySubtotal{
+					money.DefaultCurrency: {
+						MatchedAmount:                   money.MustParse("4000000", money.DefaultCurrency),
+						MissingBankStatementsAmount:     money.MustParse("5000000", money.DefaultCurrency),
+						MissingSystemTransactionsAmount: money.MustParse("2000000", money.DefaultCurrency),
+					},
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
+						{
+							ID:              "MANDIRI_12346",
+							Amount:          "Rp2,500,000",
+							RealAmount:      money.MustParse("2500000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2500000", money.DefaultCurrency),
+							Date:            "15/01/2024",
+							Type:            transactions.CREDIT,
+							BankSource:      "MANDIRI",
+							RealDate:        time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+						{
+							ID:              "MANDIRI_12347",
+							Amount:          "Rp2,500,000",
+							RealAmount:      money.MustParse("2500000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2500000", money.DefaultCurrency),
+							Date:            "19/01/2024",
+							Type:            transactions.CREDIT,
+							BankSource:      "MANDIRI",
+							RealDate:        time.Date(2024, time.Month(1), 19, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+						{
+							ID:              "MANDIRI_12348",
+							Amount:          "Rp2,000,000",
+							RealAmount:      money.MustParse("2000000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2000000", money.DefaultCurrency),
+							Date:            "13/01/2024",
+							Type:            transactions.CREDIT,
+							BankSource:      "MANDIRI",
+							RealDate:        time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+						{
+							ID:              "MANDIRI_12349",
+							Amount:          "Rp2,000,000",
+							RealAmount:      money.MustParse("2000000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2000000", money.DefaultCurrency),
+							Date:            "20/01/2024",
+							Type:            transactions.CREDIT,
+							BankSource:      "MANDIRI",
+							RealDate:        time.Date(2024, time.Month(1), 20, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
+						{
+							TransactionID:       "10",
+							Amount:              "Rp2,000,000",
+							RealAmount:          money.MustParse("2000000", money.DefaultCurrency),
+							Currency:            money.DefaultCurrency,
+							ReportingAmount:     money.MustParse("2000000", money.DefaultCurrency),
+							Type:                transactions.CREDIT,
+							TransactionTime:     "13/01/2024 08:20:00",
+							RealTransactionTime: time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+						{
+							TransactionID:       "11",
+							Amount:              "Rp2,000,000",
+							RealAmount:          money.MustParse("2000000", money.DefaultCurrency),
+							Currency:            money.DefaultCurrency,
+							ReportingAmount:     money.MustParse("2000000", money.DefaultCurrency),
+							Type:                transactions.CREDIT,
+							TransactionTime:     "14/01/2024 08:20:00",
+							RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+						{
+							TransactionID:       "12",
+							Amount:              "Rp2,000,000",
+							RealAmount:          money.MustParse("2000000", money.DefaultCurrency),
+							Currency:            money.DefaultCurrency,
+							ReportingAmount:     money.MustParse("2000000", money.DefaultCurrency),
+							Type:                transactions.CREDIT,
+							TransactionTime:     "20/01/2024 08:20:00",
+							RealTransactionTime: time.Date(2024, time.Month(1), 20, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+					}, nil
+				})
+
+			},
+			unmock: func() {},
+		},
+		{
+			name:    "unmarshalCsvToStructForBankStatements return error",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements: []transactions.BankStatementsUpload{{}},
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{},
+			wantErr:    true,
+			mock: func() {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return nil, errMock
+				})
+			},
+			unmock: func() {},
+		},
+		{
+			name:    "unmarshalCsvToStructForSystemTransactions return error",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements: []transactions.BankStatementsUpload{{}},
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{},
+			wantErr:    true,
+			mock: func() {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
+						{
+							ID:              "MANDIRI_12346",
+							Amount:          "Rp2,500,000",
+							RealAmount:      money.MustParse("2500000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2500000", money.DefaultCurrency),
+							Date:            "15/01/2024",
+							Type:            transactions.DEBIT,
+							BankSource:      "MANDIRI",
+							RealDate:        time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, transactions.DefaultLocation),
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return nil, errMock
+				})
+			},
+			unmock: func() {},
+		},
+		{
+			name:    "Succesful with amount tolerance",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements:  []transactions.BankStatementsUpload{{}},
+					AmountTolerance: money.MustParse("6500", money.DefaultCurrency),
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{
+				TransactionsProceed:  1,
+				MatchedTransaction:   1,
+				UnmatchedTransaction: 0,
+				MatchedWithDifference: []transactions.MatchedTransaction{
+					{
+						TransactionID:    "10",
+						UniqueIdentifier: "BRI_12348",
+						BankSource:       "BRI",
+						Type:             transactions.CREDIT,
+						Amount:           money.MustParse("2000000", money.DefaultCurrency),
+						BankAmount:       money.MustParse("1993500", money.DefaultCurrency),
+						Difference:       money.MustParse("-6500", money.DefaultCurrency),
+						TransactionTime:  "13/01/2024 08:20:00",
+						Date:             "13/01/2024",
+					},
+				},
+				MissingBankStatements: map[string][]transactions.BankStatements{},
+				TotalDiscrepancies:    money.MustParse("6500", money.DefaultCurrency),
+				ReportingCurrency:     money.DefaultCurrency,
+				TimeZone:              "Asia/Jakarta",
+				CurrencySubtotals: map[string]transactions.CurrencySubtotal{
+					money.DefaultCurrency: {
+						MatchedAmount:                   money.MustParse("2000000", money.DefaultCurrency),
+						MissingBankStatementsAmount:     money.MustParse("0", money.DefaultCurrency),
+						MissingSystemTransactionsAmount: money.MustParse("0", money.DefaultCurrency),
+					},
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
+						{
+							ID:     "BRI_12348",
+							Amount: "Rp1,993,500",
+							Date:   "13/01/2024",
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
+						{
+							TransactionID:   "10",
+							Amount:          "Rp2,000,000",
+							Type:            transactions.CREDIT,
+							TransactionTime: "13/01/2024 08:20:00",
+						},
+					}, nil
+				})
+			},
+			unmock: func() {},
+		},
+		{
+			name:    "AmountTolerance is negative",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements:         []transactions.BankStatementsUpload{{}},
+					AmountTolerancePercent: -1,
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{},
+			wantErr:    true,
+			mock:       func() {},
+			unmock:     func() {},
+		},
+		{
+			name:    "Succesful with date range",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements: []transactions.BankStatementsUpload{{}},
+					StartDate:      time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, transactions.DefaultLocation),
+					EndDate:        time.Date(2024, time.Month(1), 31, 0, 0, 0, 0, transactions.DefaultLocation),
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{
+				TransactionsProceed:  1,
+				MatchedTransaction:   1,
+				UnmatchedTransaction: 0,
+				MatchedTransactions: []transactions.MatchedTransaction{
+					{
+						TransactionID:    "10",
+						UniqueIdentifier: "BRI_12348",
+						BankSource:       "BRI",
+						Type:             transactions.CREDIT,
+						Amount:           money.MustParse("2000000", money.DefaultCurrency),
+						BankAmount:       money.MustParse("2000000", money.DefaultCurrency),
+						Difference:       money.MustParse("0", money.DefaultCurrency),
+						TransactionTime:  "31/01/2024 08:20:00",
+						Date:             "31/01/2024",
+					},
+				},
+				MissingBankStatements: map[string][]transactions.BankStatements{},
+				TotalDiscrepancies:    money.MustParse("0", money.DefaultCurrency),
+				ReportingCurrency:     money.DefaultCurrency,
+				TimeZone:              "Asia/Jakarta",
+				CurrencySubtotals: map[string]transactions.CurrencySubtotal{
+					money.DefaultCurrency: {
+						MatchedAmount:                   money.MustParse("2000000", money.DefaultCurrency),
+						MissingBankStatementsAmount:     money.MustParse("0", money.DefaultCurrency),
+						MissingSystemTransactionsAmount: money.MustParse("0", money.DefaultCurrency),
+					},
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
+						{
+							ID:     "BRI_12348",
+							Amount: "Rp2,000,000",
+							Date:   "31/01/2024",
+						},
+						{
+							ID:     "BRI_12349",
+							Amount: "Rp2,000,000",
+							Date:   "01/02/2024",
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
+						{
+							TransactionID:   "9",
+							Amount:          "Rp2,000,000",
+							Type:            transactions.CREDIT,
+							TransactionTime: "31/12/2023 08:20:00",
+						},
+						{
+							TransactionID:   "10",
+							Amount:          "Rp2,000,000",
+							Type:            transactions.CREDIT,
+							TransactionTime: "31/01/2024 08:20:00",
+						},
+					}, nil
+				})
+			},
+			unmock: func() {},
+		},
+		{
+			name:    "Succesful with date range and date tolerance",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements:    []transactions.BankStatementsUpload{{}},
+					DateToleranceDays: 2,
+					StartDate:         time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, transactions.DefaultLocation),
+					EndDate:           time.Date(2024, time.Month(1), 31, 0, 0, 0, 0, transactions.DefaultLocation),
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{
+				TransactionsProceed:  2,
+				MatchedTransaction:   2,
+				UnmatchedTransaction: 0,
+				MatchedTransactions: []transactions.MatchedTransaction{
+					{
+						TransactionID:    "9",
+						UniqueIdentifier: "BRI_12347",
+						BankSource:       "BRI",
+						Type:             transactions.CREDIT,
+						Amount:           money.MustParse("1000000", money.DefaultCurrency),
+						BankAmount:       money.MustParse("1000000", money.DefaultCurrency),
+						Difference:       money.MustParse("0", money.DefaultCurrency),
+						TransactionTime:  "31/12/2023 08:20:00",
+						Date:             "01/01/2024",
+					},
+					{
+						TransactionID:    "10",
+						UniqueIdentifier: "BRI_12348",
+						BankSource:       "BRI",
+						Type:             transactions.CREDIT,
+						Amount:           money.MustParse("2000000", money.DefaultCurrency),
+						BankAmount:       money.MustParse("2000000", money.DefaultCurrency),
+						Difference:       money.MustParse("0", money.DefaultCurrency),
+						TransactionTime:  "31/01/2024 08:20:00",
+						Date:             "01/02/2024",
+					},
+				},
+				MissingBankStatements: map[string][]transactions.BankStatements{},
+				TotalDiscrepancies:    money.MustParse("0", money.DefaultCurrency),
+				ReportingCurrency:     money.DefaultCurrency,
+				TimeZone:              "Asia/Jakarta",
+				CurrencySubtotals: map[string]transactions.CurrencySubtotal{
+					money.DefaultCurrency: {
+						MatchedAmount:                   money.MustParse("3000000", money.DefaultCurrency),
+						MissingBankStatementsAmount:     money.MustParse("0", money.DefaultCurrency),
+						MissingSystemTransactionsAmount: money.MustParse("0", money.DefaultCurrency),
+					},
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				// the records of 30/12/2023 and 02/02/2024 have no counterpart but are outside the range
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
+						{ID: "BRI_12346", Amount: "Rp5,000", Date: "30/12/2023"},
+						{ID: "BRI_12347", Amount: "Rp1,000,000", Date: "01/01/2024"},
+						{ID: "BRI_12348", Amount: "Rp2,000,000", Date: "01/02/2024"},
+					}, nil
+				})
+
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
+						{TransactionID: "9", Amount: "Rp1,000,000", Type: transactions.CREDIT, TransactionTime: "31/12/2023 08:20:00"},
+						{TransactionID: "10", Amount: "Rp2,000,000", Type: transactions.CREDIT, TransactionTime: "31/01/2024 08:20:00"},
+						{TransactionID: "11", Amount: "Rp7,000", Type: transactions.CREDIT, TransactionTime: "02/02/2024 08:20:00"},
+					}, nil
+				})
+			},
+			unmock: func() {},
+		},
+		{
+			name:    "StartDate is after EndDate",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements: []transactions.BankStatementsUpload{{}},
+					StartDate:      time.Date(2024, time.Month(2), 1, 0, 0, 0, 0, transactions.DefaultLocation),
+					EndDate:        time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, transactions.DefaultLocation),
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{},
+			wantErr:    true,
+			mock:       func() {},
+			unmock:     func() {},
+		},
+		{
+			name:    "Succesful with exchange rates",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements:    []transactions.BankStatementsUpload{{}},
+					ReportingCurrency: "IDR",
+					ExchangeRates:     nopMultipartFile{bytes.NewReader(nil)},
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{
+				TransactionsProceed:  1,
+				MatchedTransaction:   1,
+				UnmatchedTransaction: 0,
+				MatchedTransactions: []transactions.MatchedTransaction{
+					{
+						TransactionID:    "10",
+						UniqueIdentifier: "BRI_12348",
+						BankSource:       "BRI",
+						Type:             transactions.CREDIT,
+						Amount:           money.MustParse("3100000", money.DefaultCurrency),
+						BankAmount:       money.MustParse("200", "USD"),
+						Difference:       money.MustParse("0", money.DefaultCurrency),
+						TransactionTime:  "13/01/2024 08:20:00",
+						Date:             "13/01/2024",
+					},
+				},
+				MissingBankStatements: map[string][]transactions.BankStatements{},
+				TotalDiscrepancies:    money.MustParse("0", money.DefaultCurrency),
+				ReportingCurrency:     money.DefaultCurrency,
+				TimeZone:              "Asia/Jakarta",
+				CurrencySubtotals: map[string]transactions.CurrencySubtotal{
+					money.DefaultCurrency: {
+						MatchedAmount:                   money.MustParse("3100000", money.DefaultCurrency),
+						MissingBankStatementsAmount:     money.MustParse("0", money.DefaultCurrency),
+						MissingSystemTransactionsAmount: money.MustParse("0", money.DefaultCurrency),
+					},
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
+						{
+							ID:     "BRI_12348",
+							Amount: "$200",
+							Date:   "13/01/2024",
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
+						{
+							TransactionID:   "10",
+							Amount:          "Rp3,100,000",
+							Type:            transactions.CREDIT,
+							TransactionTime: "13/01/2024 08:20:00",
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForExchangeRates = func(_ *multipart.File) (result []*transactions.ExchangeRate, err error) {
+					return []*transactions.ExchangeRate{
+						{
+							Date: "12/01/2024",
+							From: "usd",
+							To:   "idr",
+							Rate: "15500",
+						},
+					}, nil
+				}
+			},
+			unmock: func() {},
+		},
+		{
+			name:    "Succesful lenient with exchange rate that is too old",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements:    []transactions.BankStatementsUpload{{}},
+					ReportingCurrency: "IDR",
+					ExchangeRates:     nopMultipartFile{bytes.NewReader(nil)},
+					ValidationMode:    transactions.ValidationLenient,
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{
+				TransactionsProceed:  1,
+				MatchedTransaction:   0,
+				UnmatchedTransaction: 1,
+				MissingSystemTransactions: []transactions.SystemTransactions{
+					{
+						TransactionID:       "10",
+						Amount:              "Rp3,100,000",
+						RealAmount:          money.MustParse("3100000", money.DefaultCurrency),
+						Currency:            money.DefaultCurrency,
+						ReportingAmount:     money.MustParse("3100000", money.DefaultCurrency),
+						Type:                transactions.CREDIT,
+						TransactionTime:     "13/01/2024 08:20:00",
+						RealTransactionTime: time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, transactions.DefaultLocation),
+					},
+				},
+				MissingBankStatements: map[string][]transactions.BankStatements{},
+				TotalDiscrepancies:    money.MustParse("0", money.DefaultCurrency),
+				ReportingCurrency:     money.DefaultCurrency,
+				TimeZone:              "Asia/Jakarta",
+				CurrencySubtotals: map[string]transactions.CurrencySubtotal{
+					money.DefaultCurrency: {
+						MatchedAmount:                   money.MustParse("0", money.DefaultCurrency),
+						MissingBankStatementsAmount:     money.MustParse("0", money.DefaultCurrency),
+						MissingSystemTransactionsAmount: money.MustParse("3100000", money.DefaultCurrency),
+					},
+				},
+				RejectedRows: []transactions.RowError{
+					{
+						File:   transactions.BankStatementsFile,
+						Column: "amount",
+						Value:  "$200",
+						Reason: "no exchange rate from USD to IDR on 2024-01-13 or up to 7 days before",
+					},
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
+						{
+							ID:     "BRI_12348",
+							Amount: "$200",
+							Date:   "13/01/2024",
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
+						{
+							TransactionID:   "10",
+							Amount:          "Rp3,100,000",
+							Type:            transactions.CREDIT,
+							TransactionTime: "13/01/2024 08:20:00",
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForExchangeRates = func(_ *multipart.File) (result []*transactions.ExchangeRate, err error) {
+					return []*transactions.ExchangeRate{
+						{
+							Date: "01/01/2024",
+							From: "USD",
+							To:   "IDR",
+							Rate: "15500",
+						},
+					}, nil
+				}
+			},
+			unmock: func() {},
+		},
+		{
+			name:    "Exchange rate is missing",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements: []transactions.BankStatementsUpload{{}},
+					ExchangeRates:  nopMultipartFile{bytes.NewReader(nil)},
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{},
+			wantErr:    true,
+			mock: func() {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
+						{
+							ID:     "BRI_12348",
+							Amount: "€200",
+							Date:   "13/01/2024",
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
+						{
+							TransactionID:   "10",
+							Amount:          "Rp3,100,000",
+							Type:            transactions.CREDIT,
+							TransactionTime: "13/01/2024 08:20:00",
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForExchangeRates = func(_ *multipart.File) (result []*transactions.ExchangeRate, err error) {
+					return []*transactions.ExchangeRate{
+						{
+							Date: "01/01/2024",
+							From: "USD",
+							To:   "IDR",
+							Rate: "15500",
+						},
+					}, nil
+				}
+			},
+			unmock: func() {},
+		},
+		{
+			name:    "Data has more than one currency",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements: []transactions.BankStatementsUpload{{}},
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{},
+			wantErr:    true,
+			mock: func() {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
+						{
+							ID:     "BRI_12348",
+							Amount: "$2,000",
+							Date:   "13/01/2024",
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
+						{
+							TransactionID:   "10",
+							Amount:          "Rp2,000,000",
+							Type:            transactions.CREDIT,
+							TransactionTime: "13/01/2024 08:20:00",
+						},
+					}, nil
+				})
+			},
+			unmock: func() {},
+		},
+		{
+			name:    "Succesful with multiple bank statements files",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements: []transactions.BankStatementsUpload{
+						{Name: "bca.csv", Bank: "BCA"},
+						{Name: "mandiri.csv", Bank: "MANDIRI"},
+					},
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{
+				TransactionsProceed:  1,
+				MatchedTransaction:   1,
+				UnmatchedTransaction: 1,
+				MatchedTransactions: []transactions.MatchedTransaction{
+					{
+						TransactionID:    "10",
+						UniqueIdentifier: "12348",
+						BankSource:       "BCA",
+						Type:             transactions.CREDIT,
+						Amount:           money.MustParse("2000000", money.DefaultCurrency),
+						BankAmount:       money.MustParse("2000000", money.DefaultCurrency),
+						Difference:       money.MustParse("0", money.DefaultCurrency),
+						TransactionTime:  "13/01/2024 08:20:00",
+						Date:             "13/01/2024",
+					},
+				},
+				MissingBankStatements: map[string][]transactions.BankStatements{
+					"MANDIRI": {
+						{
+							ID:              "12348",
+							Amount:          "Rp2,000,000",
+							RealAmount:      money.MustParse("2000000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2000000", money.DefaultCurrency),
+							Date:            "13/01/2024",
+							RealDate:        time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, transactions.DefaultLocation),
+							Bank:            "MANDIRI",
+							BankSource:      "MANDIRI",
+							SourceFile:      "mandiri.csv",
+							Type:            transactions.CREDIT,
+							Line:            2,
+						},
 					},
-					{
-						TransactionID:       "3",
-						Amount:              "Rp2,000,000",
-						RealAmount:          2000000,
-						Type:                transactions.DEBIT,
-						TransactionTime:     "17/02/2024 8:20:00",
-						RealTransactionTime: time.Date(2024, time.Month(2), 17, 8, 20, 0, 0, time.Local),
+				},
+				TotalDiscrepancies: money.MustParse("0", money.DefaultCurrency),
+				ReportingCurrency:  money.DefaultCurrency,
+				TimeZone:           "Asia/Jakarta",
+				CurrencySubtotals: map[string]transactions.CurrencySubtotal{
+					money.DefaultCurrency: {
+						MatchedAmount:                   money.MustParse("2000000", money.DefaultCurrency),
+						MissingBankStatementsAmount:     money.MustParse("2000000", money.DefaultCurrency),
+						MissingSystemTransactionsAmount: money.MustParse("0", money.DefaultCurrency),
 					},
 				},
-				targetDate: time.Date(2024, time.Month(2), 15, 8, 20, 0, 0, time.Local),
-				targetType: transactions.DEBIT,
 			},
-			want: &transactions.SystemTransactions{
-				TransactionID:       "1",
-				Amount:              "Rp2,000,000",
-				RealAmount:          2000000,
-				Type:                transactions.DEBIT,
-				TransactionTime:     "15/02/2024 8:20:00",
-				RealTransactionTime: time.Date(2024, time.Month(2), 15, 8, 20, 0, 0, time.Local),
+			wantErr: false,
+			mock: func() {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
+						{
+							ID:     "12348",
+							Amount: "Rp2,000,000",
+							Date:   "13/01/2024",
+							Line:   2,
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
+						{
+							TransactionID:   "10",
+							Amount:          "Rp2,000,000",
+							RawType:         "CREDIT",
+							TransactionTime: "13/01/2024 08:20:00",
+						},
+					}, nil
+				})
 			},
+			unmock: func() {},
 		},
 		{
-			name: "Not Found",
+			name:    "Invalid rows fail the reconciliation",
+			usecase: TransactionUsecase{},
 			args: args{
-				transaction: []*transactions.SystemTransactions{
-					{
-						TransactionID:       "1",
-						Amount:              "Rp2,000,000",
-						RealAmount:          2000000,
-						Type:                transactions.DEBIT,
-						TransactionTime:     "15/02/2024 8:20:00",
-						RealTransactionTime: time.Date(2024, time.Month(2), 15, 8, 20, 0, 0, time.Local),
-					},
-					{
-						TransactionID:       "2",
-						Amount:              "Rp2,000,000",
-						RealAmount:          2000000,
-						Type:                transactions.DEBIT,
-						TransactionTime:     "16/02/2024 8:20:00",
-						RealTransactionTime: time.Date(2024, time.Month(2), 16, 8, 20, 0, 0, time.Local),
-					},
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements: []transactions.BankStatementsUpload{{}},
 				},
-				targetDate: time.Date(2024, time.Month(2), 19, 8, 20, 0, 0, time.Local),
-				targetType: transactions.DEBIT,
 			},
-			want: nil,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := findSystemTransactionData(tt.args.transaction, tt.args.targetDate, tt.args.targetType); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("findSystemTransactionData() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
+			wantResult: transactions.DoReconciliationResponse{},
+			wantErr:    true,
+			mock: func() {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
+						{
+							ID:     "BRI_12348",
+							Amount: "Rp2,000,000",
+							Date:   "13/01/2024",
+							Line:   2,
+						},
+						{
+							ID:     "BRI12349",
+							Amount: "Rp1,000,000",
+							Date:   "13/01/2024",
+							Line:   3,
+						},
+					}, nil
+				})
 
-func TestTransactionUsecase_DoReconciliation(t *testing.T) {
-	type args struct {
-		ctx   context.Context
-		param transactions.DoReconciliationRequest
-	}
-	tests := []struct {
-		name       string
-		usecase    TransactionUsecase
-		args       args
-		wantResult transactions.DoReconciliationResponse
-		wantErr    bool
-		mock       func()
-		unmock     func()
-	}{
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
+						{
+							TransactionID:   "10",
+							Amount:          "Rp2,000,000",
+							Type:            transactions.CREDIT,
+							TransactionTime: "13/01/2024 08:20:00",
+							Line:            2,
+						},
+						{
+							TransactionID:   "11",
+							Amount:          "two million",
+							Type:            transactions.CREDIT,
+							TransactionTime: "13/01/2024 08:20:00",
+							Line:            3,
+						},
+					}, nil
+				})
+			},
+			unmock: func() {},
+		},
 		{
-			name:    "Succesful",
+			name:    "Succesful lenient skips invalid rows",
 			usecase: TransactionUsecase{},
 			args: args{
-				param: transactions.DoReconciliationRequest{},
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements: []transactions.BankStatementsUpload{{}},
+					ValidationMode: transactions.ValidationLenient,
+				},
 			},
 			wantResult: transactions.DoReconciliationResponse{
-				TransactionsProceed:  3,
-				MatchedTransaction:   2,
-				UnmatchedTransaction: 3,
-				MissingBankStatements: map[string][]transactions.BankStatements{
-					"MANDIRI": {
-						{
-							ID:         "MANDIRI_12346",
-							Amount:     "Rp2,500,000",
-							RealAmount: 2500000,
-							Date:       "15/01/2024",
-							RealDate:   time.Date(2024, time.Month(1), 15, 0, 0, 0, 0, time.Local),
-							BankSource: "MANDIRI",
-							Type:       transactions.CREDIT,
-						},
-						// MANDIRI_12347 Rp2,500,000 2.5e+06 19/01/2024 2024-01-19 00:00:00 +0700 WIB MANDIRI 2}
-						{
-							ID:         "MANDIRI_12347",
-							Amount:     "Rp2,500,000",
-							RealAmount: 2500000,
-							Date:       "19/01/2024",
-							RealDate:   time.Date(2024, time.Month(1), 19, 0, 0, 0, 0, time.Local),
-							BankSource: "MANDIRI",
-							Type:       transactions.CREDIT,
-						},
+				TransactionsProceed:  1,
+				MatchedTransaction:   1,
+				UnmatchedTransaction: 0,
+				MatchedTransactions: []transactions.MatchedTransaction{
+					{
+						TransactionID:    "10",
+						UniqueIdentifier: "BRI_12348",
+						BankSource:       "BRI",
+						Type:             transactions.CREDIT,
+						Amount:           money.MustParse("2000000", money.DefaultCurrency),
+						BankAmount:       money.MustParse("2000000", money.DefaultCurrency),
+						Difference:       money.MustParse("0", money.DefaultCurrency),
+						TransactionTime:  "13/01/2024 08:20:00",
+						Date:             "13/01/2024",
 					},
 				},
-				MissingSystemTransactions: []transactions.SystemTransactions{
+				MissingBankStatements: map[string][]transactions.BankStatements{},
+				TotalDiscrepancies:    money.MustParse("0", money.DefaultCurrency),
+				ReportingCurrency:     money.DefaultCurrency,
+				TimeZone:              "Asia/Jakarta",
+				CurrencySubtotals: map[string]transactions.CurrencySubtotal{
+					money.DefaultCurrency: {
+						MatchedAmount:                   money.MustParse("2000000", money.DefaultCurrency),
+						MissingBankStatementsAmount:     money.MustParse("0", money.DefaultCurrency),
+						MissingSystemTransactionsAmount: money.MustParse("0", money.DefaultCurrency),
+					},
+				},
+				RejectedRows: []transactions.RowError{
 					{
-						TransactionID:       "11",
-						Amount:              "Rp2,000,000",
-						RealAmount:          2000000,
-						Type:                transactions.CREDIT,
-						TransactionTime:     "14/01/2024 08:20:00",
-						RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+						File:   transactions.BankStatementsFile,
+						Line:   3,
+						Column: "unique_identifier",
+						Value:  "BRI12349",
+						Reason: "unique_identifier must contain the bank source, for example BCA_123",
+					},
+					{
+						File:   transactions.SystemTransactionsFile,
+						Line:   3,
+						Column: "amount",
+						Value:  "two million",
+						Reason: `amount format is invalid, invalid amount "two million": unexpected character 't'`,
 					},
 				},
-				TotalDiscrepancies: 4000000,
 			},
 			wantErr: false,
 			mock: func() {
-				unmarshalCsvToStructForBankStatements = func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
 					return []*transactions.BankStatements{
 						{
-							ID:         "MANDIRI_12346",
-							Amount:     "Rp2,500,000",
-							RealAmount: 2500000,
-							Date:       "15/01/2024",
-							Type:       transactions.CREDIT,
-							BankSource: "MANDIRI",
-							RealDate:   time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
+							ID:     "BRI_12348",
+							Amount: "Rp2,000,000",
+							Date:   "13/01/2024",
+							Line:   2,
 						},
 						{
-							ID:         "MANDIRI_12347",
-							Amount:     "Rp2,500,000",
-							RealAmount: 2500000,
-							Date:       "19/01/2024",
-							Type:       transactions.CREDIT,
-							BankSource: "MANDIRI",
-							RealDate:   time.Date(2024, time.Month(1), 19, 0, 0, 0, 0, time.Local),
+							ID:     "BRI12349",
+							Amount: "Rp1,000,000",
+							Date:   "13/01/2024",
+							Line:   3,
 						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
 						{
-							ID:         "MANDIRI_12348",
-							Amount:     "Rp2,000,000",
-							RealAmount: 2000000,
-							Date:       "13/01/2024",
-							Type:       transactions.CREDIT,
-							BankSource: "MANDIRI",
-							RealDate:   time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
+							TransactionID:   "10",
+							Amount:          "Rp2,000,000",
+							Type:            transactions.CREDIT,
+							TransactionTime: "13/01/2024 08:20:00",
+							Line:            2,
 						},
 						{
-							ID:         "MANDIRI_12349",
-							Amount:     "Rp2,000,000",
-							RealAmount: 2000000,
-							Date:       "20/01/2024",
-							Type:       transactions.CREDIT,
-							BankSource: "MANDIRI",
-							RealDate:   time.Date(2024, time.Month(1), 20, 0, 0, 0, 0, time.Local),
+							TransactionID:   "11",
+							Amount:          "two million",
+							Type:            transactions.CREDIT,
+							TransactionTime: "13/01/2024 08:20:00",
+							Line:            3,
 						},
 					}, nil
-				}
-
-				unmarshalCsvToStructForSystemTransactions = func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
-					return []*transactions.SystemTransactions{
+				})
+			},
+			unmock: func() {},
+		},
+		{
+			name:    "Succesful with detected date formats",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements:            []transactions.BankStatementsUpload{{}},
+					BankStatementsOptions:     transactions.FileOptions{DateFormat: dates.FormatAuto},
+					SystemTransactionsOptions: transactions.FileOptions{DateFormat: dates.FormatAuto},
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{
+				TransactionsProceed:  1,
+				MatchedTransaction:   1,
+				UnmatchedTransaction: 1,
+				MatchedTransactions: []transactions.MatchedTransaction{
+					{
+						TransactionID:    "10",
+						UniqueIdentifier: "BRI_12348",
+						BankSource:       "BRI",
+						Type:             transactions.CREDIT,
+						Amount:           money.MustParse("2000000", money.DefaultCurrency),
+						BankAmount:       money.MustParse("2000000", money.DefaultCurrency),
+						Difference:       money.MustParse("0", money.DefaultCurrency),
+						TransactionTime:  "2024-01-13T08:20:00",
+						Date:             "1/13/2024",
+					},
+				},
+				MissingBankStatements: map[string][]transactions.BankStatements{
+					"BRI": {
 						{
-							TransactionID:       "10",
-							Amount:              "Rp2,000,000",
-							RealAmount:          2000000,
-							Type:                transactions.CREDIT,
-							TransactionTime:     "13/01/2024 08:20:00",
-							RealTransactionTime: time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
+							ID:              "BRI_12349",
+							Amount:          "Rp1,000,000",
+							RealAmount:      money.MustParse("1000000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("1000000", money.DefaultCurrency),
+							Date:            "1/14/2024",
+							RealDate:        time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, transactions.DefaultLocation),
+							BankSource:      "BRI",
+							Type:            transactions.CREDIT,
+							Line:            3,
 						},
+					},
+				},
+				TotalDiscrepancies: money.MustParse("0", money.DefaultCurrency),
+				ReportingCurrency:  money.DefaultCurrency,
+				TimeZone:           "Asia/Jakarta",
+				CurrencySubtotals: map[string]transactions.CurrencySubtotal{
+					money.DefaultCurrency: {
+						MatchedAmount:                   money.MustParse("2000000", money.DefaultCurrency),
+						MissingBankStatementsAmount:     money.MustParse("1000000", money.DefaultCurrency),
+						MissingSystemTransactionsAmount: money.MustParse("0", money.DefaultCurrency),
+					},
+				},
+			},
+			wantErr: false,
+			mock: func() {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
 						{
-							TransactionID:       "11",
-							Amount:              "Rp2,000,000",
-							RealAmount:          2000000,
-							Type:                transactions.CREDIT,
-							TransactionTime:     "14/01/2024 08:20:00",
-							RealTransactionTime: time.Date(2024, time.Month(1), 14, 0, 0, 0, 0, time.Local),
+							ID:     "BRI_12348",
+							Amount: "Rp2,000,000",
+							Date:   "1/13/2024",
+							Line:   2,
 						},
 						{
-							TransactionID:       "12",
-							Amount:              "Rp2,000,000",
-							RealAmount:          2000000,
-							Type:                transactions.CREDIT,
-							TransactionTime:     "20/01/2024 08:20:00",
-							RealTransactionTime: time.Date(2024, time.Month(1), 20, 0, 0, 0, 0, time.Local),
+							ID:     "BRI_12349",
+							Amount: "Rp1,000,000",
+							Date:   "1/14/2024",
+							Line:   3,
 						},
 					}, nil
-				}
+				})
 
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
+						{
+							TransactionID:   "10",
+							Amount:          "Rp2,000,000",
+							TransactionTime: "2024-01-13T08:20:00",
+							Line:            2,
+						},
+					}, nil
+				})
 			},
 			unmock: func() {},
 		},
 		{
-			name:    "unmarshalCsvToStructForBankStatements return error",
+			name:    "Succesful with system transactions in UTC",
 			usecase: TransactionUsecase{},
 			args: args{
-				param: transactions.DoReconciliationRequest{},
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements:            []transactions.BankStatementsUpload{{}},
+					SystemTransactionsOptions: transactions.FileOptions{Location: time.UTC},
+				},
 			},
-			wantResult: transactions.DoReconciliationResponse{},
-			wantErr:    true,
+			wantResult: transactions.DoReconciliationResponse{
+				TransactionsProceed:  1,
+				MatchedTransaction:   1,
+				UnmatchedTransaction: 0,
+				MatchedTransactions: []transactions.MatchedTransaction{
+					{
+						TransactionID:    "10",
+						UniqueIdentifier: "BRI_12348",
+						BankSource:       "BRI",
+						Type:             transactions.CREDIT,
+						Amount:           money.MustParse("2000000", money.DefaultCurrency),
+						BankAmount:       money.MustParse("2000000", money.DefaultCurrency),
+						Difference:       money.MustParse("0", money.DefaultCurrency),
+						TransactionTime:  "12/01/2024 23:30:00",
+						Date:             "13/01/2024",
+					},
+				},
+				MissingBankStatements: map[string][]transactions.BankStatements{},
+				TotalDiscrepancies:    money.MustParse("0", money.DefaultCurrency),
+				ReportingCurrency:     money.DefaultCurrency,
+				TimeZone:              "Asia/Jakarta",
+				CurrencySubtotals: map[string]transactions.CurrencySubtotal{
+					money.DefaultCurrency: {
+						MatchedAmount:                   money.MustParse("2000000", money.DefaultCurrency),
+						MissingBankStatementsAmount:     money.MustParse("0", money.DefaultCurrency),
+						MissingSystemTransactionsAmount: money.MustParse("0", money.DefaultCurrency),
+					},
+				},
+			},
+			wantErr: false,
 			mock: func() {
-				unmarshalCsvToStructForBankStatements = func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
-					return nil, errMock
-				}
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+					return []*transactions.BankStatements{
+						{
+							ID:     "BRI_12348",
+							Amount: "Rp2,000,000",
+							Date:   "13/01/2024",
+							Line:   2,
+						},
+					}, nil
+				})
+
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+					return []*transactions.SystemTransactions{
+						{
+							TransactionID:   "10",
+							Amount:          "Rp2,000,000",
+							TransactionTime: "12/01/2024 23:30:00",
+							Line:            2,
+						},
+					}, nil
+				})
 			},
 			unmock: func() {},
 		},
 		{
-			name:    "unmarshalCsvToStructForSystemTransactions return error",
+			name:    "Date format is ambiguous",
 			usecase: TransactionUsecase{},
 			args: args{
-				param: transactions.DoReconciliationRequest{},
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements:        []transactions.BankStatementsUpload{{}},
+					BankStatementsOptions: transactions.FileOptions{DateFormat: dates.FormatAuto},
+				},
 			},
 			wantResult: transactions.DoReconciliationResponse{},
 			wantErr:    true,
 			mock: func() {
-				unmarshalCsvToStructForBankStatements = func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
 					return []*transactions.BankStatements{
 						{
-							ID:         "MANDIRI_12346",
-							Amount:     "Rp2,500,000",
-							RealAmount: 2500000,
-							Date:       "15/01/2024",
-							Type:       transactions.DEBIT,
-							BankSource: "MANDIRI",
-							RealDate:   time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
+							ID:     "BRI_12348",
+							Amount: "Rp2,000,000",
+							Date:   "03/01/2024",
+							Line:   2,
 						},
 					}, nil
-				}
-
-				unmarshalCsvToStructForSystemTransactions = func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
-					return nil, errMock
-				}
+				})
 			},
 			unmock: func() {},
 		},
+		{
+			name:    "DateToleranceDays is negative",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements:    []transactions.BankStatementsUpload{{}},
+					DateToleranceDays: -1,
+				},
+			},
+			wantResult: transactions.DoReconciliationResponse{},
+			wantErr:    true,
+			mock:       func() {},
+			unmock:     func() {},
+		},
 		{
 			name:    "BankStatements data is empty",
 			usecase: TransactionUsecase{},
 			args: args{
-				param: transactions.DoReconciliationRequest{},
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements: []transactions.BankStatementsUpload{{}},
+				},
 			},
 			wantResult: transactions.DoReconciliationResponse{},
 			wantErr:    true,
 			mock: func() {
-				unmarshalCsvToStructForBankStatements = func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
 					return []*transactions.BankStatements{}, nil
-				}
+				})
 
 			},
 			unmock: func() {},
@@ -787,38 +2719,125 @@ func TestTransactionUsecase_DoReconciliation(t *testing.T) {
 			name:    "SystemTransactions data is empty",
 			usecase: TransactionUsecase{},
 			args: args{
-				param: transactions.DoReconciliationRequest{},
+				ctx: context.Background(),
+				param: transactions.DoReconciliationRequest{
+					BankStatements: []transactions.BankStatementsUpload{{}},
+				},
 			},
 			wantResult: transactions.DoReconciliationResponse{},
 			wantErr:    true,
 			mock: func() {
-				unmarshalCsvToStructForBankStatements = func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+				unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
 					return []*transactions.BankStatements{
 						{
-							ID:         "MANDIRI_12346",
-							Amount:     "Rp2,500,000",
-							RealAmount: 2500000,
-							Date:       "15/01/2024",
-							Type:       transactions.DEBIT,
-							BankSource: "MANDIRI",
-							RealDate:   time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, time.Local),
+							ID:              "MANDIRI_12346",
+							Amount:          "Rp2,500,000",
+							RealAmount:      money.MustParse("2500000", money.DefaultCurrency),
+							Currency:        money.DefaultCurrency,
+							ReportingAmount: money.MustParse("2500000", money.DefaultCurrency),
+							Date:            "15/01/2024",
+							Type:            transactions.DEBIT,
+							BankSource:      "MANDIRI",
+							RealDate:        time.Date(2024, time.Month(1), 13, 0, 0, 0, 0, transactions.DefaultLocation),
 						},
 					}, nil
-				}
+				})
 
-				unmarshalCsvToStructForSystemTransactions = func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+				unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
 					return []*transactions.SystemTransactions{}, nil
-				}
+				})
 			},
 			unmock: func() {},
 		},
+		{
+			name:    "Succesful with stored run",
+			usecase: TransactionUsecase{ReconciliationRepository: mockRepository},
+			args: args{
+				ctx:   context.Background(),
+				param: storedRunParam(),
+			},
+			wantResult: func() transactions.DoReconciliationResponse {
+				result := storedRunResult
+				result.RunID = "run-1"
+				return result
+			}(),
+			wantErr: false,
+			mock: func() {
+				storedRunData()
+				run := storedRun
+				run.Result = storedRunResult
+				run.Result.RunID = "run-1"
+				mockRepository.EXPECT().SaveRun(gomock.Any(), run).Return(nil)
+			},
+			unmock: func() {
+				newID, timeNow = uuidID, time.Now
+			},
+		},
+		{
+			name:    "Failed run is stored",
+			usecase: TransactionUsecase{ReconciliationRepository: mockRepository},
+			args: args{
+				ctx: context.Background(),
+				param: func() transactions.DoReconciliationRequest {
+					param := storedRunParam()
+					param.DateToleranceDays = -1
+					return param
+				}(),
+			},
+			wantResult: transactions.DoReconciliationResponse{},
+			wantErr:    true,
+			mock: func() {
+				storedRunData()
+				run := storedRun
+				run.Status, run.Error = transactions.RunFailed, "date tolerance days can not be negative"
+				run.PeriodStart, run.PeriodEnd = "", ""
+				run.Parameters.DateToleranceDays = -1
+				mockRepository.EXPECT().SaveRun(gomock.Any(), run).Return(nil)
+			},
+			unmock: func() {
+				newID, timeNow = uuidID, time.Now
+			},
+		},
+		{
+			name:    "Failed when canceled",
+			usecase: TransactionUsecase{},
+			args: args{
+				ctx: func() context.Context {
+					ctx, cancel := context.WithCancel(context.Background())
+					cancel()
+					return ctx
+				}(),
+				param: storedRunParam(),
+			},
+			wantResult: transactions.DoReconciliationResponse{},
+			wantErr:    true,
+			mock:       storedRunData,
+			unmock: func() {
+				newID, timeNow = uuidID, time.Now
+			},
+		},
+		{
+			name:    "Failed storing run",
+			usecase: TransactionUsecase{ReconciliationRepository: mockRepository},
+			args: args{
+				ctx:   context.Background(),
+				param: storedRunParam(),
+			},
+			wantResult: transactions.DoReconciliationResponse{},
+			wantErr:    true,
+			mock: func() {
+				storedRunData()
+				mockRepository.EXPECT().SaveRun(gomock.Any(), gomock.Any()).Return(errMock)
+			},
+			unmock: func() {
+				newID, timeNow = uuidID, time.Now
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			usecase := TransactionUsecase{}
-
 			tt.mock()
-			gotResult, err := usecase.DoReconciliation(tt.args.ctx, tt.args.param)
+			gotResult, err := tt.usecase.DoReconciliation(tt.args.ctx, tt.args.param)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("TransactionUsecase.DoReconciliation() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -830,3 +2849,173 @@ func TestTransactionUsecase_DoReconciliation(t *testing.T) {
 		})
 	}
 }
+
+func TestTransactionUsecase_reconcile(t *testing.T) {
+	unmarshalCsvToStructForBankStatements = streamedBankStatements(func(_ *multipart.File) (result []*transactions.BankStatements, err error) {
+		return []*transactions.BankStatements{
+			{ID: "BRI_1", Amount: "Rp2,000,000", Date: "03/01/2024"},
+			{ID: "BRI_2", Amount: "Rp1,000", Date: "05/01/2024"},
+		}, nil
+	})
+	unmarshalCsvToStructForSystemTransactions = streamedSystemTransactions(func(_ *multipart.File) (result []*transactions.SystemTransactions, err error) {
+		return []*transactions.SystemTransactions{
+			{TransactionID: "1", Amount: "Rp2,000,000", RawType: "CREDIT", TransactionTime: "03/01/2024 08:20:00"},
+			{TransactionID: "2", Amount: "Rp3,000", RawType: "CREDIT", TransactionTime: "04/01/2024 08:20:00"},
+			{TransactionID: "3", Amount: "Rp3,000", RawType: "CREDIT", TransactionTime: "not a date"},
+		}, nil
+	})
+
+	progress := &transactions.Progress{}
+	days := &reconciledDays{}
+	_, err := TransactionUsecase{}.reconcile(context.Background(), transactions.DoReconciliationRequest{
+		BankStatements: []transactions.BankStatementsUpload{{}},
+		ValidationMode: transactions.ValidationLenient,
+		Progress:       progress,
+	}, days)
+	if err != nil {
+		t.Fatalf("TransactionUsecase.reconcile() error = %v", err)
+	}
+
+	// invalid rows are read too, only the valid ones are proceeded
+	wantProgress := transactions.ReconciliationProgress{BankStatementsRead: 2, SystemTransactionsRead: 3, TransactionsProceed: 2}
+	if got := progress.Counts(); !reflect.DeepEqual(got, wantProgress) {
+		t.Errorf("TransactionUsecase.reconcile() progress = %v, want %v", got, wantProgress)
+	}
+	if start, end := days.period(time.Time{}, time.Time{}); start != "2024-01-03" || end != "2024-01-05" {
+		t.Errorf("TransactionUsecase.reconcile() days = %v, %v, want 2024-01-03, 2024-01-05", start, end)
+	}
+}
+
+func TestTransactionUsecase_DoReconciliation_camt(t *testing.T) {
+	unmarshalCsvToStructForSystemTransactions = csvSystemTransactions
+
+	// the servicer of the account is not a known bank
+	camtNotification := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.054.001.08">
+  <BkToCstmrDbtCdtNtfctn>
+    <Ntfctn>
+      <Acct>
+        <Id><Othr><Id>0123456789</Id></Othr></Id>
+        <Svcr><FinInstnId><BICFI>XXXXIDJA</BICFI></FinInstnId></Svcr>
+      </Acct>
+      <Ntry>
+        <NtryRef>77</NtryRef>
+        <Amt Ccy="IDR">2000000.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><Dt>2024-01-13</Dt></BookgDt>
+      </Ntry>
+    </Ntfctn>
+  </BkToCstmrDbtCdtNtfctn>
+</Document>`
+	systemTransactions := "trxID,amount,type,transactionTime\n1,2000000,CREDIT,13/01/2024 08:20:00"
+
+	usecase := TransactionUsecase{
+		BankStatementsParsers: bankStatementsParsers.BankStatementsParsers(),
+	}
+	gotResult, err := usecase.DoReconciliation(context.Background(), transactions.DoReconciliationRequest{
+		SystemTransactions:    memoryFile{bytes.NewReader([]byte(systemTransactions))},
+		BankStatements:        []transactions.BankStatementsUpload{{File: memoryFile{bytes.NewReader([]byte(camtNotification))}, Name: "notification.xml"}},
+		BankStatementsOptions: transactions.FileOptions{Format: parsers.FormatAuto},
+	})
+	if err != nil {
+		t.Fatalf("TransactionUsecase.DoReconciliation() error = %v", err)
+	}
+	if gotResult.MatchedTransaction != 1 || gotResult.UnmatchedTransaction != 0 {
+		t.Fatalf("TransactionUsecase.DoReconciliation() matched = %d, unmatched = %d, want 1 matched", gotResult.MatchedTransaction, gotResult.UnmatchedTransaction)
+	}
+	if matched := gotResult.MatchedTransactions[0]; matched.UniqueIdentifier != "XXXXIDJA_77" || matched.BankSource != "XXXXIDJA" {
+		t.Errorf("TransactionUsecase.DoReconciliation() matched = %v, want XXXXIDJA_77 of XXXXIDJA", matched)
+	}
+}
+
+var (
+	// the csv unmarshalling before any test mocks it
+	csvBankStatements     = unmarshalCsvToStructForBankStatements
+	csvSystemTransactions = unmarshalCsvToStructForSystemTransactions
+
+	// the id before any test mocks it
+	uuidID = newID
+)
+
+// writeGeneratedCsv writes count generated bank statements and the system transactions they were booked from to
+// csv files in dir, a tenth of the transactions are missing from the bank and the others are booked up to two
+// days later
+func writeGeneratedCsv(dir string, count int) (bankStatementsPath, systemTransactionsPath string, err error) {
+	bankStatementsPath, systemTransactionsPath = filepath.Join(dir, "bank_statements.csv"), filepath.Join(dir, "system_transactions.csv")
+	bankStatementsFile, err := os.Create(bankStatementsPath)
+	if err != nil {
+		return "", "", err
+	}
+	defer bankStatementsFile.Close()
+	systemTransactionsFile, err := os.Create(systemTransactionsPath)
+	if err != nil {
+		return "", "", err
+	}
+	defer systemTransactionsFile.Close()
+
+	bankStatements, systemTransactions := bufio.NewWriter(bankStatementsFile), bufio.NewWriter(systemTransactionsFile)
+	fmt.Fprintln(bankStatements, "unique_identifier,amount,date")
+	fmt.Fprintln(systemTransactions, "trxID,amount,type,transactionTime")
+
+	random := rand.New(rand.NewSource(1))
+	start := time.Date(2024, time.Month(1), 1, 0, 0, 0, 0, transactions.DefaultLocation)
+	for index := 0; index < count; index++ {
+		amount := (random.Intn(100000) + 1) * 100
+		transactionType := "CREDIT"
+		if random.Intn(2) == 0 {
+			transactionType = "DEBIT"
+		}
+		day := start.AddDate(0, 0, random.Intn(31))
+		fmt.Fprintf(systemTransactions, "%d,%d,%s,%s\n", index, amount, transactionType, day.Add(time.Duration(random.Intn(86400))*time.Second).Format(dateTimeFormat))
+		if index%10 == 0 {
+			continue
+		}
+
+		if transactionType == "DEBIT" {
+			amount = -amount
+		}
+		fmt.Fprintf(bankStatements, "BCA_%d,%d,%s\n", index, amount, day.AddDate(0, 0, random.Intn(3)).Format(dateFormat))
+	}
+
+	err = bankStatements.Flush()
+	if err != nil {
+		return "", "", err
+	}
+	return bankStatementsPath, systemTransactionsPath, systemTransactions.Flush()
+}
+
+func BenchmarkTransactionUsecase_DoReconciliation(b *testing.B) {
+	unmarshalCsvToStructForBankStatements, unmarshalCsvToStructForSystemTransactions = csvBankStatements, csvSystemTransactions
+
+	bankStatementsPath, systemTransactionsPath, err := writeGeneratedCsv(b.TempDir(), 1000000)
+	if err != nil {
+		b.Fatalf("Failed to write csv files: %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bankStatements, err := os.Open(bankStatementsPath)
+		if err != nil {
+			b.Fatalf("Failed to open bank statements: %v", err)
+		}
+		systemTransactions, err := os.Open(systemTransactionsPath)
+		if err != nil {
+			b.Fatalf("Failed to open system transactions: %v", err)
+		}
+
+		result, err := TransactionUsecase{TempDir: b.TempDir()}.DoReconciliation(context.Background(), transactions.DoReconciliationRequest{
+			BankStatements:     []transactions.BankStatementsUpload{{File: bankStatements}},
+			SystemTransactions: systemTransactions,
+			DateToleranceDays:  2,
+		})
+		bankStatements.Close()
+		systemTransactions.Close()
+		if err != nil {
+			b.Fatalf("TransactionUsecase.DoReconciliation() error = %v", err)
+		}
+		if result.TransactionsProceed != 1000000 {
+			b.Fatalf("TransactionUsecase.DoReconciliation() proceeded %d transactions, want %d", result.TransactionsProceed, 1000000)
+		}
+	}
+}